@@ -0,0 +1,252 @@
+// Package apierr defines API errors with their HTTP statuses and renders
+// any error returned by a handler as JSON ent.Error. Errors which aren't
+// API errors are reported as internal without details, so SQL and other
+// internals don't leak to clients.
+package apierr
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+
+	"eapteka/ent"
+	"eapteka/reqctx"
+)
+
+// Codes of API errors.
+const (
+	CodeValidation   = "validation"
+	CodeNotFound     = "not_found"
+	CodeConflict     = "conflict"
+	CodeUnauthorized = "unauthorized"
+	CodeRateLimited  = "rate_limited"
+	CodeTimeout      = "timeout"
+	CodeUnavailable  = "unavailable"
+	CodeInternal     = "internal"
+)
+
+// Error is the error returned to the client as is.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Fields  []ent.FieldError
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Field returns validation error of the request field.
+func Field(field, message string) ent.FieldError {
+	return ent.FieldError{Field: field, Message: message}
+}
+
+func Validation(message string, fields ...ent.FieldError) *Error {
+	return &Error{
+		Status:  http.StatusBadRequest,
+		Code:    CodeValidation,
+		Message: message,
+		Fields:  fields,
+	}
+}
+
+// InvalidField returns validation error of the request parameter or field
+// with the cause as the field message.
+func InvalidField(field string, err error) *Error {
+	return Validation("invalid "+field, Field(field, err.Error()))
+}
+
+// Body returns validation error of the request body which failed to
+// unmarshal.
+func Body(err error) *Error {
+	var te *json.UnmarshalTypeError
+	if errors.As(err, &te) && te.Field != "" {
+		return Validation("invalid request body",
+			Field(te.Field, "must be "+te.Type.String()))
+	}
+	return Validation("invalid request body: " + err.Error())
+}
+
+func NotFound(message string) *Error {
+	return &Error{
+		Status:  http.StatusNotFound,
+		Code:    CodeNotFound,
+		Message: message,
+	}
+}
+
+func Conflict(message string) *Error {
+	return &Error{
+		Status:  http.StatusConflict,
+		Code:    CodeConflict,
+		Message: message,
+	}
+}
+
+func Unauthorized(message string) *Error {
+	return &Error{
+		Status:  http.StatusUnauthorized,
+		Code:    CodeUnauthorized,
+		Message: message,
+	}
+}
+
+// RateLimited returns error of the client which exceeded request rate of
+// the route.
+func RateLimited(message string) *Error {
+	return &Error{
+		Status:  http.StatusTooManyRequests,
+		Code:    CodeRateLimited,
+		Message: message,
+	}
+}
+
+var (
+	timeout = &Error{
+		Status:  http.StatusGatewayTimeout,
+		Code:    CodeTimeout,
+		Message: "request timed out",
+	}
+	unavailable = &Error{
+		Status:  http.StatusServiceUnavailable,
+		Code:    CodeUnavailable,
+		Message: "service is shutting down",
+	}
+)
+
+var internal = &Error{
+	Status:  http.StatusInternalServerError,
+	Code:    CodeInternal,
+	Message: "internal server error",
+}
+
+// Postgres error codes mapped to API errors.
+const (
+	pqNotNullViolation    = "23502"
+	pqForeignKeyViolation = "23503"
+	pqUniqueViolation     = "23505"
+	pqCheckViolation      = "23514"
+	pqInvalidText         = "22P02"
+	pqStringTooLong       = "22001"
+	pqOutOfRange          = "22003"
+)
+
+// From returns API error of the error, internal error if it has no API
+// meaning.
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return timeout
+	case errors.Is(err, context.Canceled):
+		return unavailable
+	}
+
+	var fe *fiber.Error
+	if errors.As(err, &fe) {
+		if fe.Code >= http.StatusInternalServerError {
+			return internal
+		}
+		return &Error{
+			Status:  fe.Code,
+			Code:    code(fe.Code),
+			Message: fe.Message,
+		}
+	}
+
+	// Only the lookup of the handler itself means the requested entity is
+	// missing, wrapped error is an inconsistency of the domain package
+	if err == sql.ErrNoRows {
+		return NotFound("not found")
+	}
+
+	var pe *pq.Error
+	if errors.As(err, &pe) {
+		switch pe.Code {
+		case pqUniqueViolation:
+			return Conflict("already exists")
+		case pqForeignKeyViolation:
+			return Conflict("referenced entity does not exist or is in use")
+		case pqNotNullViolation, pqCheckViolation, pqInvalidText,
+			pqStringTooLong, pqOutOfRange:
+			var fields []ent.FieldError
+			if pe.Column != "" {
+				fields = append(fields, Field(pe.Column, "invalid value"))
+			}
+			return Validation("invalid value", fields...)
+		}
+	}
+
+	return internal
+}
+
+// code returns code of the client error status without dedicated API
+// error, e.g. of fiber.ErrMethodNotAllowed.
+func code(status int) string {
+	switch status {
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	}
+	// Other client errors, e.g. 405 and 422, are validation ones, so the
+	// set of codes clients handle stays fixed
+	return CodeValidation
+}
+
+// Of returns API error the request handler error is responded with, e.g.
+// to label metrics and spans by its status before Handler runs.
+func Of(c *fiber.Ctx, err error) *Error {
+	e := From(err)
+
+	if e == internal {
+		switch reqctx.Err(c) {
+		case context.DeadlineExceeded:
+			return timeout
+		case context.Canceled:
+			return unavailable
+		}
+	}
+
+	return e
+}
+
+// Handler is the fiber error handler responding with ent.Error. Internal
+// errors are logged with the request id sent to the client. Errors of the
+// request which ran out of time or was aborted on shutdown, e.g. Postgres
+// "canceling statement", are reported as timeout or unavailable.
+func Handler(c *fiber.Ctx, err error) error {
+	e := Of(c, err)
+
+	requestID := string(c.Response().Header.Peek(fiber.HeaderXRequestID))
+
+	if e.Status >= http.StatusInternalServerError {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"request_id": requestID,
+			"method":     c.Method(),
+			"path":       c.Path(),
+		}).Error("request failed")
+	}
+
+	return c.Status(e.Status).JSON(ent.Error{
+		Code:      e.Code,
+		Message:   e.Message,
+		Fields:    e.Fields,
+		RequestID: requestID,
+	})
+}
@@ -0,0 +1,110 @@
+package apierr
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"eapteka/ent"
+	"eapteka/reqctx"
+)
+
+func TestHandler(t *testing.T) {
+	// canceling mimics Postgres error of the query cancelled with the
+	// request context
+	canceling := func(c *fiber.Ctx) error {
+		<-reqctx.Get(c).Done()
+		return errors.New("pq: canceling statement due to user request")
+	}
+
+	abort := make(chan struct{})
+
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		abort   bool
+		handler fiber.Handler
+		status  int
+		code    string
+	}{{
+		name: "plain error",
+		handler: func(c *fiber.Ctx) error {
+			return errors.New("boom")
+		},
+		status: 500,
+		code:   CodeInternal,
+	}, {
+		name: "not found",
+		handler: func(c *fiber.Ctx) error {
+			return NotFound("product not found")
+		},
+		status: 404,
+		code:   CodeNotFound,
+	}, {
+		name: "validation",
+		handler: func(c *fiber.Ctx) error {
+			return Validation("invalid", Field("k", "too short"))
+		},
+		status: 400,
+		code:   CodeValidation,
+	}, {
+		name: "fiber error",
+		handler: func(c *fiber.Ctx) error {
+			return fiber.ErrMethodNotAllowed
+		},
+		status: 405,
+		code:   CodeValidation,
+	}, {
+		name:    "timeout",
+		timeout: 10 * time.Millisecond,
+		handler: canceling,
+		status:  504,
+		code:    CodeTimeout,
+	}, {
+		name:    "abort",
+		timeout: time.Minute,
+		abort:   true,
+		handler: canceling,
+		status:  503,
+		code:    CodeUnavailable,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{ErrorHandler: Handler})
+
+			cfg := reqctx.Config{Timeout: tt.timeout}
+			if tt.abort {
+				cfg.Abort = abort
+				time.AfterFunc(10*time.Millisecond, func() { close(abort) })
+			}
+
+			app.Use(reqctx.Middleware(cfg))
+			app.Get("/", tt.handler)
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+
+			var e ent.Error
+			err = json.NewDecoder(resp.Body).Decode(&e)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if e.Code != tt.code {
+				t.Errorf("code = %q, want %q", e.Code, tt.code)
+			}
+		})
+	}
+}
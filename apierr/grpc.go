@@ -0,0 +1,103 @@
+package apierr
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// grpcCodes are gRPC status codes of API error codes, others are internal.
+var grpcCodes = map[string]codes.Code{
+	CodeValidation:   codes.InvalidArgument,
+	CodeNotFound:     codes.NotFound,
+	CodeConflict:     codes.AlreadyExists,
+	CodeUnauthorized: codes.Unauthenticated,
+	CodeRateLimited:  codes.ResourceExhausted,
+	CodeTimeout:      codes.DeadlineExceeded,
+	CodeUnavailable:  codes.Unavailable,
+}
+
+// Status returns gRPC status of the error the same way Handler renders it
+// for REST. Validation fields are sent as BadRequest details.
+func Status(ctx context.Context, err error) *status.Status {
+	if _, ok := status.FromError(err); ok {
+		return status.Convert(err)
+	}
+
+	e := From(err)
+
+	if e == internal {
+		switch ctx.Err() {
+		case context.DeadlineExceeded:
+			e = timeout
+		case context.Canceled:
+			e = unavailable
+		}
+	}
+
+	c, ok := grpcCodes[e.Code]
+	if !ok {
+		c = codes.Internal
+	}
+
+	s := status.New(c, e.Message)
+
+	if len(e.Fields) > 0 {
+		br := &errdetails.BadRequest{}
+		for _, f := range e.Fields {
+			br.FieldViolations = append(br.FieldViolations,
+				&errdetails.BadRequest_FieldViolation{
+					Field:       f.Field,
+					Description: f.Message,
+				})
+		}
+		if sd, err := s.WithDetails(br); err == nil {
+			s = sd
+		}
+	}
+
+	return s
+}
+
+// grpcError returns status error of the handler error and logs internal
+// errors.
+func grpcError(ctx context.Context, method string, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	e := From(err)
+	if e.Status >= http.StatusInternalServerError && ctx.Err() == nil {
+		logrus.WithError(err).WithField("method", method).
+			Error("request failed")
+	}
+
+	return Status(ctx, err).Err()
+}
+
+// UnaryServerInterceptor converts errors of gRPC handlers to statuses.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (
+		interface{}, error) {
+
+		resp, err := handler(ctx, req)
+		return resp, grpcError(ctx, info.FullMethod, err)
+	}
+}
+
+// StreamServerInterceptor converts errors of gRPC stream handlers to
+// statuses.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream,
+		info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+		err := handler(srv, ss)
+		return grpcError(ss.Context(), info.FullMethod, err)
+	}
+}
@@ -0,0 +1,149 @@
+// Package apiver mounts API versions under their path prefixes, e.g.
+// /api/v1, and serves deprecated versions with Deprecation, Sunset and
+// successor Link headers.
+//
+// Compatibility policy:
+//
+//   - A version changes only compatibly: routes, optional request fields
+//     and response fields are added, clients ignore unknown fields.
+//   - Breaking changes, e.g. removing or renaming a field or changing its
+//     type or meaning, go to the next version. Routes which don't change
+//     are registered on both versions by one Router.
+//   - The replaced version is deprecated and served for at least
+//     MinSupport after its deprecation, New refuses earlier sunset.
+package apiver
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MinSupport is the minimum time the deprecated version is served.
+const MinSupport = 180 * 24 * time.Hour
+
+type Version struct {
+	// Prefix is the path of the version, e.g. "/api/v1".
+	Prefix string
+
+	// Deprecated is the time the version is deprecated since, zero if it
+	// is current.
+	Deprecated time.Time
+
+	// Sunset is the time after which deprecated version may be removed.
+	Sunset time.Time
+
+	// Successor is the prefix of the version replacing deprecated one,
+	// the same path under it is linked as successor-version.
+	Successor string
+}
+
+// Router registers routes at prefixes of its versions.
+type Router struct {
+	versions []version
+}
+
+type version struct {
+	router fiber.Router
+
+	// deprecation sets headers of deprecated version, nil if it is current.
+	deprecation fiber.Handler
+}
+
+// New returns router of versions serving the same routes, e.g. the current
+// version and its deprecated unversioned alias. It panics if the version
+// violates the compatibility policy.
+func New(app fiber.Router, vs ...Version) *Router {
+	r := &Router{}
+
+	for _, v := range vs {
+		if v.Prefix == "" {
+			panic("apiver: empty version prefix")
+		}
+
+		mv := version{router: app.Group(v.Prefix)}
+
+		if !v.Deprecated.IsZero() {
+			if v.Sunset.Sub(v.Deprecated) < MinSupport {
+				panic(fmt.Sprintf(
+					"apiver: %s sunset is earlier than %s after deprecation",
+					v.Prefix, MinSupport))
+			}
+			mv.deprecation = deprecation(v)
+		}
+
+		r.versions = append(r.versions, mv)
+	}
+
+	return r
+}
+
+// deprecation returns handler setting deprecation headers, the
+// Deprecation header is of RFC 9745, the Sunset header is of RFC 8594.
+func deprecation(v Version) fiber.Handler {
+	deprecated := "@" + strconv.FormatInt(v.Deprecated.Unix(), 10)
+	sunset := v.Sunset.UTC().Format(http.TimeFormat)
+
+	return func(c *fiber.Ctx) error {
+		c.Set("Deprecation", deprecated)
+		c.Set("Sunset", sunset)
+
+		if v.Successor != "" {
+			path := v.Successor + strings.TrimPrefix(c.Path(), v.Prefix)
+			c.Append(fiber.HeaderLink,
+				fmt.Sprintf(`<%s>; rel="successor-version"`, path))
+		}
+
+		return c.Next()
+	}
+}
+
+func (r *Router) add(method, path string, handlers []fiber.Handler) *Router {
+	for _, v := range r.versions {
+		hs := handlers
+		if v.deprecation != nil {
+			hs = append([]fiber.Handler{v.deprecation}, handlers...)
+		}
+		v.router.Add(method, path, hs...)
+	}
+	return r
+}
+
+func (r *Router) Get(path string, handlers ...fiber.Handler) *Router {
+	return r.add(fiber.MethodGet, path, handlers)
+}
+
+func (r *Router) Post(path string, handlers ...fiber.Handler) *Router {
+	return r.add(fiber.MethodPost, path, handlers)
+}
+
+func (r *Router) Put(path string, handlers ...fiber.Handler) *Router {
+	return r.add(fiber.MethodPut, path, handlers)
+}
+
+func (r *Router) Patch(path string, handlers ...fiber.Handler) *Router {
+	return r.add(fiber.MethodPatch, path, handlers)
+}
+
+func (r *Router) Delete(path string, handlers ...fiber.Handler) *Router {
+	return r.add(fiber.MethodDelete, path, handlers)
+}
+
+// Use adds middleware of every version. Middleware of the version prefix
+// also matches paths of versions nested under it, e.g. /api of /api/v1,
+// so the nested version must be registered first. Deprecation headers
+// aren't set, since the path may be of no version at all.
+func (r *Router) Use(handlers ...fiber.Handler) *Router {
+	args := make([]interface{}, len(handlers))
+	for i, h := range handlers {
+		args[i] = h
+	}
+	for _, v := range r.versions {
+		v.router.Use(args...)
+	}
+	return r
+}
@@ -0,0 +1,114 @@
+package apiver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestDeprecation(t *testing.T) {
+	deprecated := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	sunset := deprecated.Add(MinSupport)
+
+	app := fiber.New()
+
+	r := New(app,
+		Version{Prefix: "/api/v1"},
+		Version{
+			Prefix:     "/api",
+			Deprecated: deprecated,
+			Sunset:     sunset,
+			Successor:  "/api/v1",
+		})
+
+	r.Get("/products/:id", func(c *fiber.Ctx) error {
+		return c.SendString(c.Params("id"))
+	})
+
+	tests := []struct {
+		name        string
+		path        string
+		deprecation string
+		sunset      string
+		link        string
+	}{{
+		name: "current",
+		path: "/api/v1/products/1",
+	}, {
+		name:        "deprecated",
+		path:        "/api/products/1",
+		deprecation: "@1792368000",
+		sunset:      "Sat, 17 Apr 2027 00:00:00 GMT",
+		link:        `</api/v1/products/1>; rel="successor-version"`,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, tt.path,
+				nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d, want %d", resp.StatusCode,
+					http.StatusOK)
+			}
+
+			want := map[string]string{
+				"Deprecation": tt.deprecation,
+				"Sunset":      tt.sunset,
+				"Link":        tt.link,
+			}
+			for h, v := range want {
+				if got := resp.Header.Get(h); got != v {
+					t.Errorf("%s = %q, want %q", h, got, v)
+				}
+			}
+		})
+	}
+}
+
+func TestNewPanics(t *testing.T) {
+	deprecated := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		version   Version
+		wantPanic bool
+	}{{
+		name:    "current",
+		version: Version{Prefix: "/api/v1"},
+	}, {
+		name: "minimum support",
+		version: Version{Prefix: "/api", Deprecated: deprecated,
+			Sunset: deprecated.Add(MinSupport)},
+	}, {
+		name: "early sunset",
+		version: Version{Prefix: "/api", Deprecated: deprecated,
+			Sunset: deprecated.Add(MinSupport - time.Second)},
+		wantPanic: true,
+	}, {
+		name:      "no sunset",
+		version:   Version{Prefix: "/api", Deprecated: deprecated},
+		wantPanic: true,
+	}, {
+		name:      "empty prefix",
+		version:   Version{},
+		wantPanic: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r != nil) != tt.wantPanic {
+					t.Errorf("New() panic = %v, want panic %v", r,
+						tt.wantPanic)
+				}
+			}()
+			New(fiber.New(), tt.version)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+type ACMEConfig struct {
+	// Domains certificates are requested for.
+	Domains []string
+
+	// Email is the contact of the ACME account.
+	Email string
+
+	// CacheDir keeps account key and certificates between restarts.
+	CacheDir string
+
+	// DirectoryURL of the ACME server, Let's Encrypt production if empty.
+	// Local CA such as pebble may be used for testing.
+	DirectoryURL string
+
+	// CARoot is PEM file with root certificates trusted when connecting to
+	// the ACME server, system roots are used if empty.
+	CARoot string
+}
+
+// NewACME returns manager which obtains and renews certificates of the
+// domains automatically. Manager HTTPHandler must be served on port 80 for
+// http-01 challenges, tls-alpn-01 challenges are handled by its TLSConfig.
+func NewACME(cfg ACMEConfig) (*autocert.Manager, error) {
+	if len(cfg.Domains) == 0 {
+		return nil, errors.New("no ACME domains")
+	}
+
+	client := &acme.Client{DirectoryURL: cfg.DirectoryURL}
+
+	if cfg.CARoot != "" {
+		pem, err := ioutil.ReadFile(cfg.CARoot)
+		if err != nil {
+			return nil, fmt.Errorf("read CA root: %w", err)
+		}
+
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificates in CA root")
+		}
+
+		client.HTTPClient = &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{RootCAs: roots},
+			},
+		}
+	}
+
+	return &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		Cache:      autocert.DirCache(cfg.CacheDir),
+		HostPolicy: autocert.HostWhitelist(cfg.Domains...),
+		Email:      cfg.Email,
+		Client:     client,
+	}, nil
+}
+
+// HTTP1 returns copy of the config negotiating HTTP/1.1 only, since fasthttp
+// doesn't speak HTTP/2, which still answers ACME tls-alpn-01 challenges if
+// the config does.
+func HTTP1(c *tls.Config) *tls.Config {
+	h := c.Clone()
+	h.NextProtos = []string{"http/1.1"}
+
+	for _, p := range c.NextProtos {
+		if p == acme.ALPNProto {
+			h.NextProtos = append(h.NextProtos, acme.ALPNProto)
+		}
+	}
+
+	return h
+}
@@ -0,0 +1,492 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/acme"
+)
+
+var b64 = base64.RawURLEncoding
+
+// stubCA is in-process ACME (RFC 8555) server issuing certificates after
+// http-01 validation. Request signatures aren't verified.
+type stubCA struct {
+	t   *testing.T
+	srv *httptest.Server
+
+	key  *ecdsa.PrivateKey
+	cert *x509.Certificate
+
+	// http01 returns response of the http-01 challenge request to the
+	// domain.
+	http01 func(domain, token string) string
+
+	mx         sync.Mutex
+	nonce      int
+	thumbprint string
+	domain     string
+	token      string
+	status     string
+	issued     []byte
+	validated  int
+}
+
+func newStubCA(t *testing.T) *stubCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "stub CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey,
+		key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ca := &stubCA{t: t, key: key, cert: cert}
+	ca.srv = httptest.NewTLSServer(http.HandlerFunc(ca.serve))
+	t.Cleanup(ca.srv.Close)
+
+	return ca
+}
+
+// rootFile writes certificate of the server to PEM file for CARoot.
+func (ca *stubCA) rootFile(dir string) string {
+	name := filepath.Join(dir, "root.pem")
+
+	err := ioutil.WriteFile(name, pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: ca.srv.Certificate().Raw,
+	}), 0o600)
+	if err != nil {
+		ca.t.Fatal(err)
+	}
+
+	return name
+}
+
+func (ca *stubCA) url(path string) string {
+	return ca.srv.URL + path
+}
+
+// payload decodes payload of the JWS request body and stores JWK thumbprint
+// of the account key sent on registration.
+func (ca *stubCA) payload(r *http.Request, v interface{}) error {
+	var jws struct {
+		Protected string `json:"protected"`
+		Payload   string `json:"payload"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&jws)
+	if err != nil {
+		return err
+	}
+
+	ph, err := b64.DecodeString(jws.Protected)
+	if err != nil {
+		return err
+	}
+
+	var protected struct {
+		JWK *struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"jwk"`
+	}
+
+	err = json.Unmarshal(ph, &protected)
+	if err != nil {
+		return err
+	}
+
+	if k := protected.JWK; k != nil {
+		// RFC 7638 thumbprint of EC key, members in lexicographic order
+		h := sha256.Sum256([]byte(fmt.Sprintf(
+			`{"crv":%q,"kty":%q,"x":%q,"y":%q}`, k.Crv, k.Kty, k.X, k.Y)))
+		ca.thumbprint = b64.EncodeToString(h[:])
+	}
+
+	p, err := b64.DecodeString(jws.Payload)
+	if err != nil || len(p) == 0 || v == nil {
+		return err
+	}
+
+	return json.Unmarshal(p, v)
+}
+
+func (ca *stubCA) order() map[string]interface{} {
+	o := map[string]interface{}{
+		"status":         ca.status,
+		"identifiers":    []acme.AuthzID{{Type: "dns", Value: ca.domain}},
+		"authorizations": []string{ca.url("/authz")},
+		"finalize":       ca.url("/finalize"),
+	}
+	if ca.issued != nil {
+		o["certificate"] = ca.url("/cert")
+	}
+	return o
+}
+
+func (ca *stubCA) authz() map[string]interface{} {
+	status := "pending"
+	if ca.status != "pending" {
+		status = "valid"
+	}
+
+	return map[string]interface{}{
+		"status":     status,
+		"identifier": acme.AuthzID{Type: "dns", Value: ca.domain},
+		"challenges": []map[string]string{{
+			"type":   "http-01",
+			"url":    ca.url("/challenge"),
+			"token":  ca.token,
+			"status": status,
+		}},
+	}
+}
+
+func (ca *stubCA) serve(w http.ResponseWriter, r *http.Request) {
+	ca.mx.Lock()
+	defer ca.mx.Unlock()
+
+	ca.nonce++
+	w.Header().Set("Replay-Nonce", fmt.Sprintf("nonce-%d", ca.nonce))
+
+	reply := func(status int, v interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(v)
+	}
+
+	switch r.URL.Path {
+	case "/directory":
+		reply(http.StatusOK, map[string]string{
+			"newNonce":   ca.url("/nonce"),
+			"newAccount": ca.url("/account"),
+			"newOrder":   ca.url("/order/new"),
+			"revokeCert": ca.url("/revoke"),
+			"keyChange":  ca.url("/key-change"),
+		})
+
+	case "/nonce":
+		w.WriteHeader(http.StatusOK)
+
+	case "/account":
+		ca.payload(r, nil)
+		w.Header().Set("Location", ca.url("/account/1"))
+		reply(http.StatusCreated, map[string]string{"status": "valid"})
+
+	case "/order/new":
+		var req struct {
+			Identifiers []acme.AuthzID `json:"identifiers"`
+		}
+		err := ca.payload(r, &req)
+		if err != nil || len(req.Identifiers) != 1 {
+			reply(http.StatusBadRequest, map[string]string{
+				"type": "urn:ietf:params:acme:error:malformed"})
+			return
+		}
+
+		ca.domain = req.Identifiers[0].Value
+		ca.token = fmt.Sprintf("token-%d", ca.nonce)
+		ca.status = "pending"
+		ca.issued = nil
+
+		w.Header().Set("Location", ca.url("/order/1"))
+		reply(http.StatusCreated, ca.order())
+
+	case "/order/1":
+		ca.payload(r, nil)
+		reply(http.StatusOK, ca.order())
+
+	case "/authz":
+		ca.payload(r, nil)
+		reply(http.StatusOK, ca.authz())
+
+	case "/challenge":
+		ca.payload(r, nil)
+
+		// Validation request is made to the domain as a real CA does
+		want := ca.token + "." + ca.thumbprint
+		if got := ca.http01(ca.domain, ca.token); got != want {
+			ca.t.Errorf("http-01 response = %q, want %q", got, want)
+			ca.status = "invalid"
+		} else {
+			ca.status = "ready"
+			ca.validated++
+		}
+		reply(http.StatusOK, ca.authz()["challenges"].([]map[string]string)[0])
+
+	case "/finalize":
+		var req struct {
+			CSR string `json:"csr"`
+		}
+		err := ca.payload(r, &req)
+		if err == nil && ca.status != "ready" {
+			err = fmt.Errorf("order is %s", ca.status)
+		}
+		if err == nil {
+			ca.issued, err = ca.issue(req.CSR)
+		}
+		if err != nil {
+			ca.t.Errorf("finalize: %v", err)
+			reply(http.StatusForbidden, map[string]string{
+				"type": "urn:ietf:params:acme:error:orderNotReady"})
+			return
+		}
+
+		ca.status = "valid"
+		reply(http.StatusOK, ca.order())
+
+	case "/cert":
+		ca.payload(r, nil)
+		w.Header().Set("Content-Type", "application/pem-certificate-chain")
+		w.Write(ca.issued)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// issue signs the CSR and returns PEM chain of the certificate and the CA.
+func (ca *stubCA) issue(csr string) ([]byte, error) {
+	der, err := b64.DecodeString(csr)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(int64(ca.nonce)),
+		Subject:      pkix.Name{CommonName: ca.domain},
+		DNSNames:     req.DNSNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(90 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	leaf, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert,
+		req.PublicKey, ca.key)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leaf}),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw})...,
+	), nil
+}
+
+func TestACME(t *testing.T) {
+	dir := t.TempDir()
+	ca := newStubCA(t)
+
+	m, err := NewACME(ACMEConfig{
+		Domains:      []string{"shop.example.test"},
+		Email:        "admin@example.test",
+		CacheDir:     filepath.Join(dir, "cache"),
+		DirectoryURL: ca.url("/directory"),
+		CARoot:       ca.rootFile(dir),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Challenges are answered by the redirect listener handler
+	handler := m.HTTPHandler(Redirect("443"))
+	ca.http01 = func(domain, token string) string {
+		req := httptest.NewRequest("GET",
+			"http://"+domain+"/.well-known/acme-challenge/"+token, nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Body.String()
+	}
+
+	tests := []struct {
+		name       string
+		serverName string
+		wantErr    bool
+	}{
+		{name: "issued", serverName: "shop.example.test"},
+		{name: "cached", serverName: "shop.example.test"},
+		{name: "other domain", serverName: "evil.example.test", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert, err := m.GetCertificate(&tls.ClientHelloInfo{
+				ServerName:   tt.serverName,
+				CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetCertificate() error = %v, want error %v", err,
+					tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			leaf, err := x509.ParseCertificate(cert.Certificate[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = leaf.VerifyHostname(tt.serverName); err != nil {
+				t.Error(err)
+			}
+			if err = leaf.CheckSignatureFrom(ca.cert); err != nil {
+				t.Errorf("certificate isn't issued by the CA: %v", err)
+			}
+		})
+	}
+
+	if ca.validated != 1 {
+		t.Errorf("%d challenges validated, want 1", ca.validated)
+	}
+
+	// Certificate is kept in cache between restarts
+	files, err := ioutil.ReadDir(filepath.Join(dir, "cache"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	if !strings.Contains(strings.Join(names, ","), "shop.example.test") {
+		t.Errorf("cache contains %v, want certificate of shop.example.test",
+			names)
+	}
+
+	// Browsers offering HTTP/2 agree on HTTP/1.1 served by fasthttp
+	server, client := net.Pipe()
+	defer client.Close()
+
+	go func() {
+		defer server.Close()
+		tls.Server(server, HTTP1(m.TLSConfig())).Handshake()
+	}()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	conn := tls.Client(client, &tls.Config{
+		ServerName: "shop.example.test",
+		RootCAs:    roots,
+		NextProtos: []string{"h2", "http/1.1"},
+	})
+	err = conn.Handshake()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := conn.ConnectionState().NegotiatedProtocol; p != "http/1.1" {
+		t.Errorf("negotiated protocol = %q, want http/1.1", p)
+	}
+
+	// Other requests to the redirect listener are redirected to HTTPS
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET",
+		"http://shop.example.test/catalog", nil))
+	if rec.Code != http.StatusMovedPermanently {
+		t.Errorf("redirect status = %d, want 301", rec.Code)
+	}
+}
+
+func TestNewACMEInvalid(t *testing.T) {
+	dir := t.TempDir()
+
+	empty := filepath.Join(dir, "empty.pem")
+	err := ioutil.WriteFile(empty, []byte("no certificates"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  ACMEConfig
+	}{
+		{"no domains", ACMEConfig{}},
+		{"missing CA root", ACMEConfig{Domains: []string{"a.test"},
+			CARoot: filepath.Join(dir, "missing.pem")}},
+		{"empty CA root", ACMEConfig{Domains: []string{"a.test"}, CARoot: empty}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewACME(tt.cfg)
+			if err == nil {
+				t.Error("NewACME() error = nil")
+			}
+		})
+	}
+}
+
+func TestHTTP1(t *testing.T) {
+	tests := []struct {
+		name       string
+		nextProtos []string
+		want       []string
+	}{
+		{"acme", []string{"h2", "http/1.1", acme.ALPNProto},
+			[]string{"http/1.1", acme.ALPNProto}},
+		{"h2", []string{"h2", "http/1.1"}, []string{"http/1.1"}},
+		{"none", nil, []string{"http/1.1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &tls.Config{NextProtos: tt.nextProtos}
+
+			got := HTTP1(c).NextProtos
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("NextProtos = %v, want %v", got, tt.want)
+			}
+			if strings.Join(c.NextProtos, ",") != strings.Join(tt.nextProtos, ",") {
+				t.Errorf("original NextProtos changed to %v", c.NextProtos)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package certs
+
+import (
+	"net"
+	"net/http"
+)
+
+// Redirect returns handler which redirects requests to the same URL with
+// https scheme on the given port.
+func Redirect(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+
+		if httpsPort != "" && httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+
+		u := *r.URL
+		u.Scheme = "https"
+		u.Host = host
+
+		code := http.StatusMovedPermanently
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			code = http.StatusPermanentRedirect
+		}
+
+		http.Redirect(w, r, u.String(), code)
+	})
+}
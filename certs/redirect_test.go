@@ -0,0 +1,74 @@
+package certs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRedirect(t *testing.T) {
+	tests := []struct {
+		name     string
+		port     string
+		method   string
+		url      string
+		status   int
+		location string
+	}{{
+		name:     "default port",
+		port:     "443",
+		method:   "GET",
+		url:      "http://eapteka.test/api/v1/products?substance_id=1",
+		status:   http.StatusMovedPermanently,
+		location: "https://eapteka.test/api/v1/products?substance_id=1",
+	}, {
+		name:     "http port dropped",
+		port:     "443",
+		method:   "HEAD",
+		url:      "http://eapteka.test:80/",
+		status:   http.StatusMovedPermanently,
+		location: "https://eapteka.test/",
+	}, {
+		name:     "custom port",
+		port:     "10443",
+		method:   "GET",
+		url:      "http://eapteka.test:10080/docs",
+		status:   http.StatusMovedPermanently,
+		location: "https://eapteka.test:10443/docs",
+	}, {
+		name:     "no port",
+		method:   "GET",
+		url:      "http://eapteka.test/",
+		status:   http.StatusMovedPermanently,
+		location: "https://eapteka.test/",
+	}, {
+		name:     "ipv6",
+		port:     "8443",
+		method:   "GET",
+		url:      "http://[::1]:8080/",
+		status:   http.StatusMovedPermanently,
+		location: "https://[::1]:8443/",
+	}, {
+		name:     "method kept",
+		port:     "443",
+		method:   "POST",
+		url:      "http://eapteka.test/api/v1/purchases",
+		status:   http.StatusPermanentRedirect,
+		location: "https://eapteka.test/api/v1/purchases",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			Redirect(tt.port).ServeHTTP(w,
+				httptest.NewRequest(tt.method, tt.url, nil))
+
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			if l := w.Header().Get("Location"); l != tt.location {
+				t.Errorf("Location = %q, want %q", l, tt.location)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+// Package certs provides TLS certificates from files reloaded on change or
+// from an ACME certificate authority, and the HTTP to HTTPS redirect.
+package certs
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Reloader serves certificate from files and reloads it when files are
+// modified, e.g. renewed by certbot, without restarting the server.
+type Reloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	cert    *tls.Certificate
+	modTime time.Time
+	mx      sync.RWMutex
+
+	close chan struct{}
+	wg    sync.WaitGroup
+}
+
+// NewReloader loads the certificate, it fails if files are invalid.
+func NewReloader(certFile, keyFile string, interval time.Duration) (
+	*Reloader, error) {
+
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: interval,
+		close:    make(chan struct{}),
+	}
+
+	_, err := r.reload()
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Start checks files for modification every interval.
+func (r *Reloader) Start() {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		t := time.NewTicker(r.interval)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-r.close:
+				return
+			case <-t.C:
+			}
+
+			reloaded, err := r.reload()
+			if err != nil {
+				logrus.WithError(err).Error("failed to reload TLS certificate")
+				continue
+			}
+			if reloaded {
+				logrus.WithField("cert_file", r.certFile).
+					Info("TLS certificate reloaded")
+			}
+		}
+	}()
+}
+
+func (r *Reloader) Stop() {
+	close(r.close)
+	r.wg.Wait()
+}
+
+// reload loads certificate if any file is modified after the last load.
+// Certificate in use is kept if new files are invalid, e.g. half written.
+func (r *Reloader) reload() (bool, error) {
+	var modTime time.Time
+
+	for _, name := range []string{r.certFile, r.keyFile} {
+		fi, err := os.Stat(name)
+		if err != nil {
+			return false, fmt.Errorf("stat: %w", err)
+		}
+		if fi.ModTime().After(modTime) {
+			modTime = fi.ModTime()
+		}
+	}
+
+	r.mx.RLock()
+	loaded := r.cert != nil && !modTime.After(r.modTime)
+	r.mx.RUnlock()
+
+	if loaded {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("load key pair: %w", err)
+	}
+
+	r.mx.Lock()
+	r.cert = &cert
+	r.modTime = modTime
+	r.mx.Unlock()
+
+	return true, nil
+}
+
+// GetCertificate is used as tls.Config.GetCertificate.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mx.RLock()
+	defer r.mx.RUnlock()
+	return r.cert, nil
+}
+
+// TLSConfig returns server TLS config using the reloaded certificate.
+func (r *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.GetCertificate,
+	}
+}
@@ -0,0 +1,202 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCert writes new self-signed certificate of the common name to the
+// files and sets their modification time.
+func writeCert(t *testing.T, certFile, keyFile, name string,
+	modTime time.Time) {
+
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey,
+		key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]*pem.Block{
+		certFile: {Type: "CERTIFICATE", Bytes: der},
+		keyFile:  {Type: "EC PRIVATE KEY", Bytes: keyDER},
+	}
+	for name, b := range files {
+		err = ioutil.WriteFile(name, pem.EncodeToMemory(b), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.Chtimes(name, modTime, modTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// commonName returns common name of the certificate served by r.
+func commonName(t *testing.T, r *Reloader) string {
+	t.Helper()
+
+	cert, err := r.GetCertificate(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c.Subject.CommonName
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	writeCert(t, certFile, keyFile, "old", modTime)
+
+	r, err := NewReloader(certFile, keyFile, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cn := commonName(t, r); cn != "old" {
+		t.Fatalf("certificate = %q, want old", cn)
+	}
+
+	tests := []struct {
+		name     string
+		change   func()
+		reloaded bool
+		wantErr  bool
+		cn       string
+	}{{
+		name:   "unchanged",
+		change: func() {},
+		cn:     "old",
+	}, {
+		name: "replaced",
+		change: func() {
+			modTime = modTime.Add(time.Minute)
+			writeCert(t, certFile, keyFile, "new", modTime)
+		},
+		reloaded: true,
+		cn:       "new",
+	}, {
+		name: "half written",
+		change: func() {
+			modTime = modTime.Add(time.Minute)
+			err := ioutil.WriteFile(certFile, []byte("-----BEGIN"), 0o600)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = os.Chtimes(certFile, modTime, modTime)
+			if err != nil {
+				t.Fatal(err)
+			}
+		},
+		wantErr: true,
+		cn:      "new",
+	}, {
+		name: "removed",
+		change: func() {
+			err := os.Remove(keyFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+		},
+		wantErr: true,
+		cn:      "new",
+	}, {
+		name: "restored",
+		change: func() {
+			modTime = modTime.Add(time.Minute)
+			writeCert(t, certFile, keyFile, "restored", modTime)
+		},
+		reloaded: true,
+		cn:       "restored",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.change()
+
+			reloaded, err := r.reload()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("reload() error = %v, want error %v", err, tt.wantErr)
+			}
+			if reloaded != tt.reloaded {
+				t.Errorf("reload() = %v, want %v", reloaded, tt.reloaded)
+			}
+			if cn := commonName(t, r); cn != tt.cn {
+				t.Errorf("certificate = %q, want %q", cn, tt.cn)
+			}
+		})
+	}
+}
+
+func TestReloaderStart(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	modTime := time.Now().Add(-time.Hour)
+
+	writeCert(t, certFile, keyFile, "old", modTime)
+
+	r, err := NewReloader(certFile, keyFile, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Start()
+	defer r.Stop()
+
+	writeCert(t, certFile, keyFile, "new", modTime.Add(time.Minute))
+
+	for i := 0; commonName(t, r) != "new"; i++ {
+		if i == 100 {
+			t.Fatal("certificate isn't reloaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestNewReloaderInvalid(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := NewReloader(filepath.Join(dir, "cert.pem"),
+		filepath.Join(dir, "key.pem"), time.Minute)
+	if err == nil {
+		t.Fatal("NewReloader() of missing files succeeded")
+	}
+}
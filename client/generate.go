@@ -0,0 +1,5 @@
+package client
+
+// Types and client are generated from the document served by the service,
+// run go generate ./client after changing openapi/openapi.yaml.
+//go:generate go run github.com/deepmap/oapi-codegen/cmd/oapi-codegen@v1.8.2 -generate types,client,skip-prune -package client -o client.gen.go ../openapi/openapi.yaml
@@ -1,16 +1,18 @@
 package main
 
 import (
-	"database/sql"
+	"context"
 	"encoding/csv"
 	"fmt"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 
-	_ "github.com/lib/pq"
+	"go.opentelemetry.io/otel/codes"
 
 	"eapteka/data"
+	"eapteka/tracing"
 )
 
 func exitErr(err error) {
@@ -18,11 +20,60 @@ func exitErr(err error) {
 	os.Exit(1)
 }
 
+// packSizeRe matches pack size at the end of product name, e.g. "12 шт."
+// or "200 мл".
+var packSizeRe = regexp.MustCompile(`(\d+)[\s\x{a0}]*(?:шт|мл|г)\.?$`)
+
+// packSize returns number of units in the product pack, 0 if unknown.
+func packSize(name string) int {
+	m := packSizeRe.FindStringSubmatch(name)
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n
+}
+
 func main() {
+	shutdown, err := tracing.Setup(tracing.Config{
+		ServiceName: "eapteka-data-loader",
+		Exporter:    os.Getenv("TRACING_EXPORTER"),
+		Endpoint:    os.Getenv("OTLP_ENDPOINT"),
+		Insecure:    os.Getenv("OTLP_INSECURE") == "true",
+	})
+	if err != nil {
+		exitErr(err)
+	}
+
+	ctx, span := tracing.Start(context.Background(), "load data")
+
+	err = load(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+
+	shutdownErr := shutdown(context.Background())
+	if shutdownErr != nil {
+		fmt.Println(shutdownErr)
+	}
+
+	if err != nil {
+		exitErr(err)
+	}
+}
+
+// load inserts substances and products of the embedded data in one
+// transaction.
+func load(ctx context.Context) error {
+	_, span := tracing.Start(ctx, "read data")
 
 	df, err := data.FS.Open("data.csv")
 	if err != nil {
-		panic(err)
+		span.End()
+		return err
 	}
 
 	defer df.Close()
@@ -33,22 +84,29 @@ func main() {
 	r.LazyQuotes = true
 
 	data, err := r.ReadAll()
+	span.End()
 	if err != nil {
-		exitErr(err)
+		return err
 	}
 
-	db, err := sql.Open("postgres", os.Getenv("POSTGRES_DSN"))
+	db, err := tracing.OpenDB(os.Getenv("POSTGRES_DSN"))
 	if err != nil {
-		exitErr(err)
+		return err
 	}
 
 	defer db.Close()
 
-	tx, err := db.Begin()
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
-		exitErr(err)
+		return err
 	}
 
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
 	ss := map[string]int64{}
 
 	for _, d := range data {
@@ -56,43 +114,49 @@ func main() {
 		ss[d[1]] = 0
 	}
 
+	sctx, span := tracing.Start(ctx, "insert substances")
+
 	for s := range ss {
 		var id int64
-		err = tx.QueryRow(`
+		err = tx.QueryRowContext(sctx, `
 			insert into substance(name) values ($1) returning id
 		`, s).Scan(&id)
 		if err != nil {
-			tx.Rollback()
-			exitErr(err)
+			span.End()
+			return err
 		}
 		ss[s] = id
 	}
 
+	span.End()
+
+	pctx, span := tracing.Start(ctx, "insert products")
+	defer span.End()
+
 	for _, d := range data {
-		price, err := strconv.Atoi(strings.TrimSpace(d[3]))
+		var price, imageID int
+
+		price, err = strconv.Atoi(strings.TrimSpace(d[3]))
 		if err != nil {
-			tx.Rollback()
-			exitErr(err)
+			return err
 		}
 
-		imageID, err := strconv.Atoi(strings.TrimSpace(d[4]))
+		imageID, err = strconv.Atoi(strings.TrimSpace(d[4]))
 		if err != nil {
-			tx.Rollback()
-			exitErr(err)
+			return err
 		}
 
-		_, err = tx.Exec(`
-			insert into product(substance_id, name, description, price, image_id)
-			values ($1, $2, $3, $4, $5)
-		`, ss[d[1]], strings.TrimSpace(d[0]), d[2], price, imageID)
+		name := strings.TrimSpace(d[0])
+
+		_, err = tx.ExecContext(pctx, `
+			insert into product(substance_id, name, description, price, image_id,
+			                    pack_size)
+			values ($1, $2, $3, $4, $5, $6)
+		`, ss[d[1]], name, d[2], price, imageID, packSize(name))
 		if err != nil {
-			tx.Rollback()
-			exitErr(err)
+			return err
 		}
 	}
 
-	err = tx.Commit()
-	if err != nil {
-		exitErr(err)
-	}
+	return tx.Commit()
 }
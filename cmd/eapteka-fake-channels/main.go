@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/sirupsen/logrus"
+
+	"eapteka/delivery"
+	"eapteka/delivery/fake"
+)
+
+func getenv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func main() {
+	smtpAddr := getenv("SMTP_BIND_ADDR", "127.0.0.1:2525")
+	httpAddr := getenv("HTTP_BIND_ADDR", "127.0.0.1:8025")
+
+	vapidKey, err := delivery.GenerateVAPIDKey()
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to generate VAPID key")
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"SMTP_ADDR":         smtpAddr,
+		"SMS_GATEWAY_URL":   "http://" + httpAddr + "/sms",
+		"VAPID_PRIVATE_KEY": vapidKey,
+		"messages":          "http://" + httpAddr + "/messages",
+	}).Info("fake channels are ready")
+
+	var r fake.Recorder
+
+	l, err := net.Listen("tcp", smtpAddr)
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to listen SMTP")
+	}
+
+	go func() {
+		err := r.ServeSMTP(l)
+		if err != nil {
+			logrus.WithError(err).Fatal("failed to serve SMTP")
+		}
+	}()
+
+	err = http.ListenAndServe(httpAddr, r.Handler())
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to serve HTTP")
+	}
+}
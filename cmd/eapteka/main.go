@@ -2,11 +2,13 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
-	"math/rand"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -21,197 +23,306 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/gofiber/websocket/v2"
 	"github.com/jmoiron/sqlx"
-	"github.com/lib/pq"
 	_ "github.com/lib/pq"
 	"github.com/sirupsen/logrus"
-
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"eapteka/apierr"
+	"eapteka/apiver"
+	"eapteka/certs"
+	"eapteka/config"
+	"eapteka/delivery"
 	"eapteka/ent"
 	"eapteka/filesystem"
+	"eapteka/grpcapi"
+	"eapteka/health"
+	"eapteka/hub"
+	"eapteka/metrics"
 	"eapteka/migrations"
+	"eapteka/openapi"
 	"eapteka/pics"
+	"eapteka/ratelimit"
+	"eapteka/recommend"
+	"eapteka/refill"
+	"eapteka/reqctx"
+	"eapteka/scheduler"
+	"eapteka/store"
+	"eapteka/tracing"
 	"eapteka/ui"
 )
 
-func timeStrToGoTime(s string) (time.Time, error) {
-	ps := strings.Split(s, ":")
-	if len(ps) != 3 {
-		return time.Time{}, fmt.Errorf("invalid time format")
-	}
+// topicNotifiers is the hub topic of changed notifier IDs. Reminders and
+// refills are published to topics of their users, see scheduler.Topic and
+// refill.Topic.
+const topicNotifiers = "notifiers"
 
-	h, err := strconv.Atoi(ps[0])
-	if err != nil {
-		return time.Time{}, fmt.Errorf("parse hour: %w", err)
-	}
+const (
+	// wsHeartbeat is the interval of websocket pings.
+	wsHeartbeat    = 30 * time.Second
+	wsWriteTimeout = 10 * time.Second
+)
 
-	m, err := strconv.Atoi(ps[1])
-	if err != nil {
-		return time.Time{}, fmt.Errorf("parse minute: %w", err)
-	}
+// syncNotifiers applies notifier changes published by any replica to the
+// store cache until the hub is closing. Cache is reloaded if messages might
+// be lost.
+func syncNotifiers(h *hub.Hub, st *store.Store) {
+	client := h.Register(topicNotifiers, hub.TopicReconnected)
+
+	for {
+		var err error
+
+		select {
+		case <-h.Closing():
+			h.Unregister(client)
+			return
+		case <-client.Dropped():
+			client = h.Register(topicNotifiers, hub.TopicReconnected)
+			err = st.Load(context.Background())
+		case m := <-client.Queue():
+			if m.Topic == hub.TopicReconnected {
+				err = st.Load(context.Background())
+				break
+			}
 
-	tz, err := time.LoadLocation(ps[2])
-	if err != nil {
-		return time.Time{}, fmt.Errorf("parse time zone: %w", err)
-	}
+			var id int64
+			err = json.Unmarshal(m.Payload, &id)
+			if err == nil {
+				err = st.Refresh(context.Background(), id)
+			}
+		}
 
-	return time.Date(0, 0, 0, h, m, 0, 0, tz), nil
+		if err != nil {
+			logrus.WithError(err).Error("failed to sync notifiers")
+		}
+	}
 }
 
-func goTimeToTimeStr(t time.Time) string {
-	return t.Format("15:04:") + t.Location().String()
+func reminderEventError(err error) error {
+	switch {
+	case errors.Is(err, scheduler.ErrReminderNotFound):
+		return apierr.NotFound(err.Error())
+	case errors.Is(err, scheduler.ErrInvalidEvent):
+		return apierr.Validation(err.Error())
+	}
+	return err
 }
 
-func processRecommends(db *sqlx.DB) []int64 {
+func validateChannel(c ent.DeliveryChannel) error {
+	switch c.Type {
+	case ent.ChannelEmail, ent.ChannelSMS, ent.ChannelWebhook:
+	case ent.ChannelWebPush:
+		if c.Params["p256dh"] == "" || c.Params["auth"] == "" {
+			return apierr.Validation("p256dh and auth params are required",
+				apierr.Field("params", "p256dh and auth are required"))
+		}
+	default:
+		return apierr.Validation("unknown channel type",
+			apierr.Field("type", "unknown channel type"))
+	}
+	if c.Address == "" {
+		return apierr.Validation("address is required",
+			apierr.Field("address", "required"))
+	}
+	if c.Type == ent.ChannelWebhook || c.Type == ent.ChannelWebPush {
+		err := delivery.ValidateURL(c.Address)
+		if err != nil {
+			return apierr.InvalidField("address", err)
+		}
+	}
+	return nil
+}
 
-	rows, err := db.Query(`
-		select pp.product_id as product_id, p.created_at as created_at
-		from purchase as p
-		left join purchase_product as pp on p.id = pp.purchase_id
-		where product_id is not null and created_at >= $1
-		order by created_at desc ;
-	`, time.Now().AddDate(0, -6, 0))
+func main() {
+	cfg, err := config.Load(os.Args[0], os.Args[1:])
 	if err != nil {
-		logrus.WithError(err).Error("failed to get products")
-		return nil
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		logrus.WithError(err).Fatal("failed to load config")
 	}
 
-	purchases := map[int64][]time.Time{}
+	logrus.WithFields(cfg.Redacted()).Info("config loaded")
 
-	for rows.Next() {
-		var (
-			createdAt time.Time
-			productID int64
-		)
-
-		err := rows.Scan(&createdAt, &productID)
-		if err != nil {
-			logrus.WithError(err).Error("failed to get product")
-			continue
-		}
+	tracingShutdown, err := tracing.Setup(tracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.OTLPEndpoint,
+		Insecure:    cfg.Tracing.OTLPInsecure,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to setup tracing")
+	}
 
-		purchases[productID] = append(purchases[productID], createdAt)
+	channels := map[string]delivery.Channel{
+		ent.ChannelWebhook: delivery.NewWebhook(),
 	}
 
-	var recommends []int64
+	if cfg.Delivery.SMTPAddr != "" {
+		channels[ent.ChannelEmail] = delivery.NewEmail(delivery.EmailConfig{
+			Addr:     cfg.Delivery.SMTPAddr,
+			From:     cfg.Delivery.SMTPFrom,
+			Username: cfg.Delivery.SMTPUsername,
+			Password: cfg.Delivery.SMTPPassword,
+		})
+	}
 
-	const (
-		minInterval = 25 * 24 * time.Hour
-		maxInterval = 35 * 24 * time.Hour
+	if cfg.Delivery.SMSGatewayURL != "" {
+		channels[ent.ChannelSMS] = delivery.NewSMS(delivery.SMSConfig{
+			GatewayURL: cfg.Delivery.SMSGatewayURL,
+			Token:      cfg.Delivery.SMSGatewayToken,
+		})
+	}
 
-		maxSubInterval = 1 * 24 * time.Hour
-	)
+	var webPush *delivery.WebPush
 
-	for pID, ts := range purchases {
-		if len(ts) < 2 {
-			continue
-		}
-		var count int
-		for i := 1; i < len(ts); i++ {
-			interval := ts[i].Sub(ts[i-1])
-			if (interval < minInterval || interval > maxInterval) &&
-				maxSubInterval > interval {
-				break
-			}
-			count++
-		}
-		if count > 2 {
-			recommends = append(recommends, pID)
+	if cfg.Delivery.VAPIDPrivateKey != "" {
+		webPush, err = delivery.NewWebPush(delivery.WebPushConfig{
+			VAPIDPrivateKey: cfg.Delivery.VAPIDPrivateKey,
+			Subject:         cfg.Delivery.VAPIDSubject,
+		})
+		if err != nil {
+			logrus.WithError(err).Fatal("failed to create web push channel")
 		}
+		channels[ent.ChannelWebPush] = webPush
 	}
 
-	return recommends
-}
+	sqlDB, err := tracing.OpenDB(cfg.Postgres.DSN)
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to open DB")
+	}
 
-func main() {
-	pgDSN := os.Getenv("POSTGRES_DSN")
-	bindAddr := os.Getenv("BIND_ADDR")
-	tlsCert := os.Getenv("TLS_CERT")
-	tlsKey := os.Getenv("TLS_KEY")
+	db := sqlx.NewDb(sqlDB, "postgres")
 
-	db, err := sqlx.Open("postgres", pgDSN)
+	err = metrics.RegisterDB(db.DB, "eapteka")
 	if err != nil {
-		logrus.WithError(err).Fatal("failed to open DB")
+		logrus.WithError(err).Fatal("failed to register DB metrics")
 	}
 
-	err = migrations.Migrate(pgDSN)
+	err = migrations.Migrate(cfg.Postgres.DSN)
 	if err != nil {
 		logrus.WithError(err).Fatal("failed to migrate")
 	}
 
-	ws := fiber.New()
+	ws := fiber.New(fiber.Config{
+		ErrorHandler: apierr.Handler,
+		ProxyHeader:  cfg.Server.ProxyHeader,
+	})
 
-	ws.Use(recover.New(), logger.New(), cors.New())
+	// abort is closed when requests running on shutdown are out of time
+	abort := make(chan struct{})
 
-	api := ws.Group("/api")
+	ws.Use(requestid.New(), metrics.Middleware(), tracing.Middleware(),
+		reqctx.Middleware(reqctx.Config{
+			Timeout: cfg.Server.RequestTimeout,
+			Abort:   abort,
+		}),
+		recover.New(), logger.New(), cors.New(cors.Config{
+			AllowOrigins:  cfg.Server.CORSAllowOrigins,
+			ExposeHeaders: "Deprecation, Sunset, Link, Retry-After, X-Request-ID",
+		}))
 
-	api.Get("/query", func(ctx *fiber.Ctx) error {
-		keyword := ctx.Query("k", "")
-		if len(keyword) <= 3 {
-			return fiber.NewError(http.StatusBadRequest, "too short keyword")
-		}
+	ws.Get("/metrics", metrics.Handler())
 
-		var (
-			wg    sync.WaitGroup
-			ps    []ent.Product
-			psErr error
-			ss    []ent.Substance
-			ssErr error
-		)
+	hc := health.New(health.Config{})
 
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			psErr = db.Select(&ps, `
-				select substance_id, p.id as id, p.name as name, description,
-				       price, image_id, sku, s.name as substance_name 
-				from product p
-				    left join substance s on p.substance_id = s.id
-				where p.name % $1
-				order by similarity(p.name, $1) desc
-			`, keyword)
-		}()
+	ws.Get("/healthz", hc.LiveHandler())
+	ws.Get("/readyz", hc.ReadyHandler())
 
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			ssErr = db.Select(&ss, `
-				select * from substance s
-				where s.name % $1
-				order by similarity(name, $1) desc
-			`, keyword)
-		}()
+	hc.Add("postgres", db.PingContext)
+	hc.Add("migrations", func(ctx context.Context) error {
+		return migrations.Check(ctx, db.DB)
+	})
 
-		wg.Wait()
+	var (
+		limits       ratelimit.Store = ratelimit.NewMemory(ratelimit.MemoryConfig{})
+		limitsExpire *ratelimit.Postgres
+	)
 
-		if psErr != nil {
-			return psErr
-		}
-		if ssErr != nil {
-			return ssErr
-		}
+	if cfg.RateLimit.Store == "postgres" {
+		limitsExpire = ratelimit.NewPostgres(db, ratelimit.PostgresConfig{})
+		limitsExpire.Start()
+		limits = limitsExpire
 
-		return ctx.JSON(fiber.Map{
-			"products":   ps,
-			"substances": ss,
+		hc.Add("rate_limit", health.Running(limitsExpire.Running))
+	}
+
+	searchLimit := ratelimit.New(ratelimit.Config{
+		Name: "search",
+		Limit: ratelimit.PerMinute(cfg.RateLimit.SearchPerMinute,
+			cfg.RateLimit.SearchBurst),
+		Key:   ratelimit.IP,
+		Store: limits,
+	})
+
+	purchaseLimit := ratelimit.New(ratelimit.Config{
+		Name: "purchase",
+		Limit: ratelimit.PerMinute(cfg.RateLimit.PurchasePerMinute,
+			cfg.RateLimit.PurchaseBurst),
+		Key:   ratelimit.UserOrIP,
+		Store: limits,
+	})
+
+	wsHub := hub.New(hub.Config{DSN: cfg.Postgres.DSN})
+
+	err = wsHub.Start()
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to start websocket hub")
+	}
+
+	hc.Add("hub", func(context.Context) error {
+		return wsHub.Ping()
+	})
+
+	// Notifiers are cached by every replica, changes are published to
+	// refresh the caches of the others
+	st := store.New(db, store.Config{
+		MinKeywordLength: cfg.Server.MinKeywordLength,
+		OnChange: func(ctx context.Context, tx *sqlx.Tx, id int64) error {
+			return wsHub.Publish(ctx, tx, topicNotifiers, id)
+		},
+	})
+
+	err = st.Load(context.Background())
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to load notifiers")
+	}
+
+	go syncNotifiers(wsHub, st)
+
+	// Routes are served by the current version and by the unversioned
+	// alias kept for clients written before versioning. See apiver for
+	// compatibility policy, breaking changes go to /api/v2.
+	api := apiver.New(ws,
+		apiver.Version{Prefix: "/api/v1"},
+		apiver.Version{
+			Prefix:     "/api",
+			Deprecated: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+			Sunset:     time.Date(2027, 4, 19, 0, 0, 0, 0, time.UTC),
+			Successor:  "/api/v1",
 		})
+
+	api.Get("/openapi.json", openapi.Handler())
+	api.Get("/docs", openapi.UIHandler("/api/v1/openapi.json"))
+
+	api.Get("/query", searchLimit, func(ctx *fiber.Ctx) error {
+		r, err := st.Search(reqctx.Get(ctx), ctx.Query("k", ""))
+		if err != nil {
+			return err
+		}
+		return ctx.JSON(r)
 	})
 
 	api.Get("/products/:product_id", func(ctx *fiber.Ctx) error {
 		pID, err := ctx.ParamsInt("product_id")
 		if err != nil {
-			return fiber.NewError(http.StatusBadRequest, err.Error())
+			return apierr.InvalidField("product_id", err)
 		}
 
-		var p ent.Product
-
-		err = db.QueryRowx(`
-			select substance_id, p.id as id, p.name as name, description,
-				   price, image_id, sku, s.name as substance_name 
-			from product p
-				left join substance s on p.substance_id = s.id
-			where p.id = $1
-		`, pID).StructScan(&p)
+		p, err := st.Product(reqctx.Get(ctx), int64(pID))
 		if err != nil {
 			return err
 		}
@@ -221,7 +332,6 @@ func main() {
 
 	api.Get("/products", func(ctx *fiber.Ctx) error {
 		var (
-			ps          []ent.Product
 			substanceID int64
 			err         error
 		)
@@ -231,28 +341,11 @@ func main() {
 		if len(substanceIDstr) != 0 {
 			substanceID, err = strconv.ParseInt(substanceIDstr, 10, 64)
 			if err != nil {
-				return fiber.NewError(http.StatusBadRequest, err.Error())
+				return apierr.InvalidField("substance_id", err)
 			}
 		}
 
-		if substanceID != 0 {
-			err = db.Select(&ps, `
-				select p.id as id, p.name as name, description, price, image_id,
-						sku, s.name as substance_name, s.id as substance_id
-				from product p
-					left join substance s on p.substance_id = s.id
-				where substance_id = $1
-				order by id desc
-			`, substanceID)
-		} else {
-			err = db.Select(&ps, `
-				select p.id as id, p.name as name, description, price, image_id,
-						sku, s.name as substance_name, s.id as substance_id
-				from product p
-					left join substance s on p.substance_id = s.id
-				order by id desc
-			`)
-		}
+		ps, err := st.Products(reqctx.Get(ctx), substanceID)
 		if err != nil {
 			return err
 		}
@@ -262,7 +355,6 @@ func main() {
 
 	api.Get("/substances", func(ctx *fiber.Ctx) error {
 		var (
-			ss        []ent.Substance
 			productID int64
 			err       error
 		)
@@ -272,26 +364,11 @@ func main() {
 		if len(productIDstr) != 0 {
 			productID, err = strconv.ParseInt(productIDstr, 10, 64)
 			if err != nil {
-				return fiber.NewError(http.StatusBadRequest, err.Error())
+				return apierr.InvalidField("product_id", err)
 			}
 		}
 
-		if productID != 0 {
-			err = db.Select(&ss, `
-				select s.id as id, name 
-				from substance s
-					left join product p on p.substance_id = s.id
-				where p.id = $1
-				order by id desc
-			`, productID)
-
-		} else {
-			err = db.Select(&ss, `
-				select id, name
-				from substance s
-				order by id desc
-			`)
-		}
+		ss, err := st.Substances(reqctx.Get(ctx), productID)
 		if err != nil {
 			return err
 		}
@@ -300,9 +377,7 @@ func main() {
 	})
 
 	api.Get("/purchases", func(ctx *fiber.Ctx) error {
-		var ps []ent.Purchase
-
-		err := db.Select(&ps, `select * from purchase order by created_at desc`)
+		ps, err := st.Purchases(reqctx.Get(ctx), ctx.Query("user_id"))
 		if err != nil {
 			return err
 		}
@@ -314,18 +389,10 @@ func main() {
 		purchaseIDstr := ctx.Query("purchase_id", "")
 		purchaseID, err := strconv.ParseInt(purchaseIDstr, 10, 64)
 		if err != nil {
-			return fiber.NewError(http.StatusBadRequest, err.Error())
+			return apierr.InvalidField("purchase_id", err)
 		}
 
-		var ps []ent.Product
-
-		err = db.Select(&ps, `
-			select p.id as id, substance_id, p.name as name, description, image_id,
-					s.name as substance_name, count, pp.price as purchase_price 
-			from purchase_product pp
-			    left join product p on pp.product_id = p.id
-			    left join substance s on s.id = p.substance_id
-			where purchase_id = $1`, purchaseID)
+		ps, err := st.PurchaseProducts(reqctx.Get(ctx), purchaseID)
 		if err != nil {
 			return err
 		}
@@ -333,270 +400,798 @@ func main() {
 		return ctx.JSON(ps)
 	})
 
-	api.Post("/purchases", func(ctx *fiber.Ctx) (err error) {
+	api.Post("/purchases", purchaseLimit, func(ctx *fiber.Ctx) error {
 		var pps []ent.PurchaseProduct
 
-		err = json.Unmarshal(ctx.Body(), &pps)
+		err := json.Unmarshal(ctx.Body(), &pps)
 		if err != nil {
-			return fiber.NewError(http.StatusBadRequest, err.Error())
+			return apierr.Body(err)
 		}
 
-		tx, err := db.BeginTxx(context.TODO(), nil)
+		p, err := st.CreatePurchase(reqctx.Get(ctx), ctx.Query("user_id"), pps)
 		if err != nil {
 			return err
 		}
 
-		defer func() {
+		return ctx.JSON(p)
+	})
+
+	sch := scheduler.New(db, scheduler.Config{
+		Tick:        cfg.Scheduler.Tick,
+		MaxLateness: cfg.Scheduler.MaxLateness,
+		OnFire: func(ctx context.Context, tx *sqlx.Tx, r ent.Reminder) error {
+			err := delivery.Enqueue(ctx, tx, r)
 			if err != nil {
-				tx.Rollback()
+				return err
 			}
-		}()
+			n, err := st.Notifier(ctx, r.NotifierID)
+			if err != nil {
+				return err
+			}
+			return wsHub.Publish(ctx, tx, scheduler.Topic(n.UserID), r)
+		},
+	})
+	sch.Start()
 
-		var p ent.Purchase
+	hc.Add("scheduler", health.Running(sch.Running))
 
-		err = tx.QueryRowx(`insert into purchase default values returning *`).
-			StructScan(&p)
+	rp := refill.New(db, refill.Config{
+		Tick: cfg.Refill.Tick,
+		Lead: time.Duration(cfg.Refill.LeadDays) * 24 * time.Hour,
+		OnRefill: func(ctx context.Context, tx *sqlx.Tx, r ent.Refill) error {
+			err := delivery.EnqueueRefill(ctx, tx, r)
+			if err != nil {
+				return err
+			}
+			return wsHub.Publish(ctx, tx, refill.Topic(r.UserID), r)
+		},
+	})
+	rp.Start()
+
+	hc.Add("refill", health.Running(rp.Running))
+
+	api.Get("/notifiers", func(ctx *fiber.Ctx) error {
+		return ctx.JSON(st.Notifiers())
+	})
+
+	api.Get("/notifiers/:id", func(ctx *fiber.Ctx) error {
+		nID, err := ctx.ParamsInt("id")
+		if err != nil {
+			return apierr.InvalidField("id", err)
+		}
+
+		n, err := st.Notifier(reqctx.Get(ctx), int64(nID))
 		if err != nil {
 			return err
 		}
 
-		var pIDs []int64
-		for _, pp := range pps {
-			_, err = tx.Exec(`
-				insert into purchase_product(purchase_id, product_id, count, price)
- 				values ($1, $2, $3, $4)
-			`, p.ID, pp.ProductID, pp.Count, pp.Price)
+		return ctx.JSON(n)
+	})
+
+	api.Post("/notifiers", func(ctx *fiber.Ctx) error {
+		var n ent.Notifier
+
+		err := json.Unmarshal(ctx.Body(), &n)
+		if err != nil {
+			return apierr.Body(err)
+		}
+
+		n, err = st.CreateNotifier(reqctx.Get(ctx), n)
+		if err != nil {
+			return err
+		}
+
+		return ctx.JSON(n)
+	})
+
+	// updateNotifier handles PUT, which replaces the notifier, and PATCH,
+	// which changes only fields present in the request body.
+	updateNotifier := func(ctx *fiber.Ctx) error {
+		nID, err := ctx.ParamsInt("id")
+		if err != nil {
+			return apierr.InvalidField("id", err)
+		}
+
+		n, err := st.UpdateNotifier(reqctx.Get(ctx), int64(nID),
+			func(n *ent.Notifier) error {
+				if ctx.Method() == fiber.MethodPut {
+					*n = ent.Notifier{}
+				}
+				err := json.Unmarshal(ctx.Body(), n)
+				if err != nil {
+					return apierr.Body(err)
+				}
+				return nil
+			})
+		if err != nil {
+			return err
+		}
+
+		return ctx.JSON(n)
+	}
+
+	api.Put("/notifiers/:id", updateNotifier)
+	api.Patch("/notifiers/:id", updateNotifier)
+
+	api.Delete("/notifiers/:id", func(ctx *fiber.Ctx) error {
+		nID, err := ctx.ParamsInt("id")
+		if err != nil {
+			return apierr.InvalidField("id", err)
+		}
+
+		err = st.DeleteNotifier(reqctx.Get(ctx), int64(nID))
+		if err != nil {
+			return err
+		}
+
+		return ctx.SendStatus(http.StatusOK)
+	})
+
+	api.Get("/notifiers/:id/adherence", func(ctx *fiber.Ctx) error {
+		nID, err := ctx.ParamsInt("id")
+		if err != nil {
+			return apierr.InvalidField("id", err)
+		}
+
+		loc, err := time.LoadLocation(ctx.Query("tz", "UTC"))
+		if err != nil {
+			return apierr.InvalidField("tz", err)
+		}
+
+		period := ctx.Query("period", "day")
+		if period != "day" && period != "week" {
+			return apierr.Validation("invalid period",
+				apierr.Field("period", "must be day or week"))
+		}
+
+		to := time.Now()
+		if toStr := ctx.Query("to", ""); toStr != "" {
+			to, err = time.ParseInLocation("2006-01-02", toStr, loc)
 			if err != nil {
-				return err
+				return apierr.InvalidField("to", err)
+			}
+		}
+
+		from := to.AddDate(0, 0, -30)
+		if period == "week" {
+			from = to.AddDate(0, 0, -7*12)
+		}
+		if fromStr := ctx.Query("from", ""); fromStr != "" {
+			from, err = time.ParseInLocation("2006-01-02", fromStr, loc)
+			if err != nil {
+				return apierr.InvalidField("from", err)
 			}
-			pIDs = append(pIDs, pp.ProductID)
 		}
 
-		err = tx.Commit()
+		as, err := sch.Adherence(reqctx.Get(ctx), int64(nID), period, loc,
+			from, to)
 		if err != nil {
 			return err
 		}
 
-		err = db.Select(&p.Products, `
-			select * from product where id = ANY($1::BIGINT[])
-			order by id asc
-		`, pq.Array(pIDs))
+		return ctx.JSON(as)
+	})
+
+	api.Get("/reminders", func(ctx *fiber.Ctx) error {
+		nID, err := strconv.ParseInt(ctx.Query("notifier_id", ""), 10, 64)
+		if err != nil {
+			return apierr.InvalidField("notifier_id", err)
+		}
+
+		rs := []ent.Reminder{}
+
+		err = db.SelectContext(reqctx.Get(ctx), &rs, `
+			select id, notifier_id, scheduled_at, created_at, snoozed_until
+			from reminder where notifier_id = $1
+			order by scheduled_at desc
+			limit 100
+		`, nID)
 		if err != nil {
 			return err
 		}
 
-		return ctx.JSON(p)
+		return ctx.JSON(rs)
 	})
 
-	var (
-		nsMap = map[int64]ent.Notifier{}
-		nsMx  sync.RWMutex
-	)
-	func() {
-		var ns []ent.Notifier
-		err = db.Select(&ns, `
-			select n.id as id, product_id, schedule, p.name as product_name
-				from notifier as n left join product p on p.id = n.product_id
-		`)
+	api.Get("/reminders/:id/events", func(ctx *fiber.Ctx) error {
+		rID, err := ctx.ParamsInt("id")
+		if err != nil {
+			return apierr.InvalidField("id", err)
+		}
+
+		es, err := sch.Events(reqctx.Get(ctx), int64(rID))
 		if err != nil {
-			logrus.WithError(err).Fatal("failed to load notifiers")
+			return err
 		}
-		for _, n := range ns {
-			nsMap[n.ID] = n
+
+		return ctx.JSON(es)
+	})
+
+	api.Post("/reminders/:id/events", func(ctx *fiber.Ctx) error {
+		rID, err := ctx.ParamsInt("id")
+		if err != nil {
+			return apierr.InvalidField("id", err)
 		}
-	}()
 
-	api.Get("/notifiers", func(ctx *fiber.Ctx) error {
+		var e ent.ReminderEvent
 
-		nsMx.RLock()
-		defer nsMx.RUnlock()
+		err = json.Unmarshal(ctx.Body(), &e)
+		if err != nil {
+			return apierr.Body(err)
+		}
 
-		var ns []ent.Notifier
-		for _, n := range nsMap {
-			ns = append(ns, n)
+		e.ReminderID = int64(rID)
+
+		e, err = sch.AddEvent(reqctx.Get(ctx), e)
+		if err != nil {
+			return reminderEventError(err)
 		}
 
-		return ctx.JSON(ns)
+		return ctx.JSON(e)
 	})
 
-	api.Post("/notifiers", func(ctx *fiber.Ctx) error {
-		var n ent.Notifier
+	api.Get("/users/:user_id/channels", func(ctx *fiber.Ctx) error {
+		cs := []ent.DeliveryChannel{}
+
+		err := db.SelectContext(reqctx.Get(ctx), &cs, `
+			select * from delivery_channel where user_id = $1 order by id
+		`, ctx.Params("user_id"))
+		if err != nil {
+			return err
+		}
+
+		return ctx.JSON(cs)
+	})
+
+	api.Post("/users/:user_id/channels", func(ctx *fiber.Ctx) error {
+		c := ent.DeliveryChannel{Enabled: true}
 
-		err = json.Unmarshal(ctx.Body(), &n)
+		err := json.Unmarshal(ctx.Body(), &c)
 		if err != nil {
-			return fiber.NewError(http.StatusBadRequest, err.Error())
+			return apierr.Body(err)
 		}
 
-		err = db.QueryRowx(`
-			select name from product where id = $1
-		`, n.ProductID).Scan(&n.ProductName)
+		c.UserID = ctx.Params("user_id")
+
+		err = validateChannel(c)
 		if err != nil {
 			return err
 		}
 
-		for i, s := range n.Schedule {
-			t, err := timeStrToGoTime(s)
-			if err != nil {
-				return fiber.NewError(http.StatusBadRequest, err.Error())
+		err = db.QueryRowxContext(reqctx.Get(ctx), `
+			insert into delivery_channel(user_id, type, address, params, enabled)
+			values ($1, $2, $3, $4, $5)
+			returning *
+		`, c.UserID, c.Type, c.Address, c.Params, c.Enabled).StructScan(&c)
+		if err != nil {
+			return err
+		}
+
+		return ctx.JSON(c)
+	})
+
+	api.Patch("/channels/:id", func(ctx *fiber.Ctx) error {
+		cID, err := ctx.ParamsInt("id")
+		if err != nil {
+			return apierr.InvalidField("id", err)
+		}
+
+		var c ent.DeliveryChannel
+
+		err = db.QueryRowxContext(reqctx.Get(ctx), `
+			select * from delivery_channel where id = $1
+		`, cID).StructScan(&c)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return apierr.NotFound("channel not found")
 			}
-			n.Schedule[i] = goTimeToTimeStr(t)
+			return err
+		}
+
+		err = json.Unmarshal(ctx.Body(), &c)
+		if err != nil {
+			return apierr.Body(err)
 		}
 
-		err = db.QueryRowx(`
-			insert into notifier(product_id, schedule) values ($1, $2)
-			returning id
-		`, n.ProductID, pq.Array(n.Schedule)).Scan(&n.ID)
+		err = validateChannel(c)
 		if err != nil {
 			return err
 		}
 
-		nsMx.Lock()
-		nsMap[n.ID] = n
-		nsMx.Unlock()
+		err = db.QueryRowxContext(reqctx.Get(ctx), `
+			update delivery_channel
+			set type = $2, address = $3, params = $4, enabled = $5
+			where id = $1
+			returning *
+		`, cID, c.Type, c.Address, c.Params, c.Enabled).StructScan(&c)
+		if err != nil {
+			return err
+		}
 
-		return ctx.JSON(n)
+		return ctx.JSON(c)
 	})
 
-	api.Delete("/notifiers/:id", func(ctx *fiber.Ctx) error {
-		nID, err := ctx.ParamsInt("id")
+	api.Delete("/channels/:id", func(ctx *fiber.Ctx) error {
+		cID, err := ctx.ParamsInt("id")
 		if err != nil {
-			return fiber.NewError(http.StatusBadRequest, err.Error())
+			return apierr.InvalidField("id", err)
 		}
 
-		_, err = db.Exec(`delete from notifier where id = $1`, nID)
+		_, err = db.ExecContext(reqctx.Get(ctx),
+			`delete from delivery_channel where id = $1`, cID)
 		if err != nil {
 			return err
 		}
 
-		nsMx.Lock()
-		delete(nsMap, int64(nID))
-		nsMx.Unlock()
-
 		return ctx.SendStatus(http.StatusOK)
 	})
 
-	api.Get("/experts/:substance_id", func(ctx *fiber.Ctx) error {
-		sID, err := ctx.ParamsInt("substance_id")
+	api.Get("/reminders/:id/deliveries", func(ctx *fiber.Ctx) error {
+		rID, err := ctx.ParamsInt("id")
 		if err != nil {
-			return fiber.NewError(http.StatusBadRequest, err.Error())
+			return apierr.InvalidField("id", err)
 		}
 
-		var e ent.Expert
+		ds := []ent.Delivery{}
 
-		err = db.QueryRowx(`
-			select * from expert where substance_id = $1
-		`, sID).StructScan(&e)
+		err = db.SelectContext(reqctx.Get(ctx), &ds, `
+			select id, reminder_id, channel_id, status, attempts,
+			       next_attempt_at, last_error, created_at
+			from delivery where reminder_id = $1
+			order by id
+		`, rID)
 		if err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
-				return ctx.SendStatus(http.StatusNotFound)
+			return err
+		}
+
+		return ctx.JSON(ds)
+	})
+
+	api.Get("/webpush/vapid_public_key", func(ctx *fiber.Ctx) error {
+		if webPush == nil {
+			return apierr.NotFound("web push is not configured")
+		}
+		return ctx.JSON(fiber.Map{"public_key": webPush.PublicKey()})
+	})
+
+	dispatcher := delivery.NewDispatcher(db, channels, delivery.Config{
+		Tick:        cfg.Delivery.Tick,
+		MaxAttempts: cfg.Delivery.MaxAttempts,
+		BaseURL:     cfg.Server.PublicURL,
+	})
+	dispatcher.Start()
+
+	hc.Add("delivery", health.Running(dispatcher.Running))
+
+	api.Get("/users/:user_id/refills", func(ctx *fiber.Ctx) error {
+		ps, err := rp.Predict(reqctx.Get(ctx), ctx.Params("user_id"))
+		if err != nil {
+			return err
+		}
+		return ctx.JSON(ps)
+	})
+
+	api.Get("/users/:user_id/refills/history", func(ctx *fiber.Ctx) error {
+		rs, err := rp.Refills(reqctx.Get(ctx), ctx.Params("user_id"))
+		if err != nil {
+			return err
+		}
+		return ctx.JSON(rs)
+	})
+
+	api.Post("/refills/:id/reorder", func(ctx *fiber.Ctx) error {
+		rID, err := ctx.ParamsInt("id")
+		if err != nil {
+			return apierr.InvalidField("id", err)
+		}
+
+		p, err := rp.Reorder(reqctx.Get(ctx), int64(rID))
+		if err != nil {
+			if errors.Is(err, refill.ErrRefillNotFound) {
+				return apierr.NotFound(err.Error())
 			}
 			return err
 		}
 
-		return ctx.JSON(e)
+		return ctx.JSON(p)
 	})
 
-	var (
-		wsNotifierClose = make(chan struct{})
-		wsNotifierWg    sync.WaitGroup
-	)
+	// Reorder links of refill reminders open the confirm page, which posts
+	// back to reorder
+	ws.Get("/refills/:id/reorder", rp.ConfirmHandler())
+	ws.Post("/refills/:id/reorder", rp.ReorderHandler())
+
+	api.Get("/experts/:substance_id", func(ctx *fiber.Ctx) error {
+		sID, err := ctx.ParamsInt("substance_id")
+		if err != nil {
+			return apierr.InvalidField("substance_id", err)
+		}
+
+		e, err := st.Expert(reqctx.Get(ctx), int64(sID))
+		if err != nil {
+			return err
+		}
+
+		return ctx.JSON(e)
+	})
 
 	ws.Get("/ws/notifier", websocket.New(func(c *websocket.Conn) {
-		wsNotifierWg.Add(1)
-		defer wsNotifierWg.Done()
 		defer c.Close()
 
-		t := time.NewTicker(30 * time.Second)
-		defer t.Stop()
+		conns := metrics.WebsocketConnections.WithLabelValues("/ws/notifier")
+		conns.Inc()
+		defer conns.Dec()
 
-		for {
-			select {
-			case <-wsNotifierClose:
-				return
-			case <-t.C:
+		connCtx, connCancel := context.WithCancel(context.Background())
+		defer connCancel()
+
+		// opContext bounds DB calls made for the connection like requests
+		opContext := func() (context.Context, context.CancelFunc) {
+			return context.WithTimeout(connCtx, cfg.Server.RequestTimeout)
+		}
+
+		var writeMx sync.Mutex
+
+		send := func(typ, id string, data interface{}) error {
+			m := ent.NotifierMessage{
+				Version: ent.NotifierProtocolVersion,
+				Type:    typ,
+				ID:      id,
 			}
+			if data != nil {
+				d, err := json.Marshal(data)
+				if err != nil {
+					return err
+				}
+				m.Data = d
+			}
+
+			writeMx.Lock()
+			defer writeMx.Unlock()
+			c.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			return c.WriteJSON(m)
+		}
 
-			now := time.Now()
-			nowStr := goTimeToTimeStr(now)
+		closeWith := func(code int, reason string) {
+			writeMx.Lock()
+			defer writeMx.Unlock()
+			c.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(code, reason),
+				time.Now().Add(wsWriteTimeout))
+		}
+
+		if v := c.Query("v"); v != "" && v != strconv.Itoa(ent.NotifierProtocolVersion) {
+			closeWith(websocket.CloseProtocolError, "unsupported protocol version")
+			return
+		}
 
-			nsMx.RLock()
-			for _, n := range nsMap {
-				for _, s := range n.Schedule {
-					if nowStr != s {
-						continue
+		// The client is subscribed to topics of the users it selects
+		client := wsHub.Register()
+		defer wsHub.Unregister(client)
+
+		var (
+			sub    ent.NotifierSubscription
+			topics []string
+			subMx  sync.Mutex
+			done   = make(chan struct{})
+		)
+
+		// Any message from the client, including pong frames, postpones
+		// the read deadline
+		c.SetReadDeadline(time.Now().Add(2 * wsHeartbeat))
+		c.SetPongHandler(func(string) error {
+			return c.SetReadDeadline(time.Now().Add(2 * wsHeartbeat))
+		})
+
+		err := send(ent.NotifierMsgHello, "", ent.NotifierHello{
+			HeartbeatSeconds: int(wsHeartbeat.Seconds()),
+		})
+		if err != nil {
+			return
+		}
+
+		// Reader must finish before the connection is released
+		defer func() {
+			c.Close()
+			<-done
+		}()
+
+		// Read client messages
+		go func() {
+			defer close(done)
+			for {
+				var m ent.NotifierMessage
+				if err := c.ReadJSON(&m); err != nil {
+					var (
+						se *json.SyntaxError
+						te *json.UnmarshalTypeError
+					)
+					if errors.As(err, &se) || errors.As(err, &te) {
+						closeWith(websocket.CloseUnsupportedData, "invalid message")
 					}
-					msg := fmt.Sprintf("Вам необходимо выпить лекарство \"%s\".", n.ProductName)
-					if err = c.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
-						return
+					return
+				}
+
+				c.SetReadDeadline(time.Now().Add(2 * wsHeartbeat))
+
+				if m.Version != ent.NotifierProtocolVersion {
+					closeWith(websocket.CloseProtocolError,
+						"unsupported protocol version")
+					return
+				}
+
+				var err error
+
+				switch m.Type {
+				case ent.NotifierMsgPing:
+					err = send(ent.NotifierMsgPong, m.ID, nil)
+
+				case ent.NotifierMsgSubscribe:
+					var s ent.NotifierSubscription
+					if err = json.Unmarshal(m.Data, &s); err != nil {
+						err = send(ent.NotifierMsgError, m.ID,
+							ent.NotifierError{Message: err.Error()})
+						break
+					}
+					var users []string
+					opCtx, cancel := opContext()
+					users, err = st.SubscribedUsers(opCtx, s)
+					cancel()
+					if err != nil {
+						logrus.WithError(err).Error("failed to get subscribed users")
+						err = send(ent.NotifierMsgError, m.ID, ent.NotifierError{
+							Message: apierr.From(err).Message,
+						})
+						break
+					}
+					ts := make([]string, 0, len(users)+1)
+					for _, u := range users {
+						ts = append(ts, scheduler.Topic(u))
+					}
+					if s.UserID != "" {
+						ts = append(ts, refill.Topic(s.UserID))
 					}
+					subMx.Lock()
+					wsHub.Unsubscribe(client, topics...)
+					wsHub.Subscribe(client, ts...)
+					sub, topics = s, ts
+					subMx.Unlock()
+					err = send(ent.NotifierMsgSubscribed, m.ID, s)
+
+				case ent.NotifierMsgEvent:
+					var e ent.ReminderEvent
+					if err = json.Unmarshal(m.Data, &e); err != nil {
+						err = send(ent.NotifierMsgError, m.ID,
+							ent.NotifierError{Message: err.Error()})
+						break
+					}
+					opCtx, cancel := opContext()
+					e, err = sch.AddEvent(opCtx, e)
+					cancel()
+					if err != nil {
+						err = send(ent.NotifierMsgError, m.ID, ent.NotifierError{
+							Message: apierr.From(reminderEventError(err)).Message,
+						})
+						break
+					}
+					err = send(ent.NotifierMsgEvent, m.ID, e)
+
+				default:
+					err = send(ent.NotifierMsgError, m.ID, ent.NotifierError{
+						Message: fmt.Sprintf("unknown message type %q", m.Type),
+					})
+				}
+				if err != nil {
+					return
 				}
 			}
-			nsMx.RUnlock()
-		}
-	}))
+		}()
 
-	var (
-		wsRecommendsClose = make(chan struct{})
-		wsRecommends      = make(chan ent.Product)
-		wsRecommendsWg    sync.WaitGroup
-	)
+		// forward sends reminder or refill to the client if it is subscribed
+		forward := func(m hub.Message) error {
+			subMx.Lock()
+			s := sub
+			subMx.Unlock()
+
+			switch {
+			case strings.HasPrefix(m.Topic, scheduler.Topic("")):
+				var r ent.Reminder
+				if err := json.Unmarshal(m.Payload, &r); err != nil {
+					return err
+				}
 
-	wsRecommendsWg.Add(1)
-	go func() {
-		defer wsRecommendsWg.Done()
+				opCtx, cancel := opContext()
+				n, err := st.Notifier(opCtx, r.NotifierID)
+				cancel()
+				if err != nil {
+					if !errors.Is(err, store.ErrNotifierNotFound) {
+						logrus.WithError(err).Error("failed to get notifier")
+					}
+					return nil
+				}
+				if !store.Subscribed(s, n) {
+					return nil
+				}
 
-		t := time.NewTicker(1 * time.Hour)
-		var p ent.Product
+				return send(ent.NotifierMsgReminder, "", ent.NotifierReminder{
+					ReminderID:   r.ID,
+					NotifierID:   n.ID,
+					ProductID:    n.ProductID,
+					ProductName:  n.ProductName,
+					ScheduledAt:  r.ScheduledAt,
+					SnoozedUntil: r.SnoozedUntil,
+					DoseAmount:   n.DoseAmount,
+					DoseUnit:     n.DoseUnit,
+					Notes:        n.Notes,
+				})
+
+			case strings.HasPrefix(m.Topic, refill.Topic("")):
+				var rf ent.Refill
+				if err := json.Unmarshal(m.Payload, &rf); err != nil {
+					return err
+				}
+
+				// Refills are sent to subscribers of their user only, the
+				// client may have resubscribed since it was queued
+				if s.UserID == "" || s.UserID != rf.UserID {
+					return nil
+				}
+
+				return send(ent.NotifierMsgRefill, "", rf)
+			}
+
+			return nil
+		}
+
+		t := time.NewTicker(wsHeartbeat)
+		defer t.Stop()
 
 		for {
 			select {
-			case <-wsRecommendsClose:
+			case <-wsHub.Closing():
+				for len(client.Queue()) > 0 {
+					if err := forward(<-client.Queue()); err != nil {
+						return
+					}
+				}
+				closeWith(websocket.CloseGoingAway, "server shutdown")
+				return
+			case <-client.Dropped():
+				closeWith(websocket.CloseTryAgainLater, "slow consumer")
+				return
+			case <-done:
 				return
 			case <-t.C:
+				writeMx.Lock()
+				err := c.WriteControl(websocket.PingMessage, nil,
+					time.Now().Add(wsWriteTimeout))
+				writeMx.Unlock()
+				if err != nil {
+					return
+				}
+			case m := <-client.Queue():
+				if err := forward(m); err != nil {
+					return
+				}
 			}
+		}
+	}))
 
-			recommends := processRecommends(db)
+	recommender := recommend.New(db, recommend.Config{
+		Window: cfg.Recommend.Window,
+		Limit:  cfg.Recommend.Limit,
+		Reload: cfg.Recommend.Reload,
+	},
+		recommend.NewPeriodic(recommend.Periodic{}),
+		recommend.NewCoPurchase(recommend.CoPurchase{}))
 
-			pID := recommends[rand.Intn(len(recommends))]
+	err = recommender.Reload(context.Background())
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to load purchase history")
+	}
 
-			err = db.QueryRowx(`
-				select p.id as id, p.name as name, description, price, image_id,
-						sku, s.name as substance_name, s.id as substance_id
-				from product p
-					left join substance s on p.substance_id = s.id
-				where p.id = $1
-			`, pID).StructScan(&p)
-			if err != nil {
-				logrus.WithError(err).Error("failed to get product")
-				continue
-			}
+	recommender.Start()
 
-			wsRecommends <- p
+	hc.Add("recommender", health.Running(recommender.Running))
+
+	api.Get("/recommendations", func(ctx *fiber.Ctx) error {
+		rs, err := recommender.Recommend(reqctx.Get(ctx),
+			ctx.Query("user_id"))
+		if err != nil {
+			return err
 		}
-	}()
+		if rs == nil {
+			rs = []ent.Recommendation{}
+		}
+		return ctx.JSON(rs)
+	})
+
+	// Unknown API routes get JSON error, must be registered after every
+	// API route
+	api.Use(func(ctx *fiber.Ctx) error {
+		return apierr.NotFound("route not found")
+	})
+
+	recommendsBroadcaster := recommend.NewBroadcaster(recommender, wsHub,
+		cfg.Recommend.Tick)
+	recommendsBroadcaster.Start()
+
+	hc.Add("recommend", health.Running(recommendsBroadcaster.Running))
 
 	ws.Get("/ws/recommends", websocket.New(func(c *websocket.Conn) {
-		wsRecommendsWg.Add(1)
-		defer wsRecommendsWg.Done()
 		defer c.Close()
 
-		var p ent.Product
+		conns := metrics.WebsocketConnections.WithLabelValues("/ws/recommends")
+		conns.Inc()
+		defer conns.Dec()
+
+		client := wsHub.Register(recommend.Topic(c.Query("user_id")))
+		defer wsHub.Unregister(client)
+
+		closeWith := func(code int, reason string) {
+			c.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(code, reason),
+				time.Now().Add(wsWriteTimeout))
+		}
+
+		done := make(chan struct{})
+
+		// Reader must finish before the connection is released
+		defer func() {
+			c.Close()
+			<-done
+		}()
+
+		// Read and discard client messages to process close frames
+		go func() {
+			defer close(done)
+			for {
+				if _, _, err := c.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		// forward sends product of the recommendation to the client
+		forward := func(m hub.Message) error {
+			var r ent.Recommendation
+			if err := json.Unmarshal(m.Payload, &r); err != nil {
+				return err
+			}
+			if r.Product == nil {
+				return nil
+			}
+			c.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			return c.WriteJSON(r.Product)
+		}
 
 		for {
 			select {
-			case <-wsRecommendsClose:
+			case <-wsHub.Closing():
+				for len(client.Queue()) > 0 {
+					if err := forward(<-client.Queue()); err != nil {
+						return
+					}
+				}
+				closeWith(websocket.CloseGoingAway, "server shutdown")
 				return
-			case p = <-wsRecommends:
-			}
-
-			if err = c.WriteJSON(p); err != nil {
+			case <-client.Dropped():
+				closeWith(websocket.CloseTryAgainLater, "slow consumer")
+				return
+			case <-done:
 				return
+			case m := <-client.Queue():
+				if err := forward(m); err != nil {
+					return
+				}
 			}
 		}
 	}))
 
 	ws.Use("/pics", filesystem.New(filesystem.Config{
-		Root: http.FS(pics.FS),
+		Root:      http.FS(pics.FS),
+		ETag:      true,
+		ByteRange: true,
 	}))
 
 	ws.Use(filesystem.New(filesystem.Config{
@@ -606,46 +1201,179 @@ func main() {
 				strings.HasPrefix(path, "/pics/") ||
 				strings.HasPrefix(path, "/ws/")
 		},
-		Root:         http.FS(ui.FS),
-		Index:        "index.html",
-		NotFoundFile: "index.html",
-		RootPath:     "dist",
+		Root:      http.FS(ui.FS),
+		Index:     "index.html",
+		SPAIndex:  "index.html",
+		RootPath:  "dist",
+		ETag:      true,
+		Compress:  true,
+		ByteRange: true,
 	}))
 
+	var (
+		tlsConfig   *tls.Config
+		redirect    http.Handler
+		certsReload *certs.Reloader
+	)
+
+	_, httpsPort, err := net.SplitHostPort(cfg.Server.BindAddr)
+	if err != nil {
+		logrus.WithError(err).Fatal("invalid bind address")
+	}
+
+	switch {
+	case cfg.ACME.Domains != "":
+		m, err := certs.NewACME(certs.ACMEConfig{
+			Domains:      strings.Split(cfg.ACME.Domains, ","),
+			Email:        cfg.ACME.Email,
+			CacheDir:     cfg.ACME.CacheDir,
+			DirectoryURL: cfg.ACME.DirectoryURL,
+			CARoot:       cfg.ACME.CARoot,
+		})
+		if err != nil {
+			logrus.WithError(err).Fatal("failed to create ACME manager")
+		}
+		tlsConfig = m.TLSConfig()
+		redirect = m.HTTPHandler(certs.Redirect(httpsPort))
+
+	case cfg.Server.TLSCert != "":
+		certsReload, err = certs.NewReloader(cfg.Server.TLSCert,
+			cfg.Server.TLSKey, cfg.Server.CertReloadInterval)
+		if err != nil {
+			logrus.WithError(err).Fatal("failed to load TLS certificate")
+		}
+		certsReload.Start()
+		tlsConfig = certsReload.TLSConfig()
+		redirect = certs.Redirect(httpsPort)
+	}
+
+	var grpcServer *grpcapi.Server
+
+	if cfg.GRPC.BindAddr != "" {
+		var opts []grpc.ServerOption
+		if tlsConfig != nil {
+			opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		}
+
+		grpcServer = grpcapi.New(st, wsHub, recommender, grpcapi.Config{
+			Timeout: cfg.Server.RequestTimeout,
+		}, opts...)
+	}
+
+	ln, err := net.Listen("tcp", cfg.Server.BindAddr)
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to listen")
+	}
+
+	if tlsConfig != nil {
+		ln = tls.NewListener(ln, certs.HTTP1(tlsConfig))
+	}
+
 	var wg sync.WaitGroup
 
-	if tlsCert != "" && tlsKey != "" {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		err := ws.Listener(ln)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.WithError(err).Fatal("failed to start web server")
+		}
+	}()
+
+	if grpcServer != nil {
+		grpcLn, err := net.Listen("tcp", cfg.GRPC.BindAddr)
+		if err != nil {
+			logrus.WithError(err).Fatal("failed to listen gRPC")
+		}
+
+		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			err := ws.ListenTLS(bindAddr, tlsCert, tlsKey)
-			if err != nil && !errors.Is(err, http.ErrServerClosed) {
-				logrus.WithError(err).Fatal("failed to start web server")
+			err := grpcServer.Serve(grpcLn)
+			if err != nil {
+				logrus.WithError(err).Fatal("failed to start gRPC server")
 			}
 		}()
-	} else {
+	}
+
+	var redirectServer *http.Server
+
+	if tlsConfig != nil && cfg.Server.RedirectAddr != "" {
+		redirectServer = &http.Server{
+			Addr:         cfg.Server.RedirectAddr,
+			Handler:      redirect,
+			ReadTimeout:  10 * time.Second,
+			WriteTimeout: 10 * time.Second,
+		}
+
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			err := ws.Listen(bindAddr)
+			err := redirectServer.ListenAndServe()
 			if err != nil && !errors.Is(err, http.ErrServerClosed) {
-				logrus.WithError(err).Fatal("failed to start web server")
+				logrus.WithError(err).Fatal("failed to start redirect server")
 			}
 		}()
 	}
 
-	exit := make(chan os.Signal)
+	exit := make(chan os.Signal, 1)
 	signal.Notify(exit, os.Interrupt, syscall.SIGTERM)
 	<-exit
 
-	close(wsNotifierClose)
-	close(wsRecommendsClose)
+	hc.Shutdown()
+	if grpcServer != nil {
+		grpcServer.Drain()
+	}
+	time.Sleep(cfg.Server.ShutdownDelay)
+
+	sch.Stop()
+	rp.Stop()
+	recommendsBroadcaster.Stop()
+	recommender.Stop()
+	dispatcher.Stop()
+
+	if limitsExpire != nil {
+		limitsExpire.Stop()
+	}
+
+	wsHub.Close()
+
+	abortTimer := time.AfterFunc(cfg.Server.ShutdownTimeout, func() {
+		close(abort)
+		if grpcServer != nil {
+			grpcServer.Stop()
+		}
+	})
 
 	err = ws.Shutdown()
 	if err != nil {
 		logrus.WithError(err).Fatal("failed to shutdown web server")
 	}
 
-	wsNotifierWg.Wait()
-	wsRecommendsWg.Wait()
+	if grpcServer != nil {
+		grpcServer.GracefulStop()
+	}
+
+	abortTimer.Stop()
+
+	if redirectServer != nil {
+		err = redirectServer.Shutdown(context.Background())
+		if err != nil {
+			logrus.WithError(err).Error("failed to shutdown redirect server")
+		}
+	}
+
+	if certsReload != nil {
+		certsReload.Stop()
+	}
+
 	wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = tracingShutdown(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("failed to flush spans")
+	}
 }
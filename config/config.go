@@ -0,0 +1,265 @@
+// Package config loads service configuration from defaults, YAML or TOML
+// file, environment and command line flags, in order of increasing
+// priority.
+//
+// Every setting is a field of Config tagged with its file key, environment
+// variable and flag name. Sections are nested structs with file key only.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrInvalid = errors.New("invalid config")
+
+type Server struct {
+	BindAddr  string `key:"bind_addr" env:"BIND_ADDR" flag:"bind-addr" usage:"address the web server listens on"`
+	TLSCert   string `key:"tls_cert" env:"TLS_CERT" flag:"tls-cert" usage:"TLS certificate file"`
+	TLSKey    string `key:"tls_key" env:"TLS_KEY" flag:"tls-key" usage:"TLS private key file"`
+	PublicURL string `key:"public_url" env:"PUBLIC_URL" flag:"public-url" usage:"public URL of the service used in links"`
+
+	// RedirectAddr is the address of HTTP listener redirecting to HTTPS and
+	// serving ACME http-01 challenges, used with TLS only.
+	RedirectAddr string `key:"redirect_addr" env:"REDIRECT_ADDR" flag:"redirect-addr" usage:"address of HTTP to HTTPS redirect listener, disabled if empty"`
+
+	// RequestTimeout is the deadline of every request including its DB
+	// queries.
+	RequestTimeout time.Duration `key:"request_timeout" env:"REQUEST_TIMEOUT" flag:"request-timeout" usage:"maximum duration of the request"`
+
+	// ShutdownTimeout is the time running requests have to complete on
+	// shutdown before they are cancelled.
+	ShutdownTimeout time.Duration `key:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" flag:"shutdown-timeout" usage:"time running requests have to complete on shutdown"`
+
+	// ShutdownDelay is the time between readiness turning false and
+	// closing the listener, so load balancer stops sending requests first.
+	ShutdownDelay time.Duration `key:"shutdown_delay" env:"SHUTDOWN_DELAY" flag:"shutdown-delay" usage:"delay of shutdown after readiness turns false"`
+
+	CertReloadInterval time.Duration `key:"cert_reload_interval" env:"CERT_RELOAD_INTERVAL" flag:"cert-reload-interval" usage:"interval of checking TLS certificate files for changes"`
+
+	// ProxyHeader is the header with client IP set by load balancer, e.g.
+	// X-Real-IP. Clients can spoof it unless the balancer overwrites it.
+	ProxyHeader string `key:"proxy_header" env:"PROXY_HEADER" flag:"proxy-header" usage:"header with client IP set by load balancer, remote address is used if empty"`
+
+	CORSAllowOrigins string `key:"cors_allow_origins" env:"CORS_ALLOW_ORIGINS" flag:"cors-allow-origins" usage:"comma separated origins allowed by CORS"`
+
+	// MinKeywordLength is the minimum number of characters of the search
+	// keyword.
+	MinKeywordLength int `key:"min_keyword_length" env:"MIN_KEYWORD_LENGTH" flag:"min-keyword-length" usage:"minimum search keyword length"`
+}
+
+// ACME enables automatic TLS certificates for the domains instead of
+// certificate files.
+type ACME struct {
+	Domains      string `key:"domains" env:"ACME_DOMAINS" flag:"acme-domains" usage:"comma separated domains to obtain certificates for, ACME is disabled if empty"`
+	Email        string `key:"email" env:"ACME_EMAIL" flag:"acme-email" usage:"ACME account contact email"`
+	CacheDir     string `key:"cache_dir" env:"ACME_CACHE_DIR" flag:"acme-cache-dir" usage:"directory to cache ACME account and certificates"`
+	DirectoryURL string `key:"directory_url" env:"ACME_DIRECTORY_URL" flag:"acme-directory-url" usage:"ACME directory URL, Let's Encrypt if empty"`
+	CARoot       string `key:"ca_root" env:"ACME_CA_ROOT" flag:"acme-ca-root" usage:"PEM file with roots trusted for ACME server, e.g. of local pebble"`
+}
+
+type Postgres struct {
+	DSN string `key:"dsn" env:"POSTGRES_DSN" flag:"postgres-dsn" secret:"true" usage:"Postgres connection string"`
+}
+
+type Scheduler struct {
+	Tick        time.Duration `key:"tick" env:"SCHEDULER_TICK" flag:"scheduler-tick" usage:"interval of checking for due notifiers"`
+	MaxLateness time.Duration `key:"max_lateness" env:"SCHEDULER_MAX_LATENESS" flag:"scheduler-max-lateness" usage:"maximum delay of the reminder after which it is skipped"`
+}
+
+type Recommend struct {
+	Tick   time.Duration `key:"tick" env:"RECOMMEND_TICK" flag:"recommend-tick" usage:"interval of sending recommendations"`
+	Window time.Duration `key:"window" env:"RECOMMEND_WINDOW" flag:"recommend-window" usage:"age of the oldest purchase used for recommendations"`
+	Limit  int           `key:"limit" env:"RECOMMEND_LIMIT" flag:"recommend-limit" usage:"maximum number of recommendations"`
+	Reload time.Duration `key:"reload" env:"RECOMMEND_RELOAD" flag:"recommend-reload" usage:"interval of reloading purchase history"`
+}
+
+type Refill struct {
+	Tick     time.Duration `key:"tick" env:"REFILL_TICK" flag:"refill-tick" usage:"interval of predicting run out times"`
+	LeadDays int           `key:"lead_days" env:"REFILL_LEAD_DAYS" flag:"refill-lead-days" usage:"days before running out the user is reminded to refill"`
+}
+
+type Delivery struct {
+	Tick        time.Duration `key:"tick" env:"DELIVERY_TICK" flag:"delivery-tick" usage:"interval of checking for pending deliveries"`
+	MaxAttempts int           `key:"max_attempts" env:"DELIVERY_MAX_ATTEMPTS" flag:"delivery-max-attempts" usage:"number of attempts after which delivery fails"`
+
+	SMTPAddr     string `key:"smtp_addr" env:"SMTP_ADDR" flag:"smtp-addr" usage:"SMTP server host:port, email is disabled if empty"`
+	SMTPFrom     string `key:"smtp_from" env:"SMTP_FROM" flag:"smtp-from" usage:"email sender address"`
+	SMTPUsername string `key:"smtp_username" env:"SMTP_USERNAME" flag:"smtp-username" usage:"SMTP username"`
+	SMTPPassword string `key:"smtp_password" env:"SMTP_PASSWORD" flag:"smtp-password" secret:"true" usage:"SMTP password"`
+
+	SMSGatewayURL   string `key:"sms_gateway_url" env:"SMS_GATEWAY_URL" flag:"sms-gateway-url" usage:"SMS gateway URL, SMS is disabled if empty"`
+	SMSGatewayToken string `key:"sms_gateway_token" env:"SMS_GATEWAY_TOKEN" flag:"sms-gateway-token" secret:"true" usage:"SMS gateway token"`
+
+	VAPIDPrivateKey string `key:"vapid_private_key" env:"VAPID_PRIVATE_KEY" flag:"vapid-private-key" secret:"true" usage:"web push VAPID private key, web push is disabled if empty"`
+	VAPIDSubject    string `key:"vapid_subject" env:"VAPID_SUBJECT" flag:"vapid-subject" usage:"web push VAPID subject"`
+}
+
+type Tracing struct {
+	Exporter     string  `key:"exporter" env:"TRACING_EXPORTER" flag:"tracing-exporter" usage:"span exporter: stdout or otlp, tracing is disabled if empty"`
+	OTLPEndpoint string  `key:"otlp_endpoint" env:"OTLP_ENDPOINT" flag:"otlp-endpoint" usage:"host:port of OTLP HTTP receiver, OTEL_EXPORTER_OTLP_ENDPOINT is used if empty"`
+	OTLPInsecure bool    `key:"otlp_insecure" env:"OTLP_INSECURE" flag:"otlp-insecure" usage:"disable TLS of OTLP exporter"`
+	SampleRatio  float64 `key:"sample_ratio" env:"TRACING_SAMPLE_RATIO" flag:"tracing-sample-ratio" usage:"fraction of sampled traces"`
+}
+
+// RateLimit sets budgets of expensive routes per client, requests per
+// minute with burst.
+type RateLimit struct {
+	Store string `key:"store" env:"RATE_LIMIT_STORE" flag:"rate-limit-store" usage:"store of rate limit buckets: memory of the replica or postgres shared by replicas"`
+
+	SearchPerMinute   int `key:"search_per_minute" env:"RATE_LIMIT_SEARCH_PER_MINUTE" flag:"rate-limit-search-per-minute" usage:"search requests per minute of the client IP"`
+	SearchBurst       int `key:"search_burst" env:"RATE_LIMIT_SEARCH_BURST" flag:"rate-limit-search-burst" usage:"search requests at once of the client IP"`
+	PurchasePerMinute int `key:"purchase_per_minute" env:"RATE_LIMIT_PURCHASE_PER_MINUTE" flag:"rate-limit-purchase-per-minute" usage:"purchases per minute of the user or client IP"`
+	PurchaseBurst     int `key:"purchase_burst" env:"RATE_LIMIT_PURCHASE_BURST" flag:"rate-limit-purchase-burst" usage:"purchases at once of the user or client IP"`
+}
+
+// GRPC serves gRPC API for internal services with TLS of the web server.
+type GRPC struct {
+	BindAddr string `key:"bind_addr" env:"GRPC_BIND_ADDR" flag:"grpc-bind-addr" usage:"address the gRPC server listens on, gRPC is disabled if empty"`
+}
+
+type Config struct {
+	Server    Server    `key:"server"`
+	ACME      ACME      `key:"acme"`
+	Postgres  Postgres  `key:"postgres"`
+	Scheduler Scheduler `key:"scheduler"`
+	Recommend Recommend `key:"recommend"`
+	Refill    Refill    `key:"refill"`
+	Delivery  Delivery  `key:"delivery"`
+	Tracing   Tracing   `key:"tracing"`
+	RateLimit RateLimit `key:"rate_limit"`
+	GRPC      GRPC      `key:"grpc"`
+}
+
+var ConfigDefault = Config{
+	Server: Server{
+		BindAddr:         ":80",
+		CORSAllowOrigins: "*",
+		MinKeywordLength: 3,
+
+		CertReloadInterval: time.Minute,
+		RequestTimeout:     10 * time.Second,
+		ShutdownTimeout:    10 * time.Second,
+	},
+	ACME: ACME{
+		CacheDir: "acme-cache",
+	},
+	Scheduler: Scheduler{
+		Tick:        10 * time.Second,
+		MaxLateness: 10 * time.Minute,
+	},
+	Recommend: Recommend{
+		Tick:   time.Hour,
+		Window: 180 * 24 * time.Hour,
+		Limit:  10,
+		Reload: 10 * time.Minute,
+	},
+	Refill: Refill{
+		Tick:     time.Hour,
+		LeadDays: 3,
+	},
+	Delivery: Delivery{
+		Tick:        5 * time.Second,
+		MaxAttempts: 8,
+	},
+	Tracing: Tracing{
+		SampleRatio: 1,
+	},
+	RateLimit: RateLimit{
+		Store:             "memory",
+		SearchPerMinute:   60,
+		SearchBurst:       10,
+		PurchasePerMinute: 10,
+		PurchaseBurst:     3,
+	},
+}
+
+// Validate checks required settings and ranges.
+func (c Config) Validate() error {
+	var errs []string
+
+	if c.Postgres.DSN == "" {
+		errs = append(errs, "postgres.dsn is required")
+	}
+	if c.Server.BindAddr == "" {
+		errs = append(errs, "server.bind_addr is required")
+	}
+	if (c.Server.TLSCert == "") != (c.Server.TLSKey == "") {
+		errs = append(errs, "server.tls_cert and server.tls_key must be set together")
+	}
+	if c.ACME.Domains != "" && c.Server.TLSCert != "" {
+		errs = append(errs, "acme.domains and server.tls_cert are mutually exclusive")
+	}
+	if c.ACME.Domains != "" && c.ACME.CacheDir == "" {
+		errs = append(errs, "acme.cache_dir is required with acme.domains")
+	}
+	// ACME http-01 challenges are served by the redirect listener only
+	if c.ACME.Domains != "" && c.Server.RedirectAddr == "" {
+		errs = append(errs, "server.redirect_addr is required with acme.domains")
+	}
+	if c.GRPC.BindAddr != "" && c.GRPC.BindAddr == c.Server.BindAddr {
+		errs = append(errs, "grpc.bind_addr must differ from server.bind_addr")
+	}
+	if c.Server.ShutdownDelay < 0 {
+		errs = append(errs, "server.shutdown_delay must not be negative")
+	}
+	if c.Server.MinKeywordLength < 1 {
+		errs = append(errs, "server.min_keyword_length must be positive")
+	}
+
+	positive := []struct {
+		name  string
+		value int64
+	}{
+		{"server.cert_reload_interval", int64(c.Server.CertReloadInterval)},
+		{"server.request_timeout", int64(c.Server.RequestTimeout)},
+		{"server.shutdown_timeout", int64(c.Server.ShutdownTimeout)},
+		{"scheduler.tick", int64(c.Scheduler.Tick)},
+		{"scheduler.max_lateness", int64(c.Scheduler.MaxLateness)},
+		{"recommend.tick", int64(c.Recommend.Tick)},
+		{"recommend.window", int64(c.Recommend.Window)},
+		{"recommend.limit", int64(c.Recommend.Limit)},
+		{"recommend.reload", int64(c.Recommend.Reload)},
+		{"refill.tick", int64(c.Refill.Tick)},
+		{"refill.lead_days", int64(c.Refill.LeadDays)},
+		{"delivery.tick", int64(c.Delivery.Tick)},
+		{"delivery.max_attempts", int64(c.Delivery.MaxAttempts)},
+		{"rate_limit.search_per_minute", int64(c.RateLimit.SearchPerMinute)},
+		{"rate_limit.search_burst", int64(c.RateLimit.SearchBurst)},
+		{"rate_limit.purchase_per_minute", int64(c.RateLimit.PurchasePerMinute)},
+		{"rate_limit.purchase_burst", int64(c.RateLimit.PurchaseBurst)},
+	}
+
+	for _, p := range positive {
+		if p.value <= 0 {
+			errs = append(errs, p.name+" must be positive")
+		}
+	}
+
+	if c.Delivery.SMTPAddr != "" && c.Delivery.SMTPFrom == "" {
+		errs = append(errs, "delivery.smtp_from is required with delivery.smtp_addr")
+	}
+
+	switch c.Tracing.Exporter {
+	case "", "stdout", "otlp":
+	default:
+		errs = append(errs, "tracing.exporter must be stdout or otlp")
+	}
+	if c.Tracing.SampleRatio <= 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, "tracing.sample_ratio must be in (0, 1]")
+	}
+
+	switch c.RateLimit.Store {
+	case "memory", "postgres":
+	default:
+		errs = append(errs, "rate_limit.store must be memory or postgres")
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalid, strings.Join(errs, "; "))
+	}
+
+	return nil
+}
@@ -0,0 +1,302 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// FileEnv is the environment variable with config file path, which may be
+// also set with -config flag.
+const FileEnv = "CONFIG_FILE"
+
+const redacted = "***"
+
+// field is the setting of the config.
+type field struct {
+	key    string
+	env    string
+	flag   string
+	usage  string
+	secret bool
+	value  reflect.Value
+}
+
+// fields returns settings of the config section with keys prefixed with the
+// section key.
+func fields(prefix string, v reflect.Value) []field {
+	var fs []field
+
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+
+		key := sf.Tag.Get("key")
+		if key == "" {
+			continue
+		}
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if sf.Type.Kind() == reflect.Struct && sf.Type != reflect.TypeOf(time.Duration(0)) {
+			fs = append(fs, fields(key, v.Field(i))...)
+			continue
+		}
+
+		fs = append(fs, field{
+			key:    key,
+			env:    sf.Tag.Get("env"),
+			flag:   sf.Tag.Get("flag"),
+			usage:  sf.Tag.Get("usage"),
+			secret: sf.Tag.Get("secret") == "true",
+			value:  v.Field(i),
+		})
+	}
+
+	return fs
+}
+
+func (f field) set(s string) error {
+	switch f.value.Interface().(type) {
+	case time.Duration:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		f.value.SetInt(int64(d))
+	case string:
+		f.value.SetString(s)
+	case int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		f.value.SetInt(int64(n))
+	case float64:
+		x, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		f.value.SetFloat(x)
+	case bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		f.value.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported type %s", f.value.Type())
+	}
+	return nil
+}
+
+func (f field) String() string {
+	if d, ok := f.value.Interface().(time.Duration); ok {
+		return d.String()
+	}
+	return fmt.Sprint(f.value.Interface())
+}
+
+// Load returns config with defaults overridden by config file, environment
+// and flags parsed from the arguments, and validates it.
+func Load(name string, args []string) (Config, error) {
+	c := ConfigDefault
+	fs := fields("", reflect.ValueOf(&c).Elem())
+
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+
+	file := flags.String("config", os.Getenv(FileEnv),
+		"YAML or TOML config file, also "+FileEnv+" environment variable")
+
+	flagValues := map[string]*string{}
+	for _, f := range fs {
+		usage := f.usage
+		if f.env != "" {
+			usage += ", also " + f.env + " environment variable"
+		}
+		def := f.String()
+		if f.secret {
+			def = ""
+		}
+		flagValues[f.flag] = flags.String(f.flag, def, usage)
+	}
+
+	err := flags.Parse(args)
+	if err != nil {
+		return Config{}, err
+	}
+
+	if *file != "" {
+		err = loadFile(*file, fs)
+		if err != nil {
+			return Config{}, err
+		}
+	}
+
+	for _, f := range fs {
+		s, ok := os.LookupEnv(f.env)
+		if f.env == "" || !ok {
+			continue
+		}
+		err = f.set(s)
+		if err != nil {
+			return Config{}, fmt.Errorf("%w: environment variable %s: %v",
+				ErrInvalid, f.env, err)
+		}
+	}
+
+	visited := map[string]bool{}
+	flags.Visit(func(fl *flag.Flag) {
+		visited[fl.Name] = true
+	})
+
+	for _, f := range fs {
+		if !visited[f.flag] {
+			continue
+		}
+		err = f.set(*flagValues[f.flag])
+		if err != nil {
+			return Config{}, fmt.Errorf("%w: flag -%s: %v", ErrInvalid,
+				f.flag, err)
+		}
+	}
+
+	return c, c.Validate()
+}
+
+// loadFile sets fields from YAML or TOML file chosen by its extension.
+func loadFile(path string, fs []field) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	var values map[string]interface{}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		return fmt.Errorf("%w: unsupported config file extension %q",
+			ErrInvalid, filepath.Ext(path))
+	}
+	if err != nil {
+		return fmt.Errorf("%w: parse config file: %v", ErrInvalid, err)
+	}
+
+	flat := map[string]string{}
+	empty := map[string]bool{}
+	flatten("", values, flat, empty)
+
+	byKey := map[string]field{}
+	for _, f := range fs {
+		byKey[f.key] = f
+	}
+
+	var unknown []string
+
+	// Empty sections and keys without value set nothing, but still must
+	// be known
+	for k := range empty {
+		if !known(k, fs) {
+			unknown = append(unknown, k)
+		}
+	}
+
+	for k, s := range flat {
+		f, ok := byKey[k]
+		if !ok {
+			unknown = append(unknown, k)
+			continue
+		}
+		err = f.set(s)
+		if err != nil {
+			return fmt.Errorf("%w: config file key %s: %v", ErrInvalid, k, err)
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("%w: unknown config file keys: %s", ErrInvalid,
+			strings.Join(unknown, ", "))
+	}
+
+	return nil
+}
+
+// known reports whether the key is the setting or the section of settings.
+func known(key string, fs []field) bool {
+	for _, f := range fs {
+		if f.key == key || strings.HasPrefix(f.key, key+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// flatten puts values of nested YAML or TOML maps to the flat map with dot
+// separated keys. Lists are joined with commas. Keys of null values, like
+// YAML section without settings, are put to empty.
+func flatten(prefix string, v interface{}, flat map[string]string,
+	empty map[string]bool) {
+
+	join := func(k interface{}) string {
+		if prefix == "" {
+			return fmt.Sprint(k)
+		}
+		return prefix + "." + fmt.Sprint(k)
+	}
+
+	switch v := v.(type) {
+	case nil:
+		if prefix != "" {
+			empty[prefix] = true
+		}
+	case map[string]interface{}:
+		for k, vv := range v {
+			flatten(join(k), vv, flat, empty)
+		}
+	case map[interface{}]interface{}:
+		for k, vv := range v {
+			flatten(join(k), vv, flat, empty)
+		}
+	case []interface{}:
+		ss := make([]string, 0, len(v))
+		for _, vv := range v {
+			ss = append(ss, fmt.Sprint(vv))
+		}
+		flat[prefix] = strings.Join(ss, ",")
+	default:
+		flat[prefix] = fmt.Sprint(v)
+	}
+}
+
+// Redacted returns settings by their file keys with secrets hidden, to be
+// logged at startup.
+func (c Config) Redacted() map[string]interface{} {
+	m := map[string]interface{}{}
+
+	for _, f := range fields("", reflect.ValueOf(&c).Elem()) {
+		if f.secret && !f.value.IsZero() {
+			m[f.key] = redacted
+			continue
+		}
+		m[f.key] = f.String()
+	}
+
+	return m
+}
@@ -0,0 +1,403 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+
+	"eapteka/ent"
+	"eapteka/metrics"
+)
+
+// ErrPermanent marks errors which are not worth retrying, e.g. expired push
+// subscription or invalid address.
+var ErrPermanent = errors.New("permanent delivery error")
+
+// Message is the reminder or refill to deliver.
+type Message struct {
+	Reminder *ent.Reminder `json:"reminder,omitempty"`
+	Notifier *ent.Notifier `json:"notifier,omitempty"`
+	Refill   *ent.Refill   `json:"refill,omitempty"`
+
+	// ReorderURL is the page confirming one-click reorder of refill.
+	ReorderURL string `json:"reorder_url,omitempty"`
+
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+}
+
+// Text returns human readable reminder text of the notifier.
+func Text(n ent.Notifier) string {
+	if n.DoseAmount > 0 {
+		return fmt.Sprintf("Вам необходимо выпить лекарство \"%s\": %g %s.",
+			n.ProductName, n.DoseAmount, n.DoseUnit)
+	}
+	return fmt.Sprintf("Вам необходимо выпить лекарство \"%s\".", n.ProductName)
+}
+
+// RefillText returns human readable text of the refill.
+func RefillText(r ent.Refill) string {
+	return fmt.Sprintf("Лекарство \"%s\" закончится %s, пора заказать ещё.",
+		r.ProductName, r.RunOutAt.Format("02.01.2006"))
+}
+
+// Channel sends messages to the user channel of some type.
+type Channel interface {
+	Send(ctx context.Context, c ent.DeliveryChannel, m Message) error
+}
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// Enqueue creates pending deliveries of the reminder to every enabled channel
+// of the notifier's user. It is called in the transaction which fires the
+// reminder, so every reminder is enqueued exactly once.
+func Enqueue(ctx context.Context, tx *sqlx.Tx, r ent.Reminder) error {
+	_, err := tx.ExecContext(ctx, `
+		insert into delivery(reminder_id, channel_id)
+		select $1, c.id
+		from notifier n
+		    join delivery_channel c on c.user_id = n.user_id
+		where n.id = $2 and n.user_id != '' and c.enabled
+	`, r.ID, r.NotifierID)
+	if err != nil {
+		return fmt.Errorf("enqueue deliveries: %w", err)
+	}
+	return nil
+}
+
+// EnqueueRefill creates pending deliveries of the refill to every enabled
+// channel of its user. It is called in the transaction which creates the
+// refill.
+func EnqueueRefill(ctx context.Context, tx *sqlx.Tx, r ent.Refill) error {
+	_, err := tx.ExecContext(ctx, `
+		insert into delivery(refill_id, channel_id)
+		select $1, c.id
+		from delivery_channel c
+		where c.user_id = $2 and $2 != '' and c.enabled
+	`, r.ID, r.UserID)
+	if err != nil {
+		return fmt.Errorf("enqueue refill deliveries: %w", err)
+	}
+	return nil
+}
+
+type Config struct {
+	// Tick is the interval of checking for pending deliveries.
+	Tick time.Duration
+
+	// BatchSize is the maximum number of deliveries claimed at once.
+	BatchSize int
+
+	// MaxAttempts is the number of attempts after which delivery fails.
+	MaxAttempts int
+
+	// MinBackoff is the delay after the first failed attempt, it doubles
+	// after each next one up to MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// Lease is the time claimed delivery is hidden from other replicas.
+	Lease time.Duration
+
+	// BaseURL is the public URL of the service used in reorder links.
+	BaseURL string
+}
+
+var ConfigDefault = Config{
+	Tick:        5 * time.Second,
+	BatchSize:   50,
+	MaxAttempts: 8,
+	MinBackoff:  30 * time.Second,
+	MaxBackoff:  time.Hour,
+	Lease:       time.Minute,
+}
+
+// Dispatcher sends pending deliveries with the channel of their type and
+// retries failed ones with exponential backoff. Deliveries are claimed
+// with "skip locked" and a lease, so any number of replicas may run
+// dispatchers concurrently.
+type Dispatcher struct {
+	db       *sqlx.DB
+	cfg      Config
+	channels map[string]Channel
+
+	running int32
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	close chan struct{}
+	wg    sync.WaitGroup
+}
+
+func NewDispatcher(db *sqlx.DB, channels map[string]Channel,
+	cfg Config) *Dispatcher {
+
+	if cfg.Tick == 0 {
+		cfg.Tick = ConfigDefault.Tick
+	}
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = ConfigDefault.BatchSize
+	}
+	if cfg.MaxAttempts == 0 {
+		cfg.MaxAttempts = ConfigDefault.MaxAttempts
+	}
+	if cfg.MinBackoff == 0 {
+		cfg.MinBackoff = ConfigDefault.MinBackoff
+	}
+	if cfg.MaxBackoff == 0 {
+		cfg.MaxBackoff = ConfigDefault.MaxBackoff
+	}
+	if cfg.Lease == 0 {
+		cfg.Lease = ConfigDefault.Lease
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Dispatcher{
+		db:       db,
+		cfg:      cfg,
+		channels: channels,
+		ctx:      ctx,
+		cancel:   cancel,
+		close:    make(chan struct{}),
+	}
+}
+
+func (d *Dispatcher) Start() {
+	atomic.StoreInt32(&d.running, 1)
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		defer atomic.StoreInt32(&d.running, 0)
+
+		t := time.NewTicker(d.cfg.Tick)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-d.close:
+				return
+			case <-t.C:
+			}
+
+			for {
+				n, err := d.dispatch(d.ctx)
+				if err != nil {
+					logrus.WithError(err).Error("failed to dispatch deliveries")
+					break
+				}
+				if n < d.cfg.BatchSize {
+					break
+				}
+			}
+		}
+	}()
+}
+
+func (d *Dispatcher) Stop() {
+	close(d.close)
+	d.cancel()
+	d.wg.Wait()
+}
+
+// Running reports whether the dispatch loop is running.
+func (d *Dispatcher) Running() bool {
+	return atomic.LoadInt32(&d.running) == 1
+}
+
+type claimedDelivery struct {
+	ent.Delivery
+	Channel ent.DeliveryChannel `db:"channel"`
+}
+
+// dispatch claims and sends one batch of pending deliveries and returns its
+// size.
+func (d *Dispatcher) dispatch(ctx context.Context) (int, error) {
+	var ds []claimedDelivery
+
+	err := d.db.SelectContext(ctx, &ds, `
+		with claimed as (
+			update delivery
+			set locked_until = now() + $2 * interval '1 second',
+			    attempts = attempts + 1
+			where id in (
+				select id from delivery
+				where status = 'pending' and next_attempt_at <= now() and
+				      (locked_until is null or locked_until < now())
+				order by next_attempt_at
+				limit $1
+				for update skip locked
+			)
+			returning *
+		)
+		select d.id, d.reminder_id, d.refill_id, d.channel_id, d.status,
+		       d.attempts, d.next_attempt_at, d.last_error, d.created_at,
+		       c.id as "channel.id", c.user_id as "channel.user_id",
+		       c.type as "channel.type", c.address as "channel.address",
+		       c.params as "channel.params", c.enabled as "channel.enabled",
+		       c.created_at as "channel.created_at"
+		from claimed d
+		    join delivery_channel c on c.id = d.channel_id
+	`, d.cfg.BatchSize, d.cfg.Lease.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("claim deliveries: %w", err)
+	}
+
+	var wg sync.WaitGroup
+
+	for _, cd := range ds {
+		wg.Add(1)
+		go func(cd claimedDelivery) {
+			defer wg.Done()
+			d.send(ctx, cd)
+		}(cd)
+	}
+
+	wg.Wait()
+
+	return len(ds), nil
+}
+
+func (d *Dispatcher) send(ctx context.Context, cd claimedDelivery) {
+	log := logrus.WithFields(logrus.Fields{
+		"delivery_id": cd.ID,
+		"channel":     cd.Channel.Type,
+		"attempt":     cd.Attempts,
+	})
+
+	err := d.sendMessage(ctx, cd)
+	if err == nil {
+		_, err = d.db.ExecContext(ctx, `
+			update delivery set status = 'sent', locked_until = null,
+			                    last_error = ''
+			where id = $1
+		`, cd.ID)
+		if err != nil {
+			log.WithError(err).Error("failed to mark delivery sent")
+		}
+		metrics.Deliveries.WithLabelValues(cd.Channel.Type, ent.DeliverySent).Inc()
+		return
+	}
+
+	log.WithError(err).Warn("failed to send delivery")
+
+	status := ent.DeliveryPending
+	if errors.Is(err, ErrPermanent) || cd.Attempts >= d.cfg.MaxAttempts {
+		status = ent.DeliveryFailed
+	}
+
+	metrics.Deliveries.WithLabelValues(cd.Channel.Type, status).Inc()
+
+	_, err = d.db.ExecContext(ctx, `
+		update delivery set status = $2, next_attempt_at = $3,
+		                    locked_until = null, last_error = $4
+		where id = $1
+	`, cd.ID, status, time.Now().Add(d.backoff(cd.Attempts)), err.Error())
+	if err != nil {
+		log.WithError(err).Error("failed to mark delivery failed")
+	}
+}
+
+func (d *Dispatcher) sendMessage(ctx context.Context,
+	cd claimedDelivery) error {
+
+	ch, ok := d.channels[cd.Channel.Type]
+	if !ok {
+		return fmt.Errorf("%w: channel %q is not configured", ErrPermanent,
+			cd.Channel.Type)
+	}
+
+	m, err := d.message(ctx, cd.Delivery)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.cfg.Lease/2)
+	defer cancel()
+
+	return ch.Send(ctx, cd.Channel, m)
+}
+
+// message loads reminder or refill of the delivery.
+func (d *Dispatcher) message(ctx context.Context, dl ent.Delivery) (Message,
+	error) {
+
+	if dl.RefillID != nil {
+		var r ent.Refill
+
+		err := d.db.QueryRowxContext(ctx, `
+			select r.id as id, user_id, product_id, purchase_id, run_out_at,
+			       remaining, reorder_purchase_id, r.created_at as created_at,
+			       p.name as product_name
+			from refill r
+			    join product p on p.id = r.product_id
+			where r.id = $1
+		`, *dl.RefillID).StructScan(&r)
+		if err != nil {
+			return Message{}, fmt.Errorf("select refill: %w", err)
+		}
+
+		return Message{
+			Refill: &r,
+			ReorderURL: fmt.Sprintf("%s/refills/%d/reorder",
+				strings.TrimSuffix(d.cfg.BaseURL, "/"), r.ID),
+			Subject: "Лекарство заканчивается",
+			Text:    RefillText(r),
+		}, nil
+	}
+
+	var rn struct {
+		Reminder ent.Reminder `db:"reminder"`
+		Notifier ent.Notifier `db:"notifier"`
+	}
+
+	err := d.db.QueryRowxContext(ctx, `
+		select r.id as "reminder.id", r.notifier_id as "reminder.notifier_id",
+		       r.scheduled_at as "reminder.scheduled_at",
+		       r.created_at as "reminder.created_at",
+		       r.snoozed_until as "reminder.snoozed_until",
+		       n.id as "notifier.id", n.user_id as "notifier.user_id",
+		       n.product_id as "notifier.product_id",
+		       n.schedule as "notifier.schedule", n.rule as "notifier.rule",
+		       n.dose_amount as "notifier.dose_amount",
+		       n.dose_unit as "notifier.dose_unit", n.notes as "notifier.notes",
+		       n.paused as "notifier.paused",
+		       p.name as "notifier.product_name"
+		from reminder r
+		    join notifier n on n.id = r.notifier_id
+		    left join product p on p.id = n.product_id
+		where r.id = $1
+	`, dl.ReminderID).StructScan(&rn)
+	if err != nil {
+		return Message{}, fmt.Errorf("select reminder: %w", err)
+	}
+
+	return Message{
+		Reminder: &rn.Reminder,
+		Notifier: &rn.Notifier,
+		Subject:  "Напоминание о приёме лекарства",
+		Text:     Text(rn.Notifier),
+	}, nil
+}
+
+// backoff returns delay before the next attempt after given attempts.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	b := d.cfg.MinBackoff
+	for i := 1; i < attempts && b < d.cfg.MaxBackoff; i++ {
+		b *= 2
+	}
+	if b > d.cfg.MaxBackoff {
+		b = d.cfg.MaxBackoff
+	}
+	return b
+}
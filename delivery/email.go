@@ -0,0 +1,82 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+
+	"eapteka/ent"
+)
+
+type EmailConfig struct {
+	// Addr is SMTP server host:port.
+	Addr     string
+	From     string
+	Username string
+	Password string
+}
+
+// Email sends reminders with SMTP server.
+type Email struct {
+	cfg EmailConfig
+}
+
+func NewEmail(cfg EmailConfig) *Email {
+	return &Email{cfg: cfg}
+}
+
+func (e *Email) Send(ctx context.Context, c ent.DeliveryChannel, m Message) error {
+	to, err := mail.ParseAddress(c.Address)
+	if err != nil {
+		return fmt.Errorf("%w: parse address: %v", ErrPermanent, err)
+	}
+
+	var auth smtp.Auth
+	if e.cfg.Username != "" {
+		host, _, err := net.SplitHostPort(e.cfg.Addr)
+		if err != nil {
+			return fmt.Errorf("parse SMTP address: %w", err)
+		}
+		auth = smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, host)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", e.cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", to.String())
+	fmt.Fprintf(&msg, "Subject: %s\r\n",
+		mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: text/plain; charset=utf-8\r\n")
+	fmt.Fprintf(&msg, "\r\n%s\r\n", m.Text)
+	if m.Notifier != nil && m.Notifier.Notes != "" {
+		fmt.Fprintf(&msg, "\r\n%s\r\n", m.Notifier.Notes)
+	}
+	if m.ReorderURL != "" {
+		fmt.Fprintf(&msg, "\r\n%s\r\n", m.ReorderURL)
+	}
+
+	// net/smtp doesn't support context, so send in background and give up
+	// waiting when context is done
+	errs := make(chan error, 1)
+	go func() {
+		errs <- smtp.SendMail(e.cfg.Addr, auth, e.cfg.From,
+			[]string{to.Address}, msg.Bytes())
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err = <-errs:
+	}
+	if err != nil {
+		return fmt.Errorf("send mail: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"eapteka/delivery/fake"
+	"eapteka/ent"
+)
+
+func TestEmailSend(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	var rec fake.Recorder
+	go rec.ServeSMTP(ln)
+
+	e := NewEmail(EmailConfig{
+		Addr: ln.Addr().String(),
+		From: "eapteka@eapteka.test",
+	})
+
+	n := ent.Notifier{ProductName: "Парацетамол", Notes: "После еды"}
+	m := Message{Notifier: &n, Subject: "Напоминание о приёме",
+		Text: Text(n)}
+
+	err = e.Send(context.Background(), ent.DeliveryChannel{
+		Type:    ent.ChannelEmail,
+		Address: "Пользователь <user@eapteka.test>",
+	}, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ms := rec.Messages()
+	if len(ms) != 1 {
+		t.Fatalf("%d messages received, want 1", len(ms))
+	}
+	got := ms[0]
+
+	if got.From != "eapteka@eapteka.test" {
+		t.Errorf("from = %q, want eapteka@eapteka.test", got.From)
+	}
+	if len(got.To) != 1 || got.To[0] != "user@eapteka.test" {
+		t.Errorf("to = %v, want [user@eapteka.test]", got.To)
+	}
+	for _, s := range []string{
+		"Subject: =?utf-8?q?",
+		"Content-Type: text/plain; charset=utf-8",
+		m.Text,
+		n.Notes,
+	} {
+		if !strings.Contains(got.Body, s) {
+			t.Errorf("mail doesn't contain %q:\n%s", s, got.Body)
+		}
+	}
+
+	err = e.Send(context.Background(), ent.DeliveryChannel{
+		Type:    ent.ChannelEmail,
+		Address: "not an address",
+	}, m)
+	if !errors.Is(err, ErrPermanent) {
+		t.Errorf("Send() to invalid address error = %v, want permanent", err)
+	}
+}
@@ -0,0 +1,213 @@
+// Package fake contains local stand-ins of SMTP server, SMS gateway, push
+// service and webhook receiver which record everything they receive. They
+// are used to try reminder delivery without external services.
+package fake
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Message is the message received by a fake server.
+type Message struct {
+	Kind       string            `json:"kind"`
+	Path       string            `json:"path,omitempty"`
+	From       string            `json:"from,omitempty"`
+	To         []string          `json:"to,omitempty"`
+	Header     map[string]string `json:"header,omitempty"`
+	Body       string            `json:"body"`
+	ReceivedAt time.Time         `json:"received_at"`
+}
+
+// Recorder keeps received messages.
+type Recorder struct {
+	mx       sync.Mutex
+	messages []Message
+}
+
+func (r *Recorder) add(m Message) {
+	m.ReceivedAt = time.Now()
+
+	r.mx.Lock()
+	r.messages = append(r.messages, m)
+	r.mx.Unlock()
+
+	logrus.WithFields(logrus.Fields{
+		"kind": m.Kind,
+		"path": m.Path,
+		"to":   m.To,
+	}).Info("message received")
+}
+
+// Messages returns copy of received messages.
+func (r *Recorder) Messages() []Message {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return append([]Message{}, r.messages...)
+}
+
+// Handler returns HTTP handler which records POST requests to /sms, /push/*
+// and /webhook/* paths and lists received messages on GET /messages.
+// Requests to paths ending with /fail are answered with 500 to try retries,
+// to paths ending with /gone with 410.
+func (r *Recorder) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/messages", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(r.Messages())
+	})
+
+	record := func(kind string) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			if req.Method != http.MethodPost {
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+
+			body, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			header := map[string]string{}
+			for k := range req.Header {
+				header[k] = req.Header.Get(k)
+			}
+
+			r.add(Message{
+				Kind:   kind,
+				Path:   req.URL.Path,
+				Header: header,
+				Body:   string(body),
+			})
+
+			switch {
+			case strings.HasSuffix(req.URL.Path, "/fail"):
+				http.Error(w, "fake failure", http.StatusInternalServerError)
+			case strings.HasSuffix(req.URL.Path, "/gone"):
+				http.Error(w, "fake gone", http.StatusGone)
+			case kind == "push":
+				w.WriteHeader(http.StatusCreated)
+			}
+		}
+	}
+
+	mux.HandleFunc("/sms", record("sms"))
+	mux.HandleFunc("/sms/", record("sms"))
+	mux.HandleFunc("/push/", record("push"))
+	mux.HandleFunc("/webhook/", record("webhook"))
+
+	return mux
+}
+
+// ServeSMTP accepts SMTP connections on the listener and records received
+// mails. It supports plain SMTP without TLS and authentication, which is
+// enough for net/smtp client.
+func (r *Recorder) ServeSMTP(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go r.handleSMTP(conn)
+	}
+}
+
+func (r *Recorder) handleSMTP(conn net.Conn) {
+	defer conn.Close()
+
+	var (
+		rd   = bufio.NewReader(conn)
+		w    = bufio.NewWriter(conn)
+		from string
+		to   []string
+	)
+
+	reply := func(s string) bool {
+		w.WriteString(s + "\r\n")
+		return w.Flush() == nil
+	}
+
+	if !reply("220 localhost fake ESMTP") {
+		return
+	}
+
+	for {
+		line, err := rd.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+
+		var ok bool
+
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"):
+			ok = reply("250-localhost\r\n250 8BITMIME")
+		case strings.HasPrefix(cmd, "HELO"):
+			ok = reply("250 localhost")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			from = smtpPath(line[len("MAIL FROM:"):])
+			to = nil
+			ok = reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			to = append(to, smtpPath(line[len("RCPT TO:"):]))
+			ok = reply("250 OK")
+		case cmd == "DATA":
+			if !reply("354 End data with <CR><LF>.<CR><LF>") {
+				return
+			}
+			var data strings.Builder
+			for {
+				l, err := rd.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" || l == ".\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			r.add(Message{Kind: "email", From: from, To: to, Body: data.String()})
+			ok = reply("250 OK")
+		case cmd == "RSET", cmd == "NOOP":
+			ok = reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			ok = reply("502 Command not implemented")
+		}
+
+		if !ok {
+			return
+		}
+	}
+}
+
+// smtpPath returns address of MAIL FROM or RCPT TO argument without angle
+// brackets and parameters, e.g. BODY=8BITMIME.
+func smtpPath(arg string) string {
+	arg = strings.TrimSpace(arg)
+	if strings.HasPrefix(arg, "<") {
+		if i := strings.IndexByte(arg, '>'); i > 0 {
+			return arg[1:i]
+		}
+	}
+	if i := strings.IndexByte(arg, ' '); i > 0 {
+		arg = arg[:i]
+	}
+	return strings.Trim(arg, "<>")
+}
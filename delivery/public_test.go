@@ -0,0 +1,76 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestValidateURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+		private bool
+	}{
+		{url: "https://push.example.com/send/1"},
+		{url: "https://93.184.216.34:8443/hook"},
+		{url: "https://[2606:2800:220:1::]/hook"},
+		{url: "http://push.example.com/send/1", wantErr: true},
+		{url: "push.example.com/send/1", wantErr: true},
+		{url: "https:///path", wantErr: true},
+		{url: "https://localhost/hook", wantErr: true, private: true},
+		{url: "https://api.localhost./hook", wantErr: true, private: true},
+		{url: "https://127.0.0.1/hook", wantErr: true, private: true},
+		{url: "https://10.1.2.3/hook", wantErr: true, private: true},
+		{url: "https://172.20.0.1/hook", wantErr: true, private: true},
+		{url: "https://192.168.1.1/hook", wantErr: true, private: true},
+		{url: "https://169.254.169.254/latest", wantErr: true, private: true},
+		{url: "https://0.0.0.0/hook", wantErr: true, private: true},
+		{url: "https://[::1]/hook", wantErr: true, private: true},
+		{url: "https://[::ffff:127.0.0.1]/hook", wantErr: true, private: true},
+		{url: "https://[fe80::1]/hook", wantErr: true, private: true},
+		{url: "https://[fd00::1]/hook", wantErr: true, private: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := ValidateURL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateURL() error = %v, want error %v", err,
+					tt.wantErr)
+			}
+			if errors.Is(err, ErrPrivateAddress) != tt.private {
+				t.Errorf("ValidateURL() error = %v, want private %v", err,
+					tt.private)
+			}
+		})
+	}
+}
+
+func TestPublicClient(t *testing.T) {
+	var called bool
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) { called = true }))
+	defer srv.Close()
+
+	req, err := http.NewRequestWithContext(context.Background(),
+		http.MethodPost, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = doRequest(publicClient, req)
+	if !errors.Is(err, ErrPrivateAddress) {
+		t.Errorf("doRequest() error = %v, want %v", err, ErrPrivateAddress)
+	}
+	if called {
+		t.Error("loopback server is called")
+	}
+
+	if !publicIP(net.ParseIP("93.184.216.34")) {
+		t.Error("public IP is considered private")
+	}
+}
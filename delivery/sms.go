@@ -0,0 +1,87 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"eapteka/ent"
+)
+
+type SMSConfig struct {
+	// GatewayURL receives POST requests with {"to": phone, "text": text}
+	// JSON body.
+	GatewayURL string
+
+	// Token is sent as bearer authorization token if set.
+	Token string
+}
+
+// SMS sends reminders with HTTP SMS gateway.
+type SMS struct {
+	cfg SMSConfig
+}
+
+func NewSMS(cfg SMSConfig) *SMS {
+	return &SMS{cfg: cfg}
+}
+
+func (s *SMS) Send(ctx context.Context, c ent.DeliveryChannel, m Message) error {
+	text := m.Text
+	if m.ReorderURL != "" {
+		text += " " + m.ReorderURL
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"to":   c.Address,
+		"text": text,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		s.cfg.GatewayURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if s.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.cfg.Token)
+	}
+
+	return doRequest(httpClient, req)
+}
+
+// doRequest sends the request and checks response status. Client errors
+// except 408 and 429 are permanent. Response body is never included in the
+// error since it ends up in delivery last_error visible to the user.
+func doRequest(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	err = fmt.Errorf("unexpected status %d", resp.StatusCode)
+
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+		resp.StatusCode != http.StatusRequestTimeout &&
+		resp.StatusCode != http.StatusTooManyRequests {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+
+	return err
+}
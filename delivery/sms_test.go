@@ -0,0 +1,81 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"eapteka/delivery/fake"
+	"eapteka/ent"
+)
+
+func TestSMSSend(t *testing.T) {
+	var rec fake.Recorder
+	srv := httptest.NewServer(rec.Handler())
+	defer srv.Close()
+
+	m := Message{Subject: "Напоминание", Text: "Вам необходимо выпить лекарство"}
+	c := ent.DeliveryChannel{Type: ent.ChannelSMS, Address: "+79990000000"}
+
+	tests := []struct {
+		path      string
+		token     string
+		reorder   string
+		wantErr   bool
+		permanent bool
+	}{
+		{path: "/sms", token: "token"},
+		{path: "/sms"},
+		{path: "/sms", reorder: "https://eapteka.test/refills/1/reorder"},
+		{path: "/sms/fail", wantErr: true},
+		{path: "/sms/gone", wantErr: true, permanent: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			err := NewSMS(SMSConfig{
+				GatewayURL: srv.URL + tt.path,
+				Token:      tt.token,
+			}).Send(context.Background(), c, Message{Subject: m.Subject,
+				Text: m.Text, ReorderURL: tt.reorder})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send() error = %v, want error %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrPermanent) != tt.permanent {
+				t.Errorf("Send() error = %v, want permanent %v", err,
+					tt.permanent)
+			}
+
+			ms := rec.Messages()
+			if len(ms) != i+1 {
+				t.Fatalf("%d messages received, want %d", len(ms), i+1)
+			}
+			got := ms[i]
+
+			auth := ""
+			if tt.token != "" {
+				auth = "Bearer " + tt.token
+			}
+			if got.Header["Authorization"] != auth {
+				t.Errorf("Authorization = %q, want %q",
+					got.Header["Authorization"], auth)
+			}
+
+			var body map[string]string
+			err = json.Unmarshal([]byte(got.Body), &body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			text := m.Text
+			if tt.reorder != "" {
+				text += " " + tt.reorder
+			}
+			if body["to"] != c.Address || body["text"] != text {
+				t.Errorf("body = %v, want to %s and text %q", body, c.Address,
+					text)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"eapteka/ent"
+)
+
+// SignatureHeader contains hex encoded HMAC-SHA256 of the timestamp, dot and
+// request body, keyed with the channel "secret" param.
+const (
+	SignatureHeader = "X-Eapteka-Signature"
+	TimestampHeader = "X-Eapteka-Timestamp"
+)
+
+// Webhook posts reminders as JSON to the channel URL.
+type Webhook struct {
+	client *http.Client
+}
+
+func NewWebhook() *Webhook {
+	return &Webhook{client: publicClient}
+}
+
+func (w *Webhook) Send(ctx context.Context, c ent.DeliveryChannel, m Message) error {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("marshal message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Address,
+		bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: create request: %v", ErrPermanent, err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if secret := c.Params["secret"]; secret != "" {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(TimestampHeader, ts)
+		req.Header.Set(SignatureHeader, Sign(secret, ts, body))
+	}
+
+	return doRequest(w.client, req)
+}
+
+// Sign returns webhook signature of the body sent at timestamp ts.
+func Sign(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
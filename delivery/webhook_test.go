@@ -0,0 +1,67 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"eapteka/delivery/fake"
+	"eapteka/ent"
+)
+
+func TestWebhookSend(t *testing.T) {
+	var rec fake.Recorder
+	srv := httptest.NewServer(rec.Handler())
+	defer srv.Close()
+
+	r := ent.Refill{ID: 1, UserID: "u1", ProductName: "Парацетамол"}
+	m := Message{Refill: &r, ReorderURL: "https://eapteka.test/reorder",
+		Subject: "Пора заказать", Text: RefillText(r)}
+
+	tests := []struct {
+		name   string
+		params ent.ChannelParams
+		signed bool
+	}{
+		{name: "signed", params: ent.ChannelParams{"secret": "s3cret"}, signed: true},
+		{name: "unsigned"},
+	}
+
+	// The fake listens on loopback, which the public client refuses.
+	wh := NewWebhook()
+	wh.client = httpClient
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := wh.Send(context.Background(), ent.DeliveryChannel{
+				Type:    ent.ChannelWebhook,
+				Address: srv.URL + "/webhook/" + tt.name,
+				Params:  tt.params,
+			}, m)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := rec.Messages()[i]
+
+			sig, ts := got.Header[SignatureHeader], got.Header[TimestampHeader]
+			switch {
+			case !tt.signed && (sig != "" || ts != ""):
+				t.Errorf("unsigned request has signature %q at %q", sig, ts)
+			case tt.signed && sig != Sign(tt.params["secret"], ts, []byte(got.Body)):
+				t.Errorf("signature %q doesn't match body at %q", sig, ts)
+			}
+
+			var sent Message
+			err = json.Unmarshal([]byte(got.Body), &sent)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sent.Refill == nil || sent.Refill.ID != r.ID ||
+				sent.ReorderURL != m.ReorderURL || sent.Text != m.Text {
+				t.Errorf("sent message = %+v, want %+v", sent, m)
+			}
+		})
+	}
+}
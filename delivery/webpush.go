@@ -0,0 +1,230 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"eapteka/ent"
+)
+
+const (
+	webPushTTL        = 24 * time.Hour
+	webPushRecordSize = 4096
+)
+
+var b64 = base64.RawURLEncoding
+
+type WebPushConfig struct {
+	// VAPIDPrivateKey is base64url encoded P-256 private key.
+	VAPIDPrivateKey string
+
+	// Subject is "mailto:" or "https:" contact of the application server.
+	Subject string
+}
+
+// WebPush sends reminders with Web Push protocol (RFC 8030) encrypting
+// payload as per RFC 8291 and authorizing with VAPID (RFC 8292).
+type WebPush struct {
+	cfg    WebPushConfig
+	key    *ecdsa.PrivateKey
+	client *http.Client
+}
+
+func NewWebPush(cfg WebPushConfig) (*WebPush, error) {
+	d, err := b64.DecodeString(cfg.VAPIDPrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode VAPID private key: %w", err)
+	}
+
+	curve := elliptic.P256()
+
+	key := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(d)}
+	key.PublicKey.Curve = curve
+	key.PublicKey.X, key.PublicKey.Y = curve.ScalarBaseMult(d)
+
+	return &WebPush{cfg: cfg, key: key, client: publicClient}, nil
+}
+
+// PublicKey returns base64url encoded VAPID public key which clients pass
+// as applicationServerKey when subscribing.
+func (wp *WebPush) PublicKey() string {
+	return b64.EncodeToString(elliptic.Marshal(elliptic.P256(),
+		wp.key.X, wp.key.Y))
+}
+
+func (wp *WebPush) Send(ctx context.Context, c ent.DeliveryChannel, m Message) error {
+	payload, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("marshal message: %w", err)
+	}
+
+	body, err := encryptPush(payload, c.Params["p256dh"], c.Params["auth"])
+	if err != nil {
+		return fmt.Errorf("%w: encrypt: %v", ErrPermanent, err)
+	}
+
+	endpoint, err := url.Parse(c.Address)
+	if err != nil {
+		return fmt.Errorf("%w: parse endpoint: %v", ErrPermanent, err)
+	}
+
+	jwt, err := wp.vapidToken(endpoint.Scheme + "://" + endpoint.Host)
+	if err != nil {
+		return fmt.Errorf("sign VAPID token: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Address,
+		bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: create request: %v", ErrPermanent, err)
+	}
+
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", strconv.Itoa(int(webPushTTL.Seconds())))
+	req.Header.Set("Urgency", "high")
+	req.Header.Set("Authorization",
+		"vapid t="+jwt+", k="+wp.PublicKey())
+
+	return doRequest(wp.client, req)
+}
+
+// vapidToken returns ES256 signed JWT for the push service origin.
+func (wp *WebPush) vapidToken(aud string) (string, error) {
+	header := b64.EncodeToString([]byte(`{"typ":"JWT","alg":"ES256"}`))
+
+	claims, err := json.Marshal(map[string]interface{}{
+		"aud": aud,
+		"exp": time.Now().Add(12 * time.Hour).Unix(),
+		"sub": wp.cfg.Subject,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := header + "." + b64.EncodeToString(claims)
+
+	h := sha256.Sum256([]byte(unsigned))
+
+	r, s, err := ecdsa.Sign(rand.Reader, wp.key, h[:])
+	if err != nil {
+		return "", err
+	}
+
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+
+	return unsigned + "." + b64.EncodeToString(sig), nil
+}
+
+// encryptPush encrypts payload for the subscription with given p256dh and
+// auth keys using aes128gcm content encoding in a single record.
+func encryptPush(payload []byte, p256dh, auth string) ([]byte, error) {
+	uaPublic, err := b64.DecodeString(p256dh)
+	if err != nil {
+		return nil, fmt.Errorf("decode p256dh: %w", err)
+	}
+
+	authSecret, err := b64.DecodeString(auth)
+	if err != nil {
+		return nil, fmt.Errorf("decode auth: %w", err)
+	}
+
+	if len(payload)+1+16 > webPushRecordSize {
+		return nil, errors.New("payload is too big")
+	}
+
+	curve := elliptic.P256()
+
+	uaX, uaY := elliptic.Unmarshal(curve, uaPublic)
+	if uaX == nil {
+		return nil, errors.New("invalid p256dh")
+	}
+
+	asPrivate, asX, asY, err := elliptic.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	asPublic := elliptic.Marshal(curve, asX, asY)
+
+	sx, _ := curve.ScalarMult(uaX, uaY, asPrivate)
+	shared := make([]byte, 32)
+	sx.FillBytes(shared)
+
+	keyInfo := append([]byte("WebPush: info\x00"), uaPublic...)
+	keyInfo = append(keyInfo, asPublic...)
+
+	ikm := hkdf(authSecret, shared, keyInfo, 32)
+
+	salt := make([]byte, 16)
+	_, err = rand.Read(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	cek := hkdf(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce := hkdf(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12)
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// Padding delimiter of the last record
+	plaintext := append(append([]byte{}, payload...), 2)
+
+	var buf bytes.Buffer
+	buf.Write(salt)
+	binary.Write(&buf, binary.BigEndian, uint32(webPushRecordSize))
+	buf.WriteByte(byte(len(asPublic)))
+	buf.Write(asPublic)
+	buf.Write(gcm.Seal(nil, nonce, plaintext, nil))
+
+	return buf.Bytes(), nil
+}
+
+// hkdf derives key of length up to 32 bytes as per RFC 5869.
+func hkdf(salt, ikm, info []byte, length int) []byte {
+	extract := hmac.New(sha256.New, salt)
+	extract.Write(ikm)
+	prk := extract.Sum(nil)
+
+	expand := hmac.New(sha256.New, prk)
+	expand.Write(info)
+	expand.Write([]byte{1})
+
+	return expand.Sum(nil)[:length]
+}
+
+// GenerateVAPIDKey returns new base64url encoded VAPID private key.
+func GenerateVAPIDKey() (string, error) {
+	d, _, _, err := elliptic.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	return b64.EncodeToString(d), nil
+}
@@ -0,0 +1,360 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	xhkdf "golang.org/x/crypto/hkdf"
+
+	"eapteka/delivery/fake"
+	"eapteka/ent"
+)
+
+// subscription is the user agent side of a push subscription.
+type subscription struct {
+	key  []byte
+	pub  []byte
+	auth []byte
+}
+
+func newSubscription(t *testing.T) subscription {
+	t.Helper()
+
+	key, x, y, err := elliptic.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auth := make([]byte, 16)
+	_, err = rand.Read(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return subscription{
+		key:  key,
+		pub:  elliptic.Marshal(elliptic.P256(), x, y),
+		auth: auth,
+	}
+}
+
+func (s subscription) params() ent.ChannelParams {
+	return ent.ChannelParams{
+		"p256dh": b64.EncodeToString(s.pub),
+		"auth":   b64.EncodeToString(s.auth),
+	}
+}
+
+// derive reads n bytes of HKDF-SHA256 output, independently of hkdf.
+func derive(secret, salt, info []byte, n int) []byte {
+	out := make([]byte, n)
+	io.ReadFull(xhkdf.New(sha256.New, secret, salt, info), out)
+	return out
+}
+
+// decrypt decrypts aes128gcm body as the user agent does per RFC 8291.
+func (s subscription) decrypt(body []byte) ([]byte, error) {
+	if len(body) < 21 {
+		return nil, errors.New("body is shorter than header")
+	}
+
+	salt := body[:16]
+	rs := binary.BigEndian.Uint32(body[16:20])
+	idLen := int(body[20])
+	if len(body) < 21+idLen {
+		return nil, errors.New("body is shorter than key id")
+	}
+	asPub := body[21 : 21+idLen]
+	record := body[21+idLen:]
+
+	if rs != webPushRecordSize || len(record) > int(rs) {
+		return nil, fmt.Errorf("record of %d bytes, record size %d",
+			len(record), rs)
+	}
+
+	curve := elliptic.P256()
+
+	x, y := elliptic.Unmarshal(curve, asPub)
+	if x == nil {
+		return nil, errors.New("invalid application server key")
+	}
+
+	sx, _ := curve.ScalarMult(x, y, s.key)
+	shared := make([]byte, 32)
+	sx.FillBytes(shared)
+
+	info := append([]byte("WebPush: info\x00"), s.pub...)
+	info = append(info, asPub...)
+	ikm := derive(shared, s.auth, info, 32)
+
+	cek := derive(ikm, salt, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce := derive(ikm, salt, []byte("Content-Encoding: nonce\x00"), 12)
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := gcm.Open(nil, nonce, record, nil)
+	if err != nil {
+		return nil, fmt.Errorf("open record: %w", err)
+	}
+
+	// Last record ends with delimiter 2 followed by zero padding
+	plain = bytes.TrimRight(plain, "\x00")
+	if len(plain) == 0 || plain[len(plain)-1] != 2 {
+		return nil, errors.New("no last record delimiter")
+	}
+
+	return plain[:len(plain)-1], nil
+}
+
+func TestEncryptPush(t *testing.T) {
+	sub := newSubscription(t)
+
+	tests := []struct {
+		name    string
+		payload []byte
+		p256dh  string
+		auth    string
+		wantErr bool
+	}{{
+		name:    "message",
+		payload: []byte(`{"text":"Вам необходимо выпить лекарство"}`),
+	}, {
+		name:    "empty",
+		payload: []byte{},
+	}, {
+		name:    "max size",
+		payload: bytes.Repeat([]byte("a"), webPushRecordSize-17),
+	}, {
+		name:    "too big",
+		payload: bytes.Repeat([]byte("a"), webPushRecordSize-16),
+		wantErr: true,
+	}, {
+		name:    "invalid p256dh",
+		payload: []byte("a"),
+		p256dh:  b64.EncodeToString([]byte("not a point")),
+		wantErr: true,
+	}, {
+		name:    "invalid auth",
+		payload: []byte("a"),
+		auth:    "!",
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := sub.params()
+			if tt.p256dh != "" {
+				p["p256dh"] = tt.p256dh
+			}
+			if tt.auth != "" {
+				p["auth"] = tt.auth
+			}
+
+			body, err := encryptPush(tt.payload, p["p256dh"], p["auth"])
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("encryptPush() error = %v, want error %v", err,
+					tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			got, err := sub.decrypt(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.payload) {
+				t.Errorf("decrypted %q, want %q", got, tt.payload)
+			}
+		})
+	}
+}
+
+func TestEncryptPushOtherSubscription(t *testing.T) {
+	sub := newSubscription(t)
+	p := sub.params()
+
+	body, err := encryptPush([]byte("secret"), p["p256dh"], p["auth"])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Same key with other auth secret must not decrypt the payload
+	other := sub
+	other.auth = newSubscription(t).auth
+
+	_, err = other.decrypt(body)
+	if err == nil {
+		t.Error("payload decrypted with other auth secret")
+	}
+}
+
+// verifyVAPID checks Authorization header of the push request and returns
+// the token claims.
+func verifyVAPID(t *testing.T, header, publicKey string) map[string]interface{} {
+	t.Helper()
+
+	if !strings.HasPrefix(header, "vapid t=") {
+		t.Fatalf("Authorization = %q, want vapid scheme", header)
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(header, "vapid t="), ", k=", 2)
+	if len(parts) != 2 {
+		t.Fatalf("Authorization = %q, want t and k", header)
+	}
+	if parts[1] != publicKey {
+		t.Errorf("k = %q, want %q", parts[1], publicKey)
+	}
+
+	jwt := strings.Split(parts[0], ".")
+	if len(jwt) != 3 {
+		t.Fatalf("token %q isn't JWT", parts[0])
+	}
+
+	pub, err := b64.DecodeString(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, y := elliptic.Unmarshal(elliptic.P256(), pub)
+	if x == nil {
+		t.Fatal("invalid public key")
+	}
+
+	sig, err := b64.DecodeString(jwt[2])
+	if err != nil || len(sig) != 64 {
+		t.Fatalf("invalid signature %q", jwt[2])
+	}
+
+	h := sha256.Sum256([]byte(jwt[0] + "." + jwt[1]))
+	ok := ecdsa.Verify(&ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y},
+		h[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:]))
+	if !ok {
+		t.Fatal("invalid token signature")
+	}
+
+	data, err := b64.DecodeString(jwt[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var claims map[string]interface{}
+	err = json.Unmarshal(data, &claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return claims
+}
+
+func TestWebPushSend(t *testing.T) {
+	var rec fake.Recorder
+	srv := httptest.NewServer(rec.Handler())
+	defer srv.Close()
+
+	vapid, err := GenerateVAPIDKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wp, err := NewWebPush(WebPushConfig{
+		VAPIDPrivateKey: vapid,
+		Subject:         "mailto:admin@eapteka.test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wp.client = httpClient
+
+	sub := newSubscription(t)
+	n := ent.Notifier{ID: 1, ProductName: "Парацетамол", DoseAmount: 1,
+		DoseUnit: "таблетка"}
+	m := Message{Notifier: &n, Subject: "Напоминание", Text: Text(n)}
+
+	tests := []struct {
+		path      string
+		wantErr   bool
+		permanent bool
+	}{
+		{path: "/push/1"},
+		{path: "/push/fail", wantErr: true},
+		{path: "/push/gone", wantErr: true, permanent: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			err := wp.Send(context.Background(), ent.DeliveryChannel{
+				Type:    ent.ChannelWebPush,
+				Address: srv.URL + tt.path,
+				Params:  sub.params(),
+			}, m)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send() error = %v, want error %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrPermanent) != tt.permanent {
+				t.Errorf("Send() error = %v, want permanent %v", err,
+					tt.permanent)
+			}
+			if err != nil && strings.Contains(err.Error(), "fake") {
+				t.Errorf("Send() error = %v contains response body", err)
+			}
+		})
+	}
+
+	ms := rec.Messages()
+	if len(ms) != len(tests) {
+		t.Fatalf("%d messages received, want %d", len(ms), len(tests))
+	}
+
+	got := ms[0]
+	if got.Header["Content-Encoding"] != "aes128gcm" {
+		t.Errorf("Content-Encoding = %q, want aes128gcm",
+			got.Header["Content-Encoding"])
+	}
+	if got.Header["Ttl"] == "" {
+		t.Error("TTL isn't set")
+	}
+
+	claims := verifyVAPID(t, got.Header["Authorization"], wp.PublicKey())
+	if claims["aud"] != srv.URL {
+		t.Errorf("aud = %v, want %s", claims["aud"], srv.URL)
+	}
+	if claims["sub"] != "mailto:admin@eapteka.test" {
+		t.Errorf("sub = %v, want mailto:admin@eapteka.test", claims["sub"])
+	}
+
+	payload, err := sub.decrypt([]byte(got.Body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sent Message
+	err = json.Unmarshal(payload, &sent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent.Text != m.Text || sent.Notifier == nil || sent.Notifier.ID != 1 {
+		t.Errorf("decrypted message = %+v, want %+v", sent, m)
+	}
+}
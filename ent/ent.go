@@ -1,6 +1,9 @@
 package ent
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/lib/pq"
@@ -8,6 +11,7 @@ import (
 
 type Purchase struct {
 	ID        int64     `json:"id" db:"id"`
+	UserID    string    `json:"user_id" db:"user_id"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 
 	Products []Product `json:"products,omitempty" db:"-"`
@@ -29,11 +33,21 @@ type Product struct {
 	ImageID     int32  `json:"image_id" db:"image_id"`
 	SKU         int32  `json:"sku" db:"sku"`
 
+	// PackSize is the number of dose units in one pack, e.g. tablets.
+	PackSize int32 `json:"pack_size" db:"pack_size"`
+
 	SubstanceName *string `json:"substance_name" db:"substance_name"`
 	Count         int32   `json:"count,omitempty" db:"count"`
 	PurchasePrice int32   `json:"purchase_price" db:"purchase_price"`
 }
 
+// SearchResult is the products and substances with names similar to the
+// search keyword.
+type SearchResult struct {
+	Products   []Product   `json:"products"`
+	Substances []Substance `json:"substances"`
+}
+
 type Substance struct {
 	ID   int64  `json:"id" db:"id"`
 	Name string `json:"name" db:"name"`
@@ -43,10 +57,53 @@ type Substance struct {
 
 type Notifier struct {
 	ID        int64          `json:"id" db:"id"`
+	UserID    string         `json:"user_id" db:"user_id"`
 	ProductID int64          `json:"product_id" db:"product_id"`
 	Schedule  pq.StringArray `json:"schedule" db:"schedule"`
+	Rule      *ScheduleRule  `json:"rule,omitempty" db:"rule"`
+
+	DoseAmount float64 `json:"dose_amount" db:"dose_amount"`
+	DoseUnit   string  `json:"dose_unit" db:"dose_unit"`
+	Notes      string  `json:"notes" db:"notes"`
+	Paused     bool    `json:"paused" db:"paused"`
 
 	ProductName string `json:"product_name" db:"product_name"`
+
+	// RRule is iCalendar recurrence rule to import schedule from.
+	RRule string `json:"rrule,omitempty" db:"-"`
+}
+
+// ScheduleRule limits and extends notifier daily schedule.
+type ScheduleRule struct {
+	// Weekdays the notifier fires on, every day if empty.
+	Weekdays []time.Weekday `json:"weekdays,omitempty"`
+
+	// EveryHours makes the notifier fire every N hours starting from
+	// StartAt instead of daily schedule times.
+	EveryHours int `json:"every_hours,omitempty"`
+
+	StartAt *time.Time `json:"start_at,omitempty"`
+	EndAt   *time.Time `json:"end_at,omitempty"`
+
+	// CourseDays is the course length in days starting from StartAt.
+	CourseDays int `json:"course_days,omitempty"`
+
+	// PillCount is the total number of reminders to fire.
+	PillCount int `json:"pill_count,omitempty"`
+}
+
+func (r ScheduleRule) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+func (r *ScheduleRule) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return json.Unmarshal(src, r)
+	case string:
+		return json.Unmarshal([]byte(src), r)
+	}
+	return errors.New("unsupported schedule rule type")
 }
 
 type Expert struct {
@@ -56,3 +113,233 @@ type Expert struct {
 	Title       string `json:"title" db:"title"`
 	Text        string `json:"text" db:"text"`
 }
+
+type Reminder struct {
+	ID           int64      `json:"id" db:"id"`
+	NotifierID   int64      `json:"notifier_id" db:"notifier_id"`
+	ScheduledAt  time.Time  `json:"scheduled_at" db:"scheduled_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	SnoozedUntil *time.Time `json:"snoozed_until,omitempty" db:"snoozed_until"`
+}
+
+const (
+	ReminderTaken   = "taken"
+	ReminderSnoozed = "snoozed"
+	ReminderSkipped = "skipped"
+)
+
+type ReminderEvent struct {
+	ID          int64      `json:"id" db:"id"`
+	ReminderID  int64      `json:"reminder_id" db:"reminder_id"`
+	Type        string     `json:"type" db:"type"`
+	SnoozeUntil *time.Time `json:"snooze_until,omitempty" db:"snooze_until"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+
+	// SnoozeMinutes sets SnoozeUntil relative to the event time.
+	SnoozeMinutes int `json:"snooze_minutes,omitempty" db:"-"`
+}
+
+// Adherence is the count of notifier reminders by outcome in a period.
+type Adherence struct {
+	Period  time.Time `json:"period" db:"period"`
+	Total   int       `json:"total" db:"total"`
+	Taken   int       `json:"taken" db:"taken"`
+	Skipped int       `json:"skipped" db:"skipped"`
+	Missed  int       `json:"missed" db:"missed"`
+}
+
+const (
+	ChannelEmail   = "email"
+	ChannelSMS     = "sms"
+	ChannelWebPush = "webpush"
+	ChannelWebhook = "webhook"
+)
+
+// DeliveryChannel is the user preference to receive reminders by email, SMS,
+// web push or webhook.
+type DeliveryChannel struct {
+	ID     int64  `json:"id" db:"id"`
+	UserID string `json:"user_id" db:"user_id"`
+	Type   string `json:"type" db:"type"`
+
+	// Address is email, phone number, push subscription endpoint or webhook
+	// URL depending on channel type.
+	Address string `json:"address" db:"address"`
+
+	// Params are channel specific: "p256dh" and "auth" keys of web push
+	// subscription, "secret" of webhook signature.
+	Params    ChannelParams `json:"params,omitempty" db:"params"`
+	Enabled   bool          `json:"enabled" db:"enabled"`
+	CreatedAt time.Time     `json:"created_at" db:"created_at"`
+}
+
+type ChannelParams map[string]string
+
+func (p ChannelParams) Value() (driver.Value, error) {
+	if p == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(p)
+}
+
+func (p *ChannelParams) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return json.Unmarshal(src, p)
+	case string:
+		return json.Unmarshal([]byte(src), p)
+	}
+	return errors.New("unsupported channel params type")
+}
+
+const (
+	DeliveryPending = "pending"
+	DeliverySent    = "sent"
+	DeliveryFailed  = "failed"
+)
+
+// Delivery is the reminder sending attempts state for one channel.
+type Delivery struct {
+	ID            int64     `json:"id" db:"id"`
+	ReminderID    *int64    `json:"reminder_id,omitempty" db:"reminder_id"`
+	RefillID      *int64    `json:"refill_id,omitempty" db:"refill_id"`
+	ChannelID     int64     `json:"channel_id" db:"channel_id"`
+	Status        string    `json:"status" db:"status"`
+	Attempts      int       `json:"attempts" db:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at" db:"next_attempt_at"`
+	LastError     string    `json:"last_error" db:"last_error"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// Refill is the notification that the user is running out of the product.
+// It is created once per last purchase of the product.
+type Refill struct {
+	ID         int64  `json:"id" db:"id"`
+	UserID     string `json:"user_id" db:"user_id"`
+	ProductID  int64  `json:"product_id" db:"product_id"`
+	PurchaseID int64  `json:"purchase_id" db:"purchase_id"`
+
+	RunOutAt  time.Time `json:"run_out_at" db:"run_out_at"`
+	Remaining float64   `json:"remaining" db:"remaining"`
+
+	// ReorderPurchaseID is the purchase made by one-click reorder.
+	ReorderPurchaseID *int64    `json:"reorder_purchase_id,omitempty" db:"reorder_purchase_id"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+
+	ProductName string `json:"product_name" db:"product_name"`
+}
+
+// RefillPrediction is the estimated supply of the product the user takes
+// by notifiers.
+type RefillPrediction struct {
+	UserID      string `json:"user_id"`
+	ProductID   int64  `json:"product_id"`
+	ProductName string `json:"product_name"`
+
+	// PurchaseID is the last purchase of the product.
+	PurchaseID int64 `json:"purchase_id"`
+
+	// Remaining is the amount of dose units left now.
+	Remaining float64 `json:"remaining"`
+	DoseUnit  string  `json:"dose_unit"`
+
+	// RunOutAt is the time of the first dose which is not covered by the
+	// remaining supply, nil if supply outlasts the schedule.
+	RunOutAt *time.Time `json:"run_out_at,omitempty"`
+}
+
+// NotifierProtocolVersion is the version of /ws/notifier messages.
+const NotifierProtocolVersion = 1
+
+// Types of /ws/notifier messages.
+const (
+	// Sent by server after connect with NotifierHello data.
+	NotifierMsgHello = "hello"
+
+	// Sent by client with NotifierSubscription data to receive reminders of
+	// the user or notifiers only, answered with "subscribed".
+	NotifierMsgSubscribe  = "subscribe"
+	NotifierMsgSubscribed = "subscribed"
+
+	// Sent by server with NotifierReminder data when reminder fires.
+	NotifierMsgReminder = "reminder"
+
+	// Sent by client with ReminderEvent data, answered with saved event.
+	NotifierMsgEvent = "event"
+
+	// Application level heartbeat for clients which can't send websocket
+	// ping frames.
+	NotifierMsgPing = "ping"
+	NotifierMsgPong = "pong"
+
+	// Sent by server with Refill data when user is running out of the
+	// product.
+	NotifierMsgRefill = "refill"
+
+	// Sent by server with NotifierError data when client message fails.
+	NotifierMsgError = "error"
+)
+
+// NotifierMessage is the envelope of /ws/notifier messages in both
+// directions.
+type NotifierMessage struct {
+	Version int    `json:"v"`
+	Type    string `json:"type"`
+
+	// ID is set by client in requests and copied to responses.
+	ID string `json:"id,omitempty"`
+
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+type NotifierHello struct {
+	// HeartbeatSeconds is the interval of server pings, connection is closed
+	// if client sends nothing for two intervals.
+	HeartbeatSeconds int `json:"heartbeat_seconds"`
+}
+
+// NotifierSubscription selects reminders of the user or notifiers. Client
+// without subscription receives no reminders.
+type NotifierSubscription struct {
+	UserID      string  `json:"user_id,omitempty"`
+	NotifierIDs []int64 `json:"notifier_ids,omitempty"`
+}
+
+type NotifierReminder struct {
+	ReminderID   int64      `json:"reminder_id"`
+	NotifierID   int64      `json:"notifier_id"`
+	ProductID    int64      `json:"product_id"`
+	ProductName  string     `json:"product_name"`
+	ScheduledAt  time.Time  `json:"scheduled_at"`
+	SnoozedUntil *time.Time `json:"snoozed_until,omitempty"`
+	DoseAmount   float64    `json:"dose_amount,omitempty"`
+	DoseUnit     string     `json:"dose_unit,omitempty"`
+	Notes        string     `json:"notes,omitempty"`
+}
+
+type NotifierError struct {
+	Message string `json:"message"`
+}
+
+// Recommendation is the product suggested to the user with the strategy
+// reason and score from 0 to 1.
+type Recommendation struct {
+	ProductID int64    `json:"product_id"`
+	Score     float64  `json:"score"`
+	Reason    string   `json:"reason"`
+	Product   *Product `json:"product,omitempty"`
+}
+
+// Error is the body of API error responses.
+type Error struct {
+	Code      string       `json:"code"`
+	Message   string       `json:"message"`
+	Fields    []FieldError `json:"fields,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+// FieldError is the validation error of the request field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,93 @@
+package filesystem
+
+import (
+	"html/template"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// dirEntry is a directory listing item.
+type dirEntry struct {
+	Name    string    `json:"name"`
+	Path    string    `json:"path"`
+	IsDir   bool      `json:"is_dir"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+var dirListTemplate = template.Must(template.New("dir").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Path}}</title>
+</head>
+<body>
+<h1>{{.Path}}</h1>
+<ul>
+{{- if ne .Path "/"}}
+<li><a href="{{.Parent}}">..</a></li>
+{{- end}}
+{{- range .Entries}}
+<li><a href="{{.Path}}">{{.Name}}{{if .IsDir}}/{{end}}</a>{{if not .IsDir}} {{.Size}}{{end}}</li>
+{{- end}}
+</ul>
+</body>
+</html>
+`))
+
+// browse responds with the listing of the dir. Listing is sent as JSON if
+// client prefers it, otherwise as HTML. urlPath is the requested path
+// including route prefix and is used to build entry links.
+func browse(c *fiber.Ctx, dir http.File, urlPath string) error {
+	defer dir.Close()
+
+	fis, err := dir.Readdir(-1)
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(fis, func(i, j int) bool {
+		if fis[i].IsDir() != fis[j].IsDir() {
+			return fis[i].IsDir()
+		}
+		return fis[i].Name() < fis[j].Name()
+	})
+
+	if !strings.HasSuffix(urlPath, "/") {
+		urlPath += "/"
+	}
+
+	es := make([]dirEntry, 0, len(fis))
+
+	for _, fi := range fis {
+		es = append(es, dirEntry{
+			Name:    fi.Name(),
+			Path:    path.Join(urlPath, fi.Name()),
+			IsDir:   fi.IsDir(),
+			Size:    fi.Size(),
+			ModTime: fi.ModTime(),
+		})
+	}
+
+	if c.Accepts(fiber.MIMETextHTML, fiber.MIMEApplicationJSON) ==
+		fiber.MIMEApplicationJSON {
+		return c.JSON(es)
+	}
+
+	c.Type("html", "utf-8")
+
+	return dirListTemplate.Execute(c, struct {
+		Path    string
+		Parent  string
+		Entries []dirEntry
+	}{
+		Path:    urlPath,
+		Parent:  path.Dir(strings.TrimSuffix(urlPath, "/")),
+		Entries: es,
+	})
+}
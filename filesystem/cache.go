@@ -0,0 +1,142 @@
+package filesystem
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+var hashedNameRe = regexp.MustCompile(`[.-][0-9a-fA-F]{8,}\.[^./]+$`)
+
+// HashedName reports whether the file name contains a content hash, like
+// "app.3f2a9c1b.js" or "chunk-vendors-1a2b3c4d5e.css", as produced by SPA
+// bundlers. Such files never change under the same name.
+func HashedName(path string) bool {
+	return hashedNameRe.MatchString(path)
+}
+
+// fileCache keeps per-file metadata that is expensive to compute. Entries
+// are keyed by path and dropped when file size or modification time change.
+type fileCache struct {
+	mx      sync.RWMutex
+	entries map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	size    int64
+	modTime time.Time
+	etag    string
+
+	// encoded holds compressed content by encoding, nil value means
+	// compression is not worth it.
+	encoded map[string][]byte
+}
+
+func newFileCache() *fileCache {
+	return &fileCache{entries: map[string]*cacheEntry{}}
+}
+
+func (fc *fileCache) entry(path string, stat os.FileInfo) *cacheEntry {
+	fc.mx.RLock()
+	e, ok := fc.entries[path]
+	fc.mx.RUnlock()
+
+	if ok && e.size == stat.Size() && e.modTime.Equal(stat.ModTime()) {
+		return e
+	}
+
+	e = &cacheEntry{
+		size:    stat.Size(),
+		modTime: stat.ModTime(),
+	}
+
+	fc.mx.Lock()
+	fc.entries[path] = e
+	fc.mx.Unlock()
+
+	return e
+}
+
+// etag returns strong ETag of the file content. Content is hashed only once
+// per cache entry, file is rewound after hashing.
+func (fc *fileCache) etag(path string, file http.File, stat os.FileInfo) (string, error) {
+	e := fc.entry(path, stat)
+
+	fc.mx.RLock()
+	etag := e.etag
+	fc.mx.RUnlock()
+
+	if etag != "" {
+		return etag, nil
+	}
+
+	h := sha256.New()
+
+	_, err := io.Copy(h, file)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return "", err
+	}
+
+	etag = `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+
+	fc.mx.Lock()
+	e.etag = etag
+	fc.mx.Unlock()
+
+	return etag, nil
+}
+
+// notModified checks request conditional headers. If-None-Match takes
+// precedence over If-Modified-Since as required by RFC 7232.
+func notModified(c *fiber.Ctx, etag string, modTime time.Time) bool {
+	if inm := c.Get(fiber.HeaderIfNoneMatch); inm != "" {
+		return etag != "" && etagMatch(inm, etag)
+	}
+
+	ims := c.Get(fiber.HeaderIfModifiedSince)
+	if ims == "" || modTime.IsZero() {
+		return false
+	}
+
+	t, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+
+	return !modTime.Truncate(time.Second).After(t)
+}
+
+// etagMatch does weak comparison of etag with every entity tag listed in
+// the If-None-Match header value.
+func etagMatch(header, etag string) bool {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return true
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimPrefix(strings.TrimSpace(t), "W/")
+		if t == etag {
+			return true
+		}
+	}
+
+	return false
+}
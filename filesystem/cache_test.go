@@ -0,0 +1,236 @@
+package filesystem
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestHashedName(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/app.3f2a9c1b.js", true},
+		{"/js/chunk-vendors-1a2b3c4d5e.css", true},
+		{"/app.js", false},
+		{"/app.3f2a9c.js", false},
+		{"/app.3f2a9c1z.js", false},
+		{"/3f2a9c1b.js/index", false},
+	}
+
+	for _, tt := range tests {
+		if got := HashedName(tt.path); got != tt.want {
+			t.Errorf("HashedName(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestETagMatch(t *testing.T) {
+	tests := []struct {
+		header string
+		etag   string
+		want   bool
+	}{
+		{`"a"`, `"a"`, true},
+		{`"b"`, `"a"`, false},
+		{`"b", "a"`, `"a"`, true},
+		{`"b","a"`, `"a"`, true},
+		{`W/"a"`, `"a"`, true},
+		{`"a"`, `W/"a"`, true},
+		{`*`, `"a"`, true},
+		{` * `, `"a"`, true},
+		{`a`, `"a"`, false},
+	}
+
+	for _, tt := range tests {
+		if got := etagMatch(tt.header, tt.etag); got != tt.want {
+			t.Errorf("etagMatch(%q, %q) = %v, want %v", tt.header, tt.etag,
+				got, tt.want)
+		}
+	}
+}
+
+func TestConditional(t *testing.T) {
+	modTime := time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC)
+	embedTime := time.Date(2021, 7, 1, 8, 0, 0, 0, time.UTC)
+
+	root := fstest.MapFS{
+		"app.js":   {Data: []byte("console.log(1)"), ModTime: modTime},
+		"embed.js": {Data: []byte("console.log(2)")},
+	}
+
+	app := fiber.New()
+	app.Use(New(Config{
+		Root:    http.FS(root),
+		ETag:    true,
+		ModTime: embedTime,
+	}))
+
+	_, _, h := do(t, app, httptest.NewRequest("GET", "/app.js", nil))
+	etag := h.Get(fiber.HeaderETag)
+	if len(etag) != 34 || etag[0] != '"' || etag[33] != '"' {
+		t.Fatalf("ETag = %q, want strong tag of 32 hex digits", etag)
+	}
+
+	before := modTime.Add(-time.Second).Format(http.TimeFormat)
+	after := modTime.Add(time.Second).Format(http.TimeFormat)
+
+	tests := []struct {
+		name    string
+		path    string
+		headers map[string]string
+		status  int
+	}{
+		{"no conditions", "/app.js", nil, 200},
+		{"etag match", "/app.js",
+			map[string]string{"If-None-Match": etag}, 304},
+		{"weak etag match", "/app.js",
+			map[string]string{"If-None-Match": "W/" + etag}, 304},
+		{"etag in list", "/app.js",
+			map[string]string{"If-None-Match": `"other", ` + etag}, 304},
+		{"any etag", "/app.js",
+			map[string]string{"If-None-Match": "*"}, 304},
+		{"etag mismatch", "/app.js",
+			map[string]string{"If-None-Match": `"other"`}, 200},
+		{"not modified since", "/app.js",
+			map[string]string{"If-Modified-Since": after}, 304},
+		{"same second", "/app.js",
+			map[string]string{"If-Modified-Since": modTime.Format(http.TimeFormat)}, 304},
+		{"modified since", "/app.js",
+			map[string]string{"If-Modified-Since": before}, 200},
+		{"invalid date", "/app.js",
+			map[string]string{"If-Modified-Since": "yesterday"}, 200},
+		{"etag takes precedence", "/app.js", map[string]string{
+			"If-None-Match":     `"other"`,
+			"If-Modified-Since": after,
+		}, 200},
+		{"zero mod time replaced", "/embed.js", map[string]string{
+			"If-Modified-Since": embedTime.Format(http.TimeFormat),
+		}, 304},
+		{"zero mod time modified", "/embed.js", map[string]string{
+			"If-Modified-Since": after,
+		}, 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			status, body, h := do(t, app, req)
+			if status != tt.status {
+				t.Errorf("status = %d, want %d", status, tt.status)
+			}
+			if status == 304 && body != "" {
+				t.Errorf("304 with body %q", body)
+			}
+			if h.Get(fiber.HeaderETag) == "" {
+				t.Error("ETag isn't set")
+			}
+			if h.Get(fiber.HeaderLastModified) == "" {
+				t.Error("Last-Modified isn't set")
+			}
+		})
+	}
+}
+
+func TestETagChange(t *testing.T) {
+	modTime := time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC)
+	root := fstest.MapFS{
+		"app.js": {Data: []byte("console.log(1)"), ModTime: modTime},
+	}
+
+	app := fiber.New()
+	app.Use(New(Config{Root: http.FS(root), ETag: true}))
+
+	etag := func() string {
+		_, _, h := do(t, app, httptest.NewRequest("GET", "/app.js", nil))
+		return h.Get(fiber.HeaderETag)
+	}
+
+	first := etag()
+	if etag() != first {
+		t.Error("ETag changed without file change")
+	}
+
+	root["app.js"] = &fstest.MapFile{Data: []byte("console.log(2)"),
+		ModTime: modTime.Add(time.Minute)}
+
+	if etag() == first {
+		t.Error("ETag isn't changed with file content")
+	}
+}
+
+func TestCacheControl(t *testing.T) {
+	root := fstest.MapFS{
+		"app.js":                   {Data: []byte("a")},
+		"app.3f2a9c1b.js":          {Data: []byte("a")},
+		"index.html":               {Data: []byte("<html>")},
+		"404.html":                 {Data: []byte("not found")},
+		"v.3f2a9c1b.x/index.html":  {Data: []byte("<html>")},
+		"assets/logo.3f2a9c1b.svg": {Data: []byte("<svg>")},
+	}
+
+	// served records paths passed to Immutable
+	var served []string
+	record := func(path string) bool {
+		served = append(served, path)
+		return false
+	}
+
+	tests := []struct {
+		name string
+		cfg  Config
+		path string
+		want string
+	}{
+		{"none", Config{}, "/app.js", ""},
+		{"max age", Config{MaxAge: 60}, "/app.js", "public, max-age=60"},
+		{"hashed", Config{MaxAge: 60}, "/app.3f2a9c1b.js", immutableCacheControl},
+		{"custom immutable", Config{Immutable: func(string) bool { return true }},
+			"/app.js", immutableCacheControl},
+		{"hashed in directory", Config{}, "/assets/logo.3f2a9c1b.svg",
+			immutableCacheControl},
+		{"directory index", Config{MaxAge: 60}, "/v.3f2a9c1b.x/",
+			"public, max-age=60"},
+		{"not found file", Config{MaxAge: 60, NotFoundFile: "/404.html"},
+			"/app.0badc0de.js", "public, max-age=60"},
+		{"spa index", Config{SPAIndex: "/index.html",
+			Immutable: func(string) bool { return true }},
+			"/orders/1", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Root = http.FS(root)
+
+			app := fiber.New()
+			app.Use(New(tt.cfg))
+
+			_, _, h := do(t, app, httptest.NewRequest("GET", tt.path, nil))
+			if got := h.Get(fiber.HeaderCacheControl); got != tt.want {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	app := fiber.New()
+	app.Use(New(Config{Root: http.FS(root), Immutable: record}))
+
+	for _, path := range []string{"/", "/v.3f2a9c1b.x", "/app.js"} {
+		do(t, app, httptest.NewRequest("GET", path, nil))
+	}
+
+	want := []string{"/index.html", "/v.3f2a9c1b.x/index.html", "/app.js"}
+	if !reflect.DeepEqual(served, want) {
+		t.Errorf("Immutable called with %v, want %v", served, want)
+	}
+}
@@ -0,0 +1,247 @@
+package filesystem
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/gzip"
+)
+
+const (
+	encodingBrotli = "br"
+	encodingGzip   = "gzip"
+
+	// Files smaller than compressMinSize are not worth compressing, files
+	// bigger than compressMaxSize are not compressed on the fly to keep
+	// memory cache small.
+	compressMinSize = 1 << 10
+	compressMaxSize = 8 << 20
+
+	brotliLevel = 9
+)
+
+// supportedEncodings in order of server preference.
+var supportedEncodings = []string{encodingBrotli, encodingGzip}
+
+var siblingExtensions = map[string]string{
+	encodingBrotli: ".br",
+	encodingGzip:   ".gz",
+}
+
+var compressibleTypes = []string{
+	"text/",
+	"application/javascript",
+	"application/json",
+	"application/manifest+json",
+	"application/wasm",
+	"application/xml",
+	"image/svg+xml",
+}
+
+func compressible(contentType string) bool {
+	for _, t := range compressibleTypes {
+		if strings.HasPrefix(contentType, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptedEncodings returns supported encodings accepted by the client in
+// order of server preference. Encodings with zero quality are refused, "*"
+// accepts every encoding not listed explicitly.
+func acceptedEncodings(header string) []string {
+	if header == "" {
+		return nil
+	}
+
+	qs := map[string]float64{}
+
+	for _, part := range strings.Split(header, ",") {
+		ps := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(ps[0]))
+		q := 1.0
+		for _, p := range ps[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				v, err := strconv.ParseFloat(p[2:], 64)
+				if err == nil {
+					q = v
+				}
+			}
+		}
+		qs[name] = q
+	}
+
+	var encs []string
+
+	for _, enc := range supportedEncodings {
+		q, ok := qs[enc]
+		if !ok {
+			q, ok = qs["*"]
+		}
+		if ok && q > 0 {
+			encs = append(encs, enc)
+		}
+	}
+
+	return encs
+}
+
+// encodedFile is a compressed representation of the file.
+type encodedFile struct {
+	encoding string
+	body     io.ReadSeeker
+	size     int64
+	etag     string
+}
+
+// encode returns the best compressed representation of the file for the
+// given encodings. Precompressed siblings with .br or .gz extension are
+// preferred, otherwise compressible content is compressed in memory and
+// cached. It returns nil if the file should be served as is.
+func (fc *fileCache) encode(root http.FileSystem, name string, file http.File,
+	stat os.FileInfo, contentType, etag string, encs []string, withETag bool,
+) (*encodedFile, error) {
+	for _, enc := range encs {
+		sName := name + siblingExtensions[enc]
+
+		sFile, err := root.Open(sName)
+		if err != nil {
+			continue
+		}
+
+		sStat, err := sFile.Stat()
+		if err != nil || sStat.IsDir() {
+			sFile.Close()
+			continue
+		}
+
+		var sETag string
+		if withETag {
+			sETag, err = fc.etag(sName, sFile, sStat)
+			if err != nil {
+				sFile.Close()
+				return nil, err
+			}
+		}
+
+		return &encodedFile{
+			encoding: enc,
+			body:     sFile,
+			size:     sStat.Size(),
+			etag:     sETag,
+		}, nil
+	}
+
+	if !compressible(contentType) || stat.Size() < compressMinSize ||
+		stat.Size() > compressMaxSize {
+		return nil, nil
+	}
+
+	for _, enc := range encs {
+		b, err := fc.compressed(name, enc, file, stat)
+		if err != nil {
+			return nil, err
+		}
+		if b == nil {
+			continue
+		}
+
+		var eETag string
+		if etag != "" {
+			eETag = strings.TrimSuffix(etag, `"`) + "-" + enc + `"`
+		}
+
+		return &encodedFile{
+			encoding: enc,
+			body:     bytes.NewReader(b),
+			size:     int64(len(b)),
+			etag:     eETag,
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// compressed returns cached file content compressed with encoding, or nil if
+// compression doesn't reduce the size. File is rewound after reading.
+func (fc *fileCache) compressed(name, encoding string, file http.File,
+	stat os.FileInfo) ([]byte, error) {
+
+	e := fc.entry(name, stat)
+
+	fc.mx.RLock()
+	b, ok := e.encoded[encoding]
+	fc.mx.RUnlock()
+
+	if ok {
+		return b, nil
+	}
+
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err = compressBytes(encoding, content)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) >= len(content) {
+		b = nil
+	}
+
+	fc.mx.Lock()
+	if e.encoded == nil {
+		e.encoded = map[string][]byte{}
+	}
+	e.encoded[encoding] = b
+	fc.mx.Unlock()
+
+	return b, nil
+}
+
+func compressBytes(encoding string, b []byte) ([]byte, error) {
+	var (
+		buf bytes.Buffer
+		w   io.WriteCloser
+		err error
+	)
+
+	switch encoding {
+	case encodingBrotli:
+		w = brotli.NewWriterLevel(&buf, brotliLevel)
+	case encodingGzip:
+		w, err = gzip.NewWriterLevel(&buf, gzip.BestCompression)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, nil
+	}
+
+	_, err = w.Write(b)
+	if err != nil {
+		return nil, err
+	}
+
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
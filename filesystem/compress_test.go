@@ -0,0 +1,207 @@
+package filesystem
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gofiber/fiber/v2"
+	"github.com/klauspost/compress/gzip"
+)
+
+func TestAcceptedEncodings(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{"", nil},
+		{"gzip", []string{"gzip"}},
+		{"gzip, deflate, br", []string{"br", "gzip"}},
+		{"GZIP, BR", []string{"br", "gzip"}},
+		{"br;q=0, gzip;q=0.5", []string{"gzip"}},
+		{"br; q=0", nil},
+		{"*", []string{"br", "gzip"}},
+		{"*;q=0, gzip", []string{"gzip"}},
+		{"br;q=0, *", []string{"gzip"}},
+		{"identity, deflate", nil},
+		{"gzip;q=invalid", []string{"gzip"}},
+	}
+
+	for _, tt := range tests {
+		got := acceptedEncodings(tt.header)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("acceptedEncodings(%q) = %v, want %v", tt.header, got,
+				tt.want)
+		}
+	}
+}
+
+// decode decompresses body of the encoding.
+func decode(t *testing.T, encoding, body string) string {
+	t.Helper()
+
+	var r io.Reader = strings.NewReader(body)
+
+	switch encoding {
+	case "":
+		return body
+	case encodingBrotli:
+		r = brotli.NewReader(r)
+	case encodingGzip:
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = gr
+	default:
+		t.Fatalf("unexpected encoding %q", encoding)
+	}
+
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestCompress(t *testing.T) {
+	js := strings.Repeat("console.log(1);\n", 256)
+	css := strings.Repeat("body{}\n", 256)
+
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte(css))
+	w.Close()
+
+	root := fstest.MapFS{
+		"app.js":      {Data: []byte(js)},
+		"small.js":    {Data: []byte("console.log(1)")},
+		"image.png":   {Data: bytes.Repeat([]byte{0}, 4096)},
+		"app.css":     {Data: []byte(css)},
+		"app.css.gz":  {Data: gz.Bytes()},
+		"random.json": {Data: random(t, 4096)},
+	}
+
+	app := fiber.New()
+	app.Use(New(Config{Root: http.FS(root), Compress: true, ETag: true}))
+
+	_, _, h := do(t, app, httptest.NewRequest("GET", "/app.js", nil))
+	jsETag := h.Get(fiber.HeaderETag)
+
+	tests := []struct {
+		name     string
+		path     string
+		accept   string
+		encoding string
+		etag     string
+		body     string
+	}{
+		{name: "identity", path: "/app.js", body: js, etag: jsETag},
+		{name: "brotli preferred", path: "/app.js", accept: "gzip, br",
+			encoding: "br", body: js,
+			etag: strings.TrimSuffix(jsETag, `"`) + `-br"`},
+		{name: "gzip", path: "/app.js", accept: "gzip", encoding: "gzip",
+			body: js, etag: strings.TrimSuffix(jsETag, `"`) + `-gzip"`},
+		{name: "refused", path: "/app.js", accept: "br;q=0, gzip;q=0",
+			body: js, etag: jsETag},
+		{name: "small", path: "/small.js", accept: "br", body: "console.log(1)"},
+		{name: "not compressible", path: "/image.png", accept: "br",
+			body: string(root["image.png"].Data)},
+		{name: "incompressible", path: "/random.json", accept: "gzip",
+			body: string(root["random.json"].Data)},
+		{name: "precompressed", path: "/app.css", accept: "gzip",
+			encoding: "gzip", body: css},
+		{name: "sibling of other encoding", path: "/app.css", accept: "br",
+			encoding: "br", body: css},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set(fiber.HeaderAcceptEncoding, tt.accept)
+			}
+
+			// Raw body is read, app.Test doesn't decompress it
+			status, body, h := do(t, app, req)
+			if status != 200 {
+				t.Fatalf("status = %d, want 200", status)
+			}
+
+			enc := h.Get(fiber.HeaderContentEncoding)
+			if enc != tt.encoding {
+				t.Errorf("Content-Encoding = %q, want %q", enc, tt.encoding)
+			}
+			if !strings.Contains(h.Get(fiber.HeaderVary), fiber.HeaderAcceptEncoding) {
+				t.Errorf("Vary = %q, want Accept-Encoding", h.Get(fiber.HeaderVary))
+			}
+			if tt.etag != "" && h.Get(fiber.HeaderETag) != tt.etag {
+				t.Errorf("ETag = %q, want %q", h.Get(fiber.HeaderETag), tt.etag)
+			}
+			if got := decode(t, enc, body); got != tt.body {
+				t.Errorf("decoded body of %d bytes, want %d", len(got),
+					len(tt.body))
+			}
+		})
+	}
+}
+
+func TestCompressPrecompressedETag(t *testing.T) {
+	css := strings.Repeat("body{}\n", 256)
+
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte(css))
+	w.Close()
+
+	root := fstest.MapFS{
+		"app.css":    {Data: []byte(css)},
+		"app.css.gz": {Data: gz.Bytes()},
+	}
+
+	app := fiber.New()
+	app.Use(New(Config{Root: http.FS(root), Compress: true, ETag: true}))
+
+	etag := func(accept string) string {
+		req := httptest.NewRequest("GET", "/app.css", nil)
+		req.Header.Set(fiber.HeaderAcceptEncoding, accept)
+		_, _, h := do(t, app, req)
+		return h.Get(fiber.HeaderETag)
+	}
+
+	plain, gzipped := etag("identity"), etag("gzip")
+	if plain == "" || gzipped == "" || plain == gzipped {
+		t.Errorf("ETag of file %q and of its .gz sibling %q must differ",
+			plain, gzipped)
+	}
+
+	req := httptest.NewRequest("GET", "/app.css", nil)
+	req.Header.Set(fiber.HeaderAcceptEncoding, "gzip")
+	req.Header.Set(fiber.HeaderIfNoneMatch, gzipped)
+
+	status, _, _ := do(t, app, req)
+	if status != fiber.StatusNotModified {
+		t.Errorf("status = %d, want 304", status)
+	}
+}
+
+// random returns n bytes which don't compress.
+func random(t *testing.T, n int) []byte {
+	t.Helper()
+
+	b := make([]byte, n)
+	x := uint32(1)
+	for i := range b {
+		x ^= x << 13
+		x ^= x >> 17
+		x ^= x << 5
+		b[i] = byte(x)
+	}
+	return b
+}
@@ -1,11 +1,15 @@
 package filesystem
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"os"
+	pathpkg "path"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -34,12 +38,69 @@ type Config struct {
 	// Optional. Default value 0.
 	MaxAge int `json:"max_age"`
 
-	// File to return if path is not found. Useful for SPA's.
+	// File to return if path is not found, regardless of its extension.
+	// Consider SPAIndex for SPA's.
 	//
 	// Optional. Default: ""
 	NotFoundFile string `json:"not_found_file"`
 
-	RootPath string
+	// SPAIndex is the file to return if path is not found and has no file
+	// extension. Client side routes resolve to the SPA while requests for
+	// missing assets still get 404.
+	//
+	// Optional. Default: ""
+	SPAIndex string `json:"spa_index"`
+
+	// RootPath is the directory inside Root to serve files from.
+	//
+	// Optional. Default: ""
+	RootPath string `json:"root_path"`
+
+	// Browse enables directory listing for directories without index file.
+	// Listing is sent as JSON if client accepts it over HTML.
+	//
+	// Optional. Default: false
+	Browse bool `json:"browse"`
+
+	// NotFoundHandler is called when the requested file is not found.
+	//
+	// Optional. Default: responds with 404 status and calls next handler
+	NotFoundHandler fiber.Handler `json:"-"`
+
+	// ETag enables strong ETag generation from file content and handling of
+	// If-None-Match requests. Content hashes are computed once per file and
+	// kept in memory.
+	//
+	// Optional. Default: false
+	ETag bool `json:"etag"`
+
+	// ModTime is used as Last-Modified value for files with zero
+	// modification time, which is always the case for embed.FS files.
+	//
+	// Optional. Default: time the handler is created
+	ModTime time.Time `json:"-"`
+
+	// Immutable reports whether the served file at path, relative to
+	// RootPath, may be cached forever. Such files are served with "public,
+	// max-age=31536000, immutable" Cache-Control regardless of MaxAge.
+	// NotFoundFile and SPAIndex served instead of missing files are never
+	// immutable.
+	//
+	// Optional. Default: HashedName
+	Immutable func(path string) bool `json:"-"`
+
+	// Compress enables serving of precompressed .br and .gz siblings of the
+	// requested file and on the fly compression of compressible content
+	// types with in-memory cache, depending on client Accept-Encoding.
+	//
+	// Optional. Default: false
+	Compress bool `json:"compress"`
+
+	// ByteRange enables Range requests support: single and multiple ranges,
+	// If-Range preconditions and 416 responses for unsatisfiable ranges.
+	//
+	// Optional. Default: false
+	ByteRange bool `json:"byte_range"`
 }
 
 // ConfigDefault is the default config
@@ -66,10 +127,20 @@ func New(config ...Config) fiber.Handler {
 		if !strings.HasPrefix(cfg.Index, "/") {
 			cfg.Index = "/" + cfg.Index
 		}
-		strings.TrimSuffix(cfg.RootPath, "/")
+		cfg.RootPath = strings.TrimSuffix(cfg.RootPath, "/")
 		if cfg.NotFoundFile != "" && !strings.HasPrefix(cfg.NotFoundFile, "/") {
 			cfg.NotFoundFile = "/" + cfg.NotFoundFile
 		}
+		if cfg.SPAIndex != "" && !strings.HasPrefix(cfg.SPAIndex, "/") {
+			cfg.SPAIndex = "/" + cfg.SPAIndex
+		}
+	}
+
+	if cfg.ModTime.IsZero() {
+		cfg.ModTime = time.Now().UTC().Truncate(time.Second)
+	}
+	if cfg.Immutable == nil {
+		cfg.Immutable = HashedName
 	}
 
 	if cfg.Root == nil {
@@ -79,6 +150,14 @@ func New(config ...Config) fiber.Handler {
 	var once sync.Once
 	var prefix string
 	var cacheControlStr = "public, max-age=" + strconv.Itoa(cfg.MaxAge)
+	var cache = newFileCache()
+
+	notFound := cfg.NotFoundHandler
+	if notFound == nil {
+		notFound = func(c *fiber.Ctx) error {
+			return c.Status(fiber.StatusNotFound).Next()
+		}
+	}
 
 	// Return new handler
 	return func(c *fiber.Ctx) (err error) {
@@ -99,79 +178,193 @@ func New(config ...Config) fiber.Handler {
 			prefix = c.Route().Path
 		})
 
-		// Strip prefix
-		path := strings.TrimPrefix(c.Path(), prefix)
-		if !strings.HasPrefix(path, "/") {
-			path = "/" + path
-		}
+		// Strip prefix and resolve dot segments, so the path can't leave
+		// RootPath
+		path := pathpkg.Clean("/" + strings.TrimPrefix(c.Path(), prefix))
 
 		var (
 			file http.File
 			stat os.FileInfo
 		)
 
-		file, err = cfg.Root.Open(cfg.RootPath + path)
-		if err != nil && os.IsNotExist(err) && cfg.NotFoundFile != "" {
-			file, err = cfg.Root.Open(cfg.RootPath + cfg.NotFoundFile)
+		// Name of the file actually served, used as cache key, and its path
+		// relative to RootPath
+		name := fsName(cfg.RootPath, path)
+		served := path
+
+		// isFallback is set if the file is served instead of missing one
+		var isFallback bool
+
+		file, err = cfg.Root.Open(name)
+		if err != nil && os.IsNotExist(err) {
+			fallback := cfg.NotFoundFile
+			if cfg.SPAIndex != "" && !hasFileExtension(path) {
+				fallback = cfg.SPAIndex
+			}
+			if fallback != "" {
+				name = fsName(cfg.RootPath, fallback)
+				served = fallback
+				isFallback = true
+				file, err = cfg.Root.Open(name)
+			}
 		}
 
 		if err != nil {
 			if os.IsNotExist(err) {
-				return c.Status(fiber.StatusNotFound).Next()
+				return notFound(c)
 			}
 			return
 		}
 
 		if stat, err = file.Stat(); err != nil {
+			file.Close()
 			return
 		}
 
 		// Serve index if path is directory
 		if stat.IsDir() {
 			indexPath := strings.TrimSuffix(path, "/") + cfg.Index
-			index, err := cfg.Root.Open(cfg.RootPath + indexPath)
+			index, err := cfg.Root.Open(fsName(cfg.RootPath, indexPath))
 			if err == nil {
 				indexStat, err := index.Stat()
 				if err == nil {
+					file.Close()
 					file = index
 					stat = indexStat
+					name = fsName(cfg.RootPath, indexPath)
+					served = indexPath
 				}
 			}
 		}
 
+		// List directory without index or respond as not found
+		if stat.IsDir() {
+			if cfg.Browse {
+				return browse(c, file, c.Path())
+			}
+			file.Close()
+			return notFound(c)
+		}
+
 		modTime := stat.ModTime()
-		contentLength := int(stat.Size())
+		if modTime.IsZero() {
+			modTime = cfg.ModTime
+		}
+
+		var (
+			body io.ReadSeeker = file
+			size               = stat.Size()
+			etag string
+		)
+
+		if cfg.ETag {
+			etag, err = cache.etag(name, file, stat)
+			if err != nil {
+				file.Close()
+				return err
+			}
+		}
 
 		// Set Content Type header
 		c.Type(getFileExtension(stat.Name()))
 
+		// Replace body with compressed representation if any
+		if cfg.Compress {
+			c.Vary(fiber.HeaderAcceptEncoding)
+
+			ef, err := cache.encode(cfg.Root, name, file, stat,
+				string(c.Response().Header.ContentType()), etag,
+				acceptedEncodings(c.Get(fiber.HeaderAcceptEncoding)), cfg.ETag)
+			if err != nil {
+				file.Close()
+				return err
+			}
+
+			if ef != nil {
+				file.Close()
+				body, size, etag = ef.body, ef.size, ef.etag
+				c.Set(fiber.HeaderContentEncoding, ef.encoding)
+			}
+		}
+
+		if etag != "" {
+			c.Set(fiber.HeaderETag, etag)
+		}
+
 		// Set Last Modified header
-		if !modTime.IsZero() {
-			c.Set(fiber.HeaderLastModified, modTime.UTC().Format(http.TimeFormat))
+		c.Set(fiber.HeaderLastModified, modTime.UTC().Format(http.TimeFormat))
+
+		// Set Cache Control header
+		if !isFallback && cfg.Immutable(served) {
+			c.Set(fiber.HeaderCacheControl, immutableCacheControl)
+		} else if cfg.MaxAge > 0 {
+			c.Set(fiber.HeaderCacheControl, cacheControlStr)
 		}
 
-		if method == fiber.MethodGet {
-			if cfg.MaxAge > 0 {
-				c.Set(fiber.HeaderCacheControl, cacheControlStr)
+		if notModified(c, etag, modTime) {
+			c.Status(fiber.StatusNotModified)
+			c.Response().SkipBody = true
+			return closeBody(body)
+		}
+
+		if cfg.ByteRange {
+			c.Set(fiber.HeaderAcceptRanges, "bytes")
+
+			rh := c.Get(fiber.HeaderRange)
+			if rh != "" && rangeApplies(c, etag, modTime) {
+				ranges, err := parseRange(rh, size)
+				switch {
+				case errors.Is(err, errNoOverlap):
+					closeBody(body)
+					c.Set(fiber.HeaderContentRange,
+						"bytes */"+strconv.FormatInt(size, 10))
+					return c.SendStatus(fiber.StatusRequestedRangeNotSatisfiable)
+				case err == nil && len(ranges) > 0 && sumRangesSize(ranges) <= size:
+					return sendRanges(c, body, size, ranges,
+						method == fiber.MethodHead)
+				}
 			}
-			c.Response().SetBodyStream(file, contentLength)
+		}
+
+		if method == fiber.MethodGet {
+			c.Response().SetBodyStream(body, int(size))
 			return nil
 		}
 		if method == fiber.MethodHead {
 			c.Request().ResetBody()
 			// Fasthttp should skipbody by default if HEAD?
 			c.Response().SkipBody = true
-			c.Response().Header.SetContentLength(contentLength)
-			if err := file.Close(); err != nil {
-				return err
-			}
-			return nil
+			c.Response().Header.SetContentLength(int(size))
+			return closeBody(body)
 		}
 
 		return c.Next()
 	}
 }
 
+// closeBody closes body if it is a file, in-memory bodies need no closing.
+func closeBody(body io.ReadSeeker) error {
+	if c, ok := body.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// fsName joins root path with the request path into a name to open in Root.
+// Trailing slash is removed since io/fs rejects such names.
+func fsName(rootPath, path string) string {
+	name := strings.TrimSuffix(rootPath+path, "/")
+	if name == "" {
+		return "/"
+	}
+	return name
+}
+
+// hasFileExtension reports whether the last path segment has an extension.
+func hasFileExtension(path string) bool {
+	return getFileExtension(path[strings.LastIndexByte(path, '/')+1:]) != ""
+}
+
 func getFileExtension(path string) string {
 	n := strings.LastIndexByte(path, '.')
 	if n < 0 {
@@ -0,0 +1,251 @@
+package filesystem
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var site = fstest.MapFS{
+	"dist/index.html":      {Data: []byte("index")},
+	"dist/404.html":        {Data: []byte("not found page")},
+	"dist/app.js":          {Data: []byte("console.log(1)")},
+	"dist/docs/index.html": {Data: []byte("docs index")},
+	"dist/files/a.txt":     {Data: []byte("a")},
+	"dist/files/b.txt":     {Data: []byte("bb")},
+	"dist/files/sub/c.txt": {Data: []byte("c")},
+	"dist/assets/app.css":  {Data: []byte("body{}")},
+	"secret.txt":           {Data: []byte("secret")},
+}
+
+// do sends request to the app and returns response status, body and headers.
+func do(t *testing.T, app *fiber.App, req *http.Request) (int, string,
+	http.Header) {
+
+	t.Helper()
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return resp.StatusCode, string(body), resp.Header
+}
+
+func TestModes(t *testing.T) {
+	tests := []struct {
+		name   string
+		mount  string
+		cfg    Config
+		path   string
+		status int
+		body   string
+	}{
+		{"file", "", Config{}, "/dist/app.js", 200, "console.log(1)"},
+		{"root path", "", Config{RootPath: "dist"}, "/app.js", 200, "console.log(1)"},
+		{"root path escape", "", Config{RootPath: "dist"}, "/../secret.txt", 404, ""},
+		{"index", "", Config{RootPath: "dist"}, "/", 200, "index"},
+		{"nested index", "", Config{RootPath: "dist"}, "/docs/", 200, "docs index"},
+		{"custom index", "", Config{RootPath: "dist", Index: "a.txt"}, "/files", 200, "a"},
+		{"missing", "", Config{RootPath: "dist"}, "/missing.js", 404, ""},
+		{"dir without index", "", Config{RootPath: "dist"}, "/files/", 404, ""},
+		{"not found file", "", Config{RootPath: "dist", NotFoundFile: "404.html"},
+			"/missing.js", 200, "not found page"},
+		{"spa route", "", Config{RootPath: "dist", SPAIndex: "index.html"},
+			"/catalog/42", 200, "index"},
+		{"spa missing asset", "", Config{RootPath: "dist", SPAIndex: "index.html"},
+			"/assets/missing.js", 404, ""},
+		{"not found handler", "", Config{
+			RootPath: "dist",
+			NotFoundHandler: func(c *fiber.Ctx) error {
+				return c.Status(fiber.StatusTeapot).SendString("custom")
+			},
+		}, "/missing.js", 418, "custom"},
+		{"prefix", "/static", Config{RootPath: "dist"}, "/static/app.js", 200, "console.log(1)"},
+		{"prefix index", "/static", Config{RootPath: "dist"}, "/static/", 200, "index"},
+		{"skipped by next", "", Config{
+			RootPath: "dist",
+			Next: func(c *fiber.Ctx) bool {
+				return strings.HasPrefix(c.Path(), "/api/")
+			},
+		}, "/api/app.js", 404, "Cannot GET /api/app.js"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Root = http.FS(site)
+
+			app := fiber.New()
+			if tt.mount != "" {
+				app.Use(tt.mount, New(tt.cfg))
+			} else {
+				app.Use(New(tt.cfg))
+			}
+
+			status, body, _ := do(t, app, httptest.NewRequest("GET", tt.path, nil))
+			if status != tt.status {
+				t.Errorf("status = %d, want %d", status, tt.status)
+			}
+			if tt.body != "" && body != tt.body {
+				t.Errorf("body = %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestMethods(t *testing.T) {
+	app := fiber.New()
+	app.Use(New(Config{Root: http.FS(site), RootPath: "dist"}))
+	app.Post("/app.js", func(c *fiber.Ctx) error {
+		return c.SendString("posted")
+	})
+
+	status, body, _ := do(t, app, httptest.NewRequest("POST", "/app.js", nil))
+	if status != 200 || body != "posted" {
+		t.Errorf("POST = %d %q, want 200 \"posted\"", status, body)
+	}
+
+	status, body, h := do(t, app, httptest.NewRequest("HEAD", "/app.js", nil))
+	if status != 200 || body != "" {
+		t.Errorf("HEAD = %d %q, want 200 without body", status, body)
+	}
+	if h.Get(fiber.HeaderContentLength) != "14" {
+		t.Errorf("HEAD Content-Length = %q, want 14",
+			h.Get(fiber.HeaderContentLength))
+	}
+}
+
+func TestPathTraversal(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.Mkdir(filepath.Join(dir, "public"), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string]string{
+		"secret.txt":        "secret",
+		"public/index.html": "index",
+	} {
+		err = ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	roots := map[string]http.FileSystem{
+		"dir":  http.Dir(filepath.Join(dir, "public")),
+		"fs":   http.FS(os.DirFS(filepath.Join(dir, "public"))),
+		"root": http.Dir(dir),
+	}
+
+	paths := []string{
+		"/../secret.txt",
+		"/%2e%2e/secret.txt",
+		"/..%2fsecret.txt",
+		"/..%5csecret.txt",
+		"/public/../../secret.txt",
+		"//../secret.txt",
+	}
+
+	for name, root := range roots {
+		cfg := Config{Root: root, Browse: true}
+		if name == "root" {
+			cfg.RootPath = "public"
+		}
+
+		app := fiber.New()
+		app.Use(New(cfg))
+
+		for _, p := range paths {
+			t.Run(name+p, func(t *testing.T) {
+				// Path is sent as is, without cleaning by net/url
+				req := httptest.NewRequest("GET", "/", nil)
+				req.URL.Opaque = p
+				req.RequestURI = p
+
+				status, body, _ := do(t, app, req)
+				if body == "secret" {
+					t.Errorf("file outside root served with %d: %q", status,
+						body)
+				}
+			})
+		}
+	}
+}
+
+func TestBrowse(t *testing.T) {
+	app := fiber.New()
+	app.Use("/static", New(Config{
+		Root:     http.FS(site),
+		RootPath: "dist",
+		Browse:   true,
+	}))
+
+	t.Run("html", func(t *testing.T) {
+		status, body, h := do(t, app,
+			httptest.NewRequest("GET", "/static/files", nil))
+		if status != 200 {
+			t.Fatalf("status = %d, want 200", status)
+		}
+		if ct := h.Get(fiber.HeaderContentType); !strings.HasPrefix(ct, "text/html") {
+			t.Errorf("Content-Type = %q, want text/html", ct)
+		}
+		for _, s := range []string{
+			`<a href="/static">..</a>`,
+			`<a href="/static/files/sub">sub/</a>`,
+			`<a href="/static/files/a.txt">a.txt</a> 1`,
+			`<a href="/static/files/b.txt">b.txt</a> 2`,
+		} {
+			if !strings.Contains(body, s) {
+				t.Errorf("listing doesn't contain %q:\n%s", s, body)
+			}
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/static/files/", nil)
+		req.Header.Set(fiber.HeaderAccept, fiber.MIMEApplicationJSON)
+
+		status, body, _ := do(t, app, req)
+		if status != 200 {
+			t.Fatalf("status = %d, want 200", status)
+		}
+
+		var es []dirEntry
+		err := json.Unmarshal([]byte(body), &es)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []string
+		for _, e := range es {
+			got = append(got, e.Path)
+		}
+		want := []string{"/static/files/sub", "/static/files/a.txt",
+			"/static/files/b.txt"}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("entries = %v, want %v (dirs first)", got, want)
+		}
+	})
+
+	t.Run("index preferred", func(t *testing.T) {
+		_, body, _ := do(t, app, httptest.NewRequest("GET", "/static/docs/", nil))
+		if body != "docs index" {
+			t.Errorf("body = %q, want docs index", body)
+		}
+	})
+}
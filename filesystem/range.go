@@ -0,0 +1,241 @@
+package filesystem
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var errNoOverlap = errors.New("invalid range: failed to overlap")
+
+// httpRange specifies the byte range to be sent to the client.
+type httpRange struct {
+	start, length int64
+}
+
+func (r httpRange) contentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.start, r.start+r.length-1, size)
+}
+
+func (r httpRange) mimeHeader(contentType string, size int64) textproto.MIMEHeader {
+	return textproto.MIMEHeader{
+		fiber.HeaderContentRange: {r.contentRange(size)},
+		fiber.HeaderContentType:  {contentType},
+	}
+}
+
+// parseRange parses a Range header value as per RFC 7233. errNoOverlap is
+// returned if none of the ranges overlap the content.
+func parseRange(s string, size int64) ([]httpRange, error) {
+	const b = "bytes="
+	if !strings.HasPrefix(s, b) {
+		return nil, errors.New("invalid range")
+	}
+
+	var (
+		ranges    []httpRange
+		noOverlap bool
+	)
+
+	for _, ra := range strings.Split(s[len(b):], ",") {
+		ra = strings.TrimSpace(ra)
+		if ra == "" {
+			continue
+		}
+
+		i := strings.Index(ra, "-")
+		if i < 0 {
+			return nil, errors.New("invalid range")
+		}
+
+		start, end := strings.TrimSpace(ra[:i]), strings.TrimSpace(ra[i+1:])
+
+		var r httpRange
+
+		if start == "" {
+			// Suffix range: last N bytes of the content
+			i, err := strconv.ParseInt(end, 10, 64)
+			if err != nil || i < 0 {
+				return nil, errors.New("invalid range")
+			}
+			if i == 0 {
+				noOverlap = true
+				continue
+			}
+			if i > size {
+				i = size
+			}
+			r.start = size - i
+			r.length = size - r.start
+		} else {
+			i, err := strconv.ParseInt(start, 10, 64)
+			if err != nil || i < 0 {
+				return nil, errors.New("invalid range")
+			}
+			if i >= size {
+				noOverlap = true
+				continue
+			}
+			r.start = i
+			if end == "" {
+				r.length = size - r.start
+			} else {
+				i, err := strconv.ParseInt(end, 10, 64)
+				if err != nil || r.start > i {
+					return nil, errors.New("invalid range")
+				}
+				if i >= size {
+					i = size - 1
+				}
+				r.length = i - r.start + 1
+			}
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	if noOverlap && len(ranges) == 0 {
+		return nil, errNoOverlap
+	}
+
+	return ranges, nil
+}
+
+func sumRangesSize(ranges []httpRange) (size int64) {
+	for _, r := range ranges {
+		size += r.length
+	}
+	return
+}
+
+// rangeApplies checks If-Range precondition. Range is applied only if the
+// validator strongly matches current ETag or equals Last-Modified date.
+func rangeApplies(c *fiber.Ctx, etag string, modTime time.Time) bool {
+	ir := c.Get(fiber.HeaderIfRange)
+	if ir == "" {
+		return true
+	}
+
+	if strings.HasPrefix(ir, `"`) || strings.HasPrefix(ir, "W/") {
+		return etag != "" && !strings.HasPrefix(ir, "W/") && ir == etag
+	}
+
+	t, err := http.ParseTime(ir)
+	if err != nil {
+		return false
+	}
+
+	return modTime.Truncate(time.Second).Equal(t)
+}
+
+// sendRanges responds with 206 Partial Content containing the requested
+// ranges of the body. Single range is sent as is, multiple ranges are sent
+// as multipart/byteranges.
+func sendRanges(c *fiber.Ctx, body io.ReadSeeker, size int64,
+	ranges []httpRange, head bool) error {
+
+	contentType := string(c.Response().Header.ContentType())
+
+	c.Status(fiber.StatusPartialContent)
+
+	if len(ranges) == 1 {
+		r := ranges[0]
+
+		c.Set(fiber.HeaderContentRange, r.contentRange(size))
+
+		if head {
+			c.Response().SkipBody = true
+			c.Response().Header.SetContentLength(int(r.length))
+			return closeBody(body)
+		}
+
+		_, err := body.Seek(r.start, io.SeekStart)
+		if err != nil {
+			closeBody(body)
+			return err
+		}
+
+		c.Response().SetBodyStream(&readCloser{
+			Reader: io.LimitReader(body, r.length),
+			body:   body,
+		}, int(r.length))
+
+		return nil
+	}
+
+	// Compute multipart body length by writing part headers only
+	var w countingWriter
+	mw := multipart.NewWriter(&w)
+	for _, r := range ranges {
+		mw.CreatePart(r.mimeHeader(contentType, size))
+		w += countingWriter(r.length)
+	}
+	mw.Close()
+
+	c.Set(fiber.HeaderContentType,
+		"multipart/byteranges; boundary="+mw.Boundary())
+
+	if head {
+		c.Response().SkipBody = true
+		c.Response().Header.SetContentLength(int(w))
+		return closeBody(body)
+	}
+
+	boundary := mw.Boundary()
+
+	pr, pw := io.Pipe()
+	mw = multipart.NewWriter(pw)
+	mw.SetBoundary(boundary)
+
+	go func() {
+		defer closeBody(body)
+		for _, r := range ranges {
+			part, err := mw.CreatePart(r.mimeHeader(contentType, size))
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			_, err = body.Seek(r.start, io.SeekStart)
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			_, err = io.CopyN(part, body, r.length)
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		mw.Close()
+		pw.Close()
+	}()
+
+	c.Response().SetBodyStream(pr, int(w))
+
+	return nil
+}
+
+// readCloser reads limited part of the body and closes the body itself.
+type readCloser struct {
+	io.Reader
+	body io.ReadSeeker
+}
+
+func (rc *readCloser) Close() error {
+	return closeBody(rc.body)
+}
+
+type countingWriter int64
+
+func (w *countingWriter) Write(p []byte) (n int, err error) {
+	*w += countingWriter(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,240 @@
+package filesystem
+
+import (
+	"errors"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		header  string
+		size    int64
+		want    []httpRange
+		wantErr error
+	}{
+		{header: "bytes=0-4", size: 10, want: []httpRange{{0, 5}}},
+		{header: "bytes=5-", size: 10, want: []httpRange{{5, 5}}},
+		{header: "bytes=-3", size: 10, want: []httpRange{{7, 3}}},
+		{header: "bytes=-20", size: 10, want: []httpRange{{0, 10}}},
+		{header: "bytes=8-20", size: 10, want: []httpRange{{8, 2}}},
+		{header: "bytes=3-3", size: 10, want: []httpRange{{3, 1}}},
+		{header: "bytes=0-1, 4-5", size: 10, want: []httpRange{{0, 2}, {4, 2}}},
+		{header: "bytes= 0 - 1 ,,", size: 10, want: []httpRange{{0, 2}}},
+		{header: "bytes=0-1,10-20", size: 10, want: []httpRange{{0, 2}}},
+		{header: "bytes=", size: 10},
+		{header: "bytes=10-", size: 10, wantErr: errNoOverlap},
+		{header: "bytes=-0", size: 10, wantErr: errNoOverlap},
+		{header: "bytes=10-20,30-", size: 10, wantErr: errNoOverlap},
+		{header: "bytes=0-", size: 0, wantErr: errNoOverlap},
+		{header: "items=0-4", size: 10, wantErr: errInvalid},
+		{header: "bytes=4", size: 10, wantErr: errInvalid},
+		{header: "bytes=5-4", size: 10, wantErr: errInvalid},
+		{header: "bytes=a-4", size: 10, wantErr: errInvalid},
+		{header: "bytes=0-b", size: 10, wantErr: errInvalid},
+		{header: "bytes=-1-2", size: 10, wantErr: errInvalid},
+		{header: "bytes=--1", size: 10, wantErr: errInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			got, err := parseRange(tt.header, tt.size)
+
+			switch {
+			case tt.wantErr == errInvalid:
+				if err == nil || errors.Is(err, errNoOverlap) {
+					t.Fatalf("parseRange() error = %v, want invalid range", err)
+				}
+				return
+			case !errors.Is(err, tt.wantErr):
+				t.Fatalf("parseRange() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseRange() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("parseRange()[%d] = %v, want %v", i, got[i],
+						tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// errInvalid marks test cases expecting any error other than errNoOverlap.
+var errInvalid = errors.New("invalid")
+
+func TestRanges(t *testing.T) {
+	modTime := time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC)
+	root := fstest.MapFS{
+		"digits.txt": {Data: []byte("0123456789"), ModTime: modTime},
+	}
+
+	app := fiber.New()
+	app.Use(New(Config{Root: http.FS(root), ByteRange: true, ETag: true}))
+
+	_, _, h := do(t, app, httptest.NewRequest("GET", "/digits.txt", nil))
+	etag := h.Get(fiber.HeaderETag)
+	if h.Get(fiber.HeaderAcceptRanges) != "bytes" {
+		t.Errorf("Accept-Ranges = %q, want bytes", h.Get(fiber.HeaderAcceptRanges))
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		rangeHeader  string
+		ifRange      string
+		status       int
+		body         string
+		contentRange string
+	}{
+		{name: "no range", rangeHeader: "", status: 200, body: "0123456789"},
+		{name: "single", rangeHeader: "bytes=2-4", status: 206, body: "234",
+			contentRange: "bytes 2-4/10"},
+		{name: "suffix", rangeHeader: "bytes=-2", status: 206, body: "89",
+			contentRange: "bytes 8-9/10"},
+		{name: "open", rangeHeader: "bytes=7-", status: 206, body: "789",
+			contentRange: "bytes 7-9/10"},
+		{name: "unsatisfiable", rangeHeader: "bytes=20-", status: 416,
+			contentRange: "bytes */10"},
+		{name: "invalid ignored", rangeHeader: "bytes=4-2", status: 200,
+			body: "0123456789"},
+		{name: "bigger than file ignored", rangeHeader: "bytes=0-9,0-9",
+			status: 200, body: "0123456789"},
+		{name: "if-range etag", rangeHeader: "bytes=0-0", ifRange: etag,
+			status: 206, body: "0", contentRange: "bytes 0-0/10"},
+		{name: "if-range other etag", rangeHeader: "bytes=0-0",
+			ifRange: `"other"`, status: 200, body: "0123456789"},
+		{name: "if-range weak etag", rangeHeader: "bytes=0-0",
+			ifRange: "W/" + etag, status: 200, body: "0123456789"},
+		{name: "if-range date", rangeHeader: "bytes=0-0",
+			ifRange: modTime.Format(http.TimeFormat), status: 206, body: "0",
+			contentRange: "bytes 0-0/10"},
+		{name: "if-range old date", rangeHeader: "bytes=0-0",
+			ifRange: modTime.Add(-time.Hour).Format(http.TimeFormat),
+			status:  200, body: "0123456789"},
+		{name: "head", method: "HEAD", rangeHeader: "bytes=2-4", status: 206,
+			contentRange: "bytes 2-4/10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = "GET"
+			}
+
+			req := httptest.NewRequest(method, "/digits.txt", nil)
+			if tt.rangeHeader != "" {
+				req.Header.Set(fiber.HeaderRange, tt.rangeHeader)
+			}
+			if tt.ifRange != "" {
+				req.Header.Set(fiber.HeaderIfRange, tt.ifRange)
+			}
+
+			status, body, h := do(t, app, req)
+			if status != tt.status {
+				t.Errorf("status = %d, want %d", status, tt.status)
+			}
+			if tt.status != 416 && body != tt.body {
+				t.Errorf("body = %q, want %q", body, tt.body)
+			}
+			if got := h.Get(fiber.HeaderContentRange); got != tt.contentRange {
+				t.Errorf("Content-Range = %q, want %q", got, tt.contentRange)
+			}
+			if method == "HEAD" && h.Get(fiber.HeaderContentLength) != "3" {
+				t.Errorf("Content-Length = %q, want 3",
+					h.Get(fiber.HeaderContentLength))
+			}
+		})
+	}
+}
+
+func TestMultipleRanges(t *testing.T) {
+	root := fstest.MapFS{
+		"digits.txt": {Data: []byte("0123456789")},
+	}
+
+	app := fiber.New()
+	app.Use(New(Config{Root: http.FS(root), ByteRange: true}))
+
+	for _, method := range []string{"GET", "HEAD"} {
+		t.Run(method, func(t *testing.T) {
+			req := httptest.NewRequest(method, "/digits.txt", nil)
+			req.Header.Set(fiber.HeaderRange, "bytes=0-1,5-6,-1")
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != 206 {
+				t.Fatalf("status = %d, want 206", resp.StatusCode)
+			}
+
+			mt, params, err := mime.ParseMediaType(resp.Header.Get(fiber.HeaderContentType))
+			if err != nil || mt != "multipart/byteranges" {
+				t.Fatalf("Content-Type = %q, want multipart/byteranges",
+					resp.Header.Get(fiber.HeaderContentType))
+			}
+
+			if method == "HEAD" {
+				if resp.ContentLength <= 0 {
+					t.Errorf("Content-Length = %d, want multipart length",
+						resp.ContentLength)
+				}
+				return
+			}
+
+			body, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cl := resp.Header.Get(fiber.HeaderContentLength); cl != strconv.Itoa(len(body)) {
+				t.Errorf("Content-Length = %s, body of %d bytes", cl, len(body))
+			}
+
+			want := []struct{ contentRange, body string }{
+				{"bytes 0-1/10", "01"},
+				{"bytes 5-6/10", "56"},
+				{"bytes 9-9/10", "9"},
+			}
+
+			mr := multipart.NewReader(strings.NewReader(string(body)),
+				params["boundary"])
+			for i, w := range want {
+				p, err := mr.NextPart()
+				if err != nil {
+					t.Fatalf("part %d: %v", i, err)
+				}
+				if cr := p.Header.Get(fiber.HeaderContentRange); cr != w.contentRange {
+					t.Errorf("part %d Content-Range = %q, want %q", i, cr,
+						w.contentRange)
+				}
+				if ct := p.Header.Get(fiber.HeaderContentType); !strings.HasPrefix(ct, "text/plain") {
+					t.Errorf("part %d Content-Type = %q, want text/plain", i, ct)
+				}
+				b, _ := ioutil.ReadAll(p)
+				if string(b) != w.body {
+					t.Errorf("part %d body = %q, want %q", i, b, w.body)
+				}
+			}
+			if _, err := mr.NextPart(); err == nil {
+				t.Error("more parts than ranges")
+			}
+		})
+	}
+}
@@ -4,19 +4,33 @@ go 1.16
 
 require (
 	entgo.io/ent v0.8.0
-	github.com/andybalholm/brotli v1.0.3 // indirect
+	github.com/BurntSushi/toml v0.3.1
+	github.com/andybalholm/brotli v1.0.3
+	github.com/deepmap/oapi-codegen v1.8.2
 	github.com/dgrijalva/jwt-go v3.2.0+incompatible
 	github.com/fasthttp/websocket v1.4.3 // indirect
+	github.com/getkin/kin-openapi v0.61.0
 	github.com/go-sql-driver/mysql v1.6.0 // indirect
 	github.com/gofiber/fiber/v2 v2.10.0
-	github.com/gofiber/websocket/v2 v2.0.4 // indirect
+	github.com/gofiber/websocket/v2 v2.0.4
 	github.com/golang-migrate/migrate/v4 v4.14.1
 	github.com/jmoiron/sqlx v1.3.4
-	github.com/klauspost/compress v1.12.3 // indirect
+	github.com/klauspost/compress v1.12.3
 	github.com/lib/pq v1.10.2
 	github.com/mattn/go-sqlite3 v1.14.7 // indirect
+	github.com/pkg/errors v0.9.1
+	github.com/prometheus/client_golang v1.11.0
 	github.com/savsgio/gotils v0.0.0-20210520110740-c57c45b83e0a // indirect
 	github.com/sirupsen/logrus v1.8.1
-	github.com/valyala/fasthttp v1.25.0 // indirect
-	golang.org/x/sys v0.0.0-20210525143221-35b2ab0089ea // indirect
+	github.com/valyala/fasthttp v1.25.0
+	go.opentelemetry.io/otel v1.0.1
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp v1.0.1
+	go.opentelemetry.io/otel/exporters/stdout/stdouttrace v1.0.1
+	go.opentelemetry.io/otel/sdk v1.0.1
+	go.opentelemetry.io/otel/trace v1.0.1
+	golang.org/x/crypto v0.0.0-20210513164829-c07d793c2f9a
+	google.golang.org/genproto v0.0.0-20201030142918-24207fddd1c3
+	google.golang.org/grpc v1.41.0
+	google.golang.org/protobuf v1.27.1
+	gopkg.in/yaml.v2 v2.4.0
 )
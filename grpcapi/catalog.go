@@ -0,0 +1,64 @@
+package grpcapi
+
+import (
+	"context"
+
+	eaptekav1 "eapteka/proto/eapteka/v1"
+	"eapteka/store"
+)
+
+type catalog struct {
+	eaptekav1.UnimplementedCatalogServiceServer
+	store *store.Store
+}
+
+func (c *catalog) Search(ctx context.Context, req *eaptekav1.SearchRequest) (
+	*eaptekav1.SearchResponse, error) {
+
+	r, err := c.store.Search(ctx, req.K)
+	if err != nil {
+		return nil, err
+	}
+
+	return &eaptekav1.SearchResponse{
+		Products:   productsToPB(r.Products),
+		Substances: substancesToPB(r.Substances),
+	}, nil
+}
+
+func (c *catalog) GetProduct(ctx context.Context,
+	req *eaptekav1.GetProductRequest) (*eaptekav1.Product, error) {
+
+	p, err := c.store.Product(ctx, req.ProductId)
+	if err != nil {
+		return nil, err
+	}
+
+	return productToPB(p), nil
+}
+
+func (c *catalog) ListProducts(ctx context.Context,
+	req *eaptekav1.ListProductsRequest) (*eaptekav1.ListProductsResponse,
+	error) {
+
+	ps, err := c.store.Products(ctx, req.SubstanceId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &eaptekav1.ListProductsResponse{Products: productsToPB(ps)}, nil
+}
+
+func (c *catalog) ListSubstances(ctx context.Context,
+	req *eaptekav1.ListSubstancesRequest) (*eaptekav1.ListSubstancesResponse,
+	error) {
+
+	ss, err := c.store.Substances(ctx, req.ProductId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &eaptekav1.ListSubstancesResponse{
+		Substances: substancesToPB(ss),
+	}, nil
+}
@@ -0,0 +1,164 @@
+package grpcapi
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"eapteka/ent"
+	eaptekav1 "eapteka/proto/eapteka/v1"
+)
+
+// timestamp returns timestamp of the optional time, nil if it isn't set.
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// timeOf returns optional time of the timestamp, nil if it isn't set.
+func timeOf(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+func productToPB(p ent.Product) *eaptekav1.Product {
+	return &eaptekav1.Product{
+		Id:            p.ID,
+		SubstanceId:   p.SubstanceID,
+		Name:          p.Name,
+		Description:   p.Description,
+		Price:         p.Price,
+		ImageId:       p.ImageID,
+		Sku:           p.SKU,
+		PackSize:      p.PackSize,
+		SubstanceName: p.SubstanceName,
+		Count:         p.Count,
+		PurchasePrice: p.PurchasePrice,
+	}
+}
+
+func productsToPB(ps []ent.Product) []*eaptekav1.Product {
+	pbs := make([]*eaptekav1.Product, 0, len(ps))
+	for _, p := range ps {
+		pbs = append(pbs, productToPB(p))
+	}
+	return pbs
+}
+
+func substancesToPB(ss []ent.Substance) []*eaptekav1.Substance {
+	pbs := make([]*eaptekav1.Substance, 0, len(ss))
+	for _, s := range ss {
+		pbs = append(pbs, &eaptekav1.Substance{
+			Id:   s.ID,
+			Name: s.Name,
+		})
+	}
+	return pbs
+}
+
+func purchaseToPB(p ent.Purchase) *eaptekav1.Purchase {
+	return &eaptekav1.Purchase{
+		Id:        p.ID,
+		UserId:    p.UserID,
+		CreatedAt: timestamppb.New(p.CreatedAt),
+		Products:  productsToPB(p.Products),
+	}
+}
+
+func notifierToPB(n ent.Notifier) *eaptekav1.Notifier {
+	return &eaptekav1.Notifier{
+		Id:          n.ID,
+		UserId:      n.UserID,
+		ProductId:   n.ProductID,
+		Schedule:    n.Schedule,
+		Rule:        ruleToPB(n.Rule),
+		DoseAmount:  n.DoseAmount,
+		DoseUnit:    n.DoseUnit,
+		Notes:       n.Notes,
+		Paused:      n.Paused,
+		ProductName: n.ProductName,
+	}
+}
+
+func ruleToPB(r *ent.ScheduleRule) *eaptekav1.ScheduleRule {
+	if r == nil {
+		return nil
+	}
+
+	pb := &eaptekav1.ScheduleRule{
+		EveryHours: int32(r.EveryHours),
+		StartAt:    timestamp(r.StartAt),
+		EndAt:      timestamp(r.EndAt),
+		CourseDays: int32(r.CourseDays),
+		PillCount:  int32(r.PillCount),
+	}
+	for _, d := range r.Weekdays {
+		pb.Weekdays = append(pb.Weekdays, int32(d))
+	}
+
+	return pb
+}
+
+func ruleFromPB(pb *eaptekav1.ScheduleRule) *ent.ScheduleRule {
+	if pb == nil {
+		return nil
+	}
+
+	r := &ent.ScheduleRule{
+		EveryHours: int(pb.EveryHours),
+		StartAt:    timeOf(pb.StartAt),
+		EndAt:      timeOf(pb.EndAt),
+		CourseDays: int(pb.CourseDays),
+		PillCount:  int(pb.PillCount),
+	}
+	for _, d := range pb.Weekdays {
+		r.Weekdays = append(r.Weekdays, time.Weekday(d))
+	}
+
+	return r
+}
+
+func reminderToPB(r ent.Reminder, n ent.Notifier) *eaptekav1.Reminder {
+	return &eaptekav1.Reminder{
+		ReminderId:   r.ID,
+		NotifierId:   n.ID,
+		ProductId:    n.ProductID,
+		ProductName:  n.ProductName,
+		ScheduledAt:  timestamppb.New(r.ScheduledAt),
+		SnoozedUntil: timestamp(r.SnoozedUntil),
+		DoseAmount:   n.DoseAmount,
+		DoseUnit:     n.DoseUnit,
+		Notes:        n.Notes,
+	}
+}
+
+func refillToPB(r ent.Refill) *eaptekav1.Refill {
+	return &eaptekav1.Refill{
+		Id:                r.ID,
+		UserId:            r.UserID,
+		ProductId:         r.ProductID,
+		PurchaseId:        r.PurchaseID,
+		RunOutAt:          timestamppb.New(r.RunOutAt),
+		Remaining:         r.Remaining,
+		ReorderPurchaseId: r.ReorderPurchaseID,
+		CreatedAt:         timestamppb.New(r.CreatedAt),
+		ProductName:       r.ProductName,
+	}
+}
+
+func recommendationToPB(r ent.Recommendation) *eaptekav1.Recommendation {
+	pb := &eaptekav1.Recommendation{
+		ProductId: r.ProductID,
+		Score:     r.Score,
+		Reason:    r.Reason,
+	}
+	if r.Product != nil {
+		pb.Product = productToPB(*r.Product)
+	}
+	return pb
+}
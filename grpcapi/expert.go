@@ -0,0 +1,30 @@
+package grpcapi
+
+import (
+	"context"
+
+	eaptekav1 "eapteka/proto/eapteka/v1"
+	"eapteka/store"
+)
+
+type expert struct {
+	eaptekav1.UnimplementedExpertServiceServer
+	store *store.Store
+}
+
+func (e *expert) GetExpert(ctx context.Context,
+	req *eaptekav1.GetExpertRequest) (*eaptekav1.Expert, error) {
+
+	ex, err := e.store.Expert(ctx, req.SubstanceId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &eaptekav1.Expert{
+		Id:          ex.ID,
+		SubstanceId: ex.SubstanceID,
+		ExpertName:  ex.ExpertName,
+		Title:       ex.Title,
+		Text:        ex.Text,
+	}, nil
+}
@@ -0,0 +1,154 @@
+// Package grpcapi serves catalog, purchases, notifiers, experts and
+// recommendations over gRPC for internal services. Calls go through the
+// same store as REST handlers, so validation and errors are the same:
+// apierr errors are sent as statuses with BadRequest details of invalid
+// fields. Reminders and recommendations are streamed from the hub like
+// websockets do.
+//
+// Services are annotated with google.api.http rules of the matching REST
+// routes for reference, no gateway is generated from them.
+package grpcapi
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"eapteka/apierr"
+	"eapteka/hub"
+	"eapteka/metrics"
+	eaptekav1 "eapteka/proto/eapteka/v1"
+	"eapteka/recommend"
+	"eapteka/store"
+	"eapteka/tracing"
+)
+
+type Config struct {
+	// Timeout is the deadline of every unary call including its DB
+	// queries. Streams last until the client cancels them or the hub is
+	// closed.
+	Timeout time.Duration
+}
+
+var ConfigDefault = Config{
+	Timeout: 10 * time.Second,
+}
+
+// Server is the gRPC server with health service, which reports it serving
+// until Drain.
+type Server struct {
+	*grpc.Server
+	health *health.Server
+}
+
+func New(st *store.Store, h *hub.Hub, e *recommend.Engine, cfg Config,
+	opts ...grpc.ServerOption) *Server {
+
+	if cfg.Timeout == 0 {
+		cfg.Timeout = ConfigDefault.Timeout
+	}
+
+	// Timeout goes before apierr, so errors of cancelled queries are
+	// reported as timeout
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(
+			tracing.UnaryServerInterceptor(),
+			metrics.UnaryServerInterceptor(),
+			timeoutInterceptor(cfg.Timeout),
+			apierr.UnaryServerInterceptor(),
+			recoverUnaryInterceptor()),
+		grpc.ChainStreamInterceptor(
+			tracing.StreamServerInterceptor(),
+			metrics.StreamServerInterceptor(),
+			apierr.StreamServerInterceptor(),
+			recoverStreamInterceptor()))
+
+	s := &Server{
+		Server: grpc.NewServer(opts...),
+		health: health.NewServer(),
+	}
+
+	eaptekav1.RegisterCatalogServiceServer(s.Server, &catalog{store: st})
+	eaptekav1.RegisterExpertServiceServer(s.Server, &expert{store: st})
+	eaptekav1.RegisterPurchaseServiceServer(s.Server, &purchase{store: st})
+	eaptekav1.RegisterNotifierServiceServer(s.Server, &notifier{
+		store: st,
+		hub:   h,
+		cfg:   cfg,
+	})
+	eaptekav1.RegisterRecommendationServiceServer(s.Server, &recommendation{
+		engine: e,
+		hub:    h,
+	})
+
+	healthpb.RegisterHealthServer(s.Server, s.health)
+	reflection.Register(s.Server)
+
+	return s
+}
+
+// Drain reports the server and its services as not serving, so clients
+// and load balancers stop sending calls before it is stopped.
+func (s *Server) Drain() {
+	s.health.Shutdown()
+}
+
+func timeoutInterceptor(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (
+		interface{}, error) {
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		return handler(ctx, req)
+	}
+}
+
+// recovered logs the panic of the handler and returns internal status.
+func recovered(method string, r interface{}) error {
+	logrus.WithFields(logrus.Fields{
+		"method": method,
+		"panic":  r,
+		"stack":  string(debug.Stack()),
+	}).Error("handler panicked")
+
+	return status.Error(codes.Internal, "internal server error")
+}
+
+func recoverUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (
+		resp interface{}, err error) {
+
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(info.FullMethod, r)
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+func recoverStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream,
+		info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(info.FullMethod, r)
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,96 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"eapteka/ent"
+	"eapteka/hub"
+	eaptekav1 "eapteka/proto/eapteka/v1"
+	"eapteka/refill"
+	"eapteka/scheduler"
+	"eapteka/store"
+)
+
+// dial starts server without DB and returns client connection to it.
+func dial(t *testing.T, h *hub.Hub) *grpc.ClientConn {
+	ln := bufconn.Listen(1 << 16)
+
+	s := New(store.New(nil, store.Config{}), h, nil, Config{})
+	go s.Serve(ln)
+	t.Cleanup(s.Stop)
+
+	cc, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return ln.Dial()
+		}),
+		grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cc.Close() })
+
+	return cc
+}
+
+func TestStreamRemindersRequiresSubscription(t *testing.T) {
+	cc := dial(t, hub.New(hub.Config{}))
+
+	s, err := eaptekav1.NewNotifierServiceClient(cc).StreamReminders(
+		context.Background(), &eaptekav1.StreamRemindersRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = s.Recv()
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Recv() error = %v, want InvalidArgument", err)
+	}
+}
+
+func TestStreamRemindersRefills(t *testing.T) {
+	h := hub.New(hub.Config{})
+	cc := dial(t, h)
+
+	s, err := eaptekav1.NewNotifierServiceClient(cc).StreamReminders(
+		context.Background(), &eaptekav1.StreamRemindersRequest{UserId: "u1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Wait for the stream to register in the hub
+	for i := 0; len(h.Topics()) == 0; i++ {
+		if i == 100 {
+			t.Fatal("stream isn't registered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The stream is subscribed to topics of its user only
+	topics := h.Topics()
+	sort.Strings(topics)
+	want := []string{refill.Topic("u1"), scheduler.Topic("u1")}
+	if !reflect.DeepEqual(topics, want) {
+		t.Fatalf("topics = %v, want %v", topics, want)
+	}
+
+	h.Broadcast(refill.Topic("u2"), ent.Refill{ID: 1, UserID: "u2"})
+	h.Broadcast(refill.Topic("u1"), ent.Refill{ID: 2, UserID: "u1"})
+
+	m, err := s.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := m.GetRefill().GetId(); id != 2 {
+		t.Fatalf("refill id = %d, want 2", id)
+	}
+}
@@ -0,0 +1,269 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"eapteka/apierr"
+	"eapteka/ent"
+	"eapteka/hub"
+	eaptekav1 "eapteka/proto/eapteka/v1"
+	"eapteka/refill"
+	"eapteka/scheduler"
+	"eapteka/store"
+)
+
+type notifier struct {
+	eaptekav1.UnimplementedNotifierServiceServer
+	store *store.Store
+	hub   *hub.Hub
+	cfg   Config
+}
+
+func (n *notifier) ListNotifiers(ctx context.Context,
+	req *eaptekav1.ListNotifiersRequest) (*eaptekav1.ListNotifiersResponse,
+	error) {
+
+	ns := n.store.Notifiers()
+
+	resp := &eaptekav1.ListNotifiersResponse{
+		Notifiers: make([]*eaptekav1.Notifier, 0, len(ns)),
+	}
+	for _, nt := range ns {
+		resp.Notifiers = append(resp.Notifiers, notifierToPB(nt))
+	}
+
+	return resp, nil
+}
+
+func (n *notifier) GetNotifier(ctx context.Context,
+	req *eaptekav1.GetNotifierRequest) (*eaptekav1.Notifier, error) {
+
+	nt, err := n.store.Notifier(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return notifierToPB(nt), nil
+}
+
+func (n *notifier) CreateNotifier(ctx context.Context,
+	req *eaptekav1.CreateNotifierRequest) (*eaptekav1.Notifier, error) {
+
+	if req.Notifier == nil {
+		return nil, apierr.Validation("notifier is required",
+			apierr.Field("notifier", "is required"))
+	}
+
+	var nt ent.Notifier
+
+	err := setNotifierFields(&nt, req.Notifier, notifierFields)
+	if err != nil {
+		return nil, err
+	}
+
+	nt, err = n.store.CreateNotifier(ctx, nt)
+	if err != nil {
+		return nil, err
+	}
+
+	return notifierToPB(nt), nil
+}
+
+func (n *notifier) UpdateNotifier(ctx context.Context,
+	req *eaptekav1.UpdateNotifierRequest) (*eaptekav1.Notifier, error) {
+
+	if req.Notifier == nil {
+		return nil, apierr.Validation("notifier is required",
+			apierr.Field("notifier", "is required"))
+	}
+
+	// Notifier is replaced unless the mask lists fields to change
+	paths := req.GetUpdateMask().GetPaths()
+	if len(paths) == 0 {
+		paths = notifierFields
+	}
+
+	nt, err := n.store.UpdateNotifier(ctx, req.Notifier.Id,
+		func(nt *ent.Notifier) error {
+			if len(req.GetUpdateMask().GetPaths()) == 0 {
+				*nt = ent.Notifier{}
+			}
+			return setNotifierFields(nt, req.Notifier, paths)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return notifierToPB(nt), nil
+}
+
+func (n *notifier) DeleteNotifier(ctx context.Context,
+	req *eaptekav1.DeleteNotifierRequest) (*emptypb.Empty, error) {
+
+	err := n.store.DeleteNotifier(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// notifierFields are the writable fields of the notifier by their proto
+// names.
+var notifierFields = []string{"user_id", "product_id", "schedule", "rule",
+	"dose_amount", "dose_unit", "notes", "paused", "rrule"}
+
+// setNotifierFields copies fields listed by paths from pb to n.
+func setNotifierFields(n *ent.Notifier, pb *eaptekav1.Notifier,
+	paths []string) error {
+
+	for _, p := range paths {
+		switch p {
+		case "user_id":
+			n.UserID = pb.UserId
+		case "product_id":
+			n.ProductID = pb.ProductId
+		case "schedule":
+			n.Schedule = pb.Schedule
+		case "rule":
+			n.Rule = ruleFromPB(pb.Rule)
+		case "dose_amount":
+			n.DoseAmount = pb.DoseAmount
+		case "dose_unit":
+			n.DoseUnit = pb.DoseUnit
+		case "notes":
+			n.Notes = pb.Notes
+		case "paused":
+			n.Paused = pb.Paused
+		case "rrule":
+			n.RRule = pb.Rrule
+		default:
+			return apierr.Validation("invalid update mask",
+				apierr.Field("update_mask",
+					fmt.Sprintf("unknown or read-only field %q", p)))
+		}
+	}
+
+	return nil
+}
+
+func (n *notifier) StreamReminders(req *eaptekav1.StreamRemindersRequest,
+	stream eaptekav1.NotifierService_StreamRemindersServer) error {
+
+	if req.UserId == "" && len(req.NotifierIds) == 0 {
+		return apierr.Validation("user_id or notifier_ids is required",
+			apierr.Field("user_id", "is required without notifier_ids"))
+	}
+
+	sub := ent.NotifierSubscription{
+		UserID:      req.UserId,
+		NotifierIDs: req.NotifierIds,
+	}
+
+	ctx := stream.Context()
+
+	opCtx, cancel := context.WithTimeout(ctx, n.cfg.Timeout)
+	users, err := n.store.SubscribedUsers(opCtx, sub)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	// The stream receives messages of the subscribed users only
+	topics := make([]string, 0, len(users)+1)
+	for _, u := range users {
+		topics = append(topics, scheduler.Topic(u))
+	}
+	if sub.UserID != "" {
+		topics = append(topics, refill.Topic(sub.UserID))
+	}
+
+	client := n.hub.Register(topics...)
+	defer n.hub.Unregister(client)
+
+	// forward sends reminder or refill to the client if it is subscribed
+	forward := func(m hub.Message) error {
+		switch {
+		case strings.HasPrefix(m.Topic, scheduler.Topic("")):
+			var r ent.Reminder
+			if err := json.Unmarshal(m.Payload, &r); err != nil {
+				return err
+			}
+
+			opCtx, cancel := context.WithTimeout(ctx, n.cfg.Timeout)
+			nt, err := n.store.Notifier(opCtx, r.NotifierID)
+			cancel()
+			if err != nil {
+				if !errors.Is(err, store.ErrNotifierNotFound) {
+					logrus.WithError(err).Error("failed to get notifier")
+				}
+				return nil
+			}
+			if !store.Subscribed(sub, nt) {
+				return nil
+			}
+
+			return stream.Send(&eaptekav1.StreamRemindersResponse{
+				Event: &eaptekav1.StreamRemindersResponse_Reminder{
+					Reminder: reminderToPB(r, nt),
+				},
+			})
+
+		case strings.HasPrefix(m.Topic, refill.Topic("")):
+			var rf ent.Refill
+			if err := json.Unmarshal(m.Payload, &rf); err != nil {
+				return err
+			}
+
+			if sub.UserID == "" || sub.UserID != rf.UserID {
+				return nil
+			}
+
+			return stream.Send(&eaptekav1.StreamRemindersResponse{
+				Event: &eaptekav1.StreamRemindersResponse_Refill{
+					Refill: refillToPB(rf),
+				},
+			})
+		}
+
+		return nil
+	}
+
+	return pump(ctx, n.hub, client, forward)
+}
+
+// pump forwards messages of the client until the stream is cancelled or
+// the hub is closed. Queued messages are sent before the stream ends on
+// close.
+func pump(ctx context.Context, h *hub.Hub, client *hub.Client,
+	forward func(m hub.Message) error) error {
+
+	for {
+		select {
+		case <-h.Closing():
+			for len(client.Queue()) > 0 {
+				if err := forward(<-client.Queue()); err != nil {
+					return err
+				}
+			}
+			return nil
+		case <-client.Dropped():
+			return status.Error(codes.Unavailable, "slow consumer")
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case m := <-client.Queue():
+			if err := forward(m); err != nil {
+				return err
+			}
+		}
+	}
+}
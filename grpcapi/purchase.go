@@ -0,0 +1,67 @@
+package grpcapi
+
+import (
+	"context"
+
+	"eapteka/ent"
+	eaptekav1 "eapteka/proto/eapteka/v1"
+	"eapteka/store"
+)
+
+type purchase struct {
+	eaptekav1.UnimplementedPurchaseServiceServer
+	store *store.Store
+}
+
+func (p *purchase) ListPurchases(ctx context.Context,
+	req *eaptekav1.ListPurchasesRequest) (*eaptekav1.ListPurchasesResponse,
+	error) {
+
+	ps, err := p.store.Purchases(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &eaptekav1.ListPurchasesResponse{
+		Purchases: make([]*eaptekav1.Purchase, 0, len(ps)),
+	}
+	for _, pr := range ps {
+		resp.Purchases = append(resp.Purchases, purchaseToPB(pr))
+	}
+
+	return resp, nil
+}
+
+func (p *purchase) ListPurchaseProducts(ctx context.Context,
+	req *eaptekav1.ListPurchaseProductsRequest) (
+	*eaptekav1.ListPurchaseProductsResponse, error) {
+
+	ps, err := p.store.PurchaseProducts(ctx, req.PurchaseId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &eaptekav1.ListPurchaseProductsResponse{
+		Products: productsToPB(ps),
+	}, nil
+}
+
+func (p *purchase) CreatePurchase(ctx context.Context,
+	req *eaptekav1.CreatePurchaseRequest) (*eaptekav1.Purchase, error) {
+
+	pps := make([]ent.PurchaseProduct, 0, len(req.Products))
+	for _, pp := range req.Products {
+		pps = append(pps, ent.PurchaseProduct{
+			ProductID: pp.ProductId,
+			Count:     pp.Count,
+			Price:     pp.Price,
+		})
+	}
+
+	pr, err := p.store.CreatePurchase(ctx, req.UserId, pps)
+	if err != nil {
+		return nil, err
+	}
+
+	return purchaseToPB(pr), nil
+}
@@ -0,0 +1,59 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+
+	"eapteka/apierr"
+	"eapteka/ent"
+	"eapteka/hub"
+	eaptekav1 "eapteka/proto/eapteka/v1"
+	"eapteka/recommend"
+)
+
+type recommendation struct {
+	eaptekav1.UnimplementedRecommendationServiceServer
+	engine *recommend.Engine
+	hub    *hub.Hub
+}
+
+func (r *recommendation) ListRecommendations(ctx context.Context,
+	req *eaptekav1.ListRecommendationsRequest) (
+	*eaptekav1.ListRecommendationsResponse, error) {
+
+	rs, err := r.engine.Recommend(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &eaptekav1.ListRecommendationsResponse{
+		Recommendations: make([]*eaptekav1.Recommendation, 0, len(rs)),
+	}
+	for _, rc := range rs {
+		resp.Recommendations = append(resp.Recommendations,
+			recommendationToPB(rc))
+	}
+
+	return resp, nil
+}
+
+func (r *recommendation) StreamRecommendations(
+	req *eaptekav1.StreamRecommendationsRequest,
+	stream eaptekav1.RecommendationService_StreamRecommendationsServer) error {
+
+	if req.UserId == "" {
+		return apierr.Validation("user_id is required",
+			apierr.Field("user_id", "is required"))
+	}
+
+	client := r.hub.Register(recommend.Topic(req.UserId))
+	defer r.hub.Unregister(client)
+
+	return pump(stream.Context(), r.hub, client, func(m hub.Message) error {
+		var rc ent.Recommendation
+		if err := json.Unmarshal(m.Payload, &rc); err != nil {
+			return err
+		}
+		return stream.Send(recommendationToPB(rc))
+	})
+}
@@ -0,0 +1,173 @@
+// Package health reports liveness and readiness of the service. The
+// service is live while the process serves requests and ready while every
+// dependency check passes and it isn't shutting down.
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"eapteka/reqctx"
+)
+
+// Statuses of the service and its checks.
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+)
+
+var ErrShuttingDown = errors.New("shutting down")
+
+// Check returns error if the dependency is unavailable.
+type Check func(ctx context.Context) error
+
+// Report is the JSON body of health endpoints.
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckReport `json:"checks,omitempty"`
+}
+
+type CheckReport struct {
+	Status   string  `json:"status"`
+	Error    string  `json:"error,omitempty"`
+	Duration float64 `json:"duration_seconds"`
+}
+
+type Config struct {
+	// Timeout limits the time of every check.
+	Timeout time.Duration
+}
+
+var ConfigDefault = Config{
+	Timeout: 2 * time.Second,
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+type Health struct {
+	cfg Config
+
+	checks       []namedCheck
+	shuttingDown bool
+	mx           sync.RWMutex
+}
+
+func New(cfg Config) *Health {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = ConfigDefault.Timeout
+	}
+
+	return &Health{cfg: cfg}
+}
+
+// Add adds readiness check with the given name.
+func (h *Health) Add(name string, c Check) {
+	h.mx.Lock()
+	h.checks = append(h.checks, namedCheck{name: name, check: c})
+	h.mx.Unlock()
+}
+
+// Shutdown makes the service not ready, so load balancer stops sending new
+// requests while the running ones complete.
+func (h *Health) Shutdown() {
+	h.mx.Lock()
+	h.shuttingDown = true
+	h.mx.Unlock()
+}
+
+// Ready runs checks concurrently and returns their report, the report is
+// ok if all of them pass.
+func (h *Health) Ready(ctx context.Context) Report {
+	h.mx.RLock()
+	checks := h.checks
+	shuttingDown := h.shuttingDown
+	h.mx.RUnlock()
+
+	r := Report{
+		Status: StatusOK,
+		Checks: make(map[string]CheckReport, len(checks)+1),
+	}
+
+	if shuttingDown {
+		r.Status = StatusUnavailable
+		r.Checks["shutdown"] = CheckReport{
+			Status: StatusUnavailable,
+			Error:  ErrShuttingDown.Error(),
+		}
+	}
+
+	var (
+		wg sync.WaitGroup
+		mx sync.Mutex
+	)
+
+	for _, c := range checks {
+		wg.Add(1)
+		go func(c namedCheck) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, h.cfg.Timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := c.check(ctx)
+
+			cr := CheckReport{
+				Status:   StatusOK,
+				Duration: time.Since(start).Seconds(),
+			}
+			if err != nil {
+				cr.Status = StatusUnavailable
+				cr.Error = err.Error()
+			}
+
+			mx.Lock()
+			defer mx.Unlock()
+			r.Checks[c.name] = cr
+			if err != nil {
+				r.Status = StatusUnavailable
+			}
+		}(c)
+	}
+
+	wg.Wait()
+
+	return r
+}
+
+// LiveHandler responds ok while the process serves requests.
+func (h *Health) LiveHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.JSON(Report{Status: StatusOK})
+	}
+}
+
+// ReadyHandler responds with readiness report, status 503 if the service
+// isn't ready. Checks are bounded by the request context as well.
+func (h *Health) ReadyHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		r := h.Ready(reqctx.Get(c))
+		if r.Status != StatusOK {
+			c.Status(fiber.StatusServiceUnavailable)
+		}
+		return c.JSON(r)
+	}
+}
+
+// Running returns check of the background job which fails if it isn't
+// running.
+func Running(running func() bool) Check {
+	return func(context.Context) error {
+		if !running() {
+			return errors.New("not running")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,75 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"eapteka/reqctx"
+)
+
+func TestReadyHandler(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	failing := func(context.Context) error { return errors.New("down") }
+	blocking := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	tests := []struct {
+		name     string
+		checks   map[string]Check
+		shutdown bool
+		status   int
+	}{
+		{"ok", map[string]Check{"db": ok}, false, 200},
+		{"failing", map[string]Check{"db": ok, "hub": failing}, false, 503},
+		{"shutdown", map[string]Check{"db": ok}, true, 503},
+		{"request timeout", map[string]Check{"db": blocking}, false, 503},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New(Config{Timeout: time.Minute})
+			for name, c := range tt.checks {
+				h.Add(name, c)
+			}
+			if tt.shutdown {
+				h.Shutdown()
+			}
+
+			app := fiber.New()
+			app.Use(reqctx.Middleware(reqctx.Config{
+				Timeout: 50 * time.Millisecond,
+			}))
+			app.Get("/readyz", h.ReadyHandler())
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/readyz", nil),
+				int(time.Second/time.Millisecond))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+
+			var r Report
+			err = json.NewDecoder(resp.Body).Decode(&r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name := range tt.checks {
+				if _, ok := r.Checks[name]; !ok {
+					t.Errorf("check %q isn't reported", name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,345 @@
+// Package hub fans out messages to websocket clients subscribed to topics.
+// Every client has its own buffered queue, client which doesn't keep up is
+// dropped. Messages published with Postgres NOTIFY reach clients of every
+// replica.
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// TopicReconnected is broadcast to clients of this replica when the
+// listener reconnects, messages published meanwhile are lost.
+const TopicReconnected = "hub.reconnected"
+
+// Message is the payload published to the topic.
+type Message struct {
+	Topic   string          `json:"topic"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+type Config struct {
+	// DSN of the Postgres database used to listen for messages published
+	// by any replica. Hub without DSN broadcasts local messages only.
+	DSN string
+
+	// Channel is the Postgres NOTIFY channel.
+	Channel string
+
+	// QueueSize is the number of messages queued for every client.
+	QueueSize int
+
+	// DrainTimeout is the time clients have to send queued messages on
+	// close.
+	DrainTimeout time.Duration
+}
+
+var ConfigDefault = Config{
+	Channel:      "hub",
+	QueueSize:    16,
+	DrainTimeout: 5 * time.Second,
+}
+
+// Client is the connection receiving messages of its topics.
+type Client struct {
+	topics map[string]bool
+
+	queue   chan Message
+	dropped chan struct{}
+}
+
+// Queue returns messages sent to the client.
+func (c *Client) Queue() <-chan Message {
+	return c.queue
+}
+
+// Dropped is closed when the client is unregistered because its queue
+// overflowed.
+func (c *Client) Dropped() <-chan struct{} {
+	return c.dropped
+}
+
+type Hub struct {
+	cfg Config
+
+	listener *pq.Listener
+
+	clients map[*Client]struct{}
+	closing chan struct{}
+	empty   *sync.Cond
+	mx      sync.Mutex
+
+	// drainTimedOut is set when clients aren't drained in DrainTimeout.
+	drainTimedOut bool
+
+	close     chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+func New(cfg Config) *Hub {
+	if cfg.Channel == "" {
+		cfg.Channel = ConfigDefault.Channel
+	}
+	if cfg.QueueSize == 0 {
+		cfg.QueueSize = ConfigDefault.QueueSize
+	}
+	if cfg.DrainTimeout == 0 {
+		cfg.DrainTimeout = ConfigDefault.DrainTimeout
+	}
+
+	h := &Hub{
+		cfg:     cfg,
+		clients: map[*Client]struct{}{},
+		closing: make(chan struct{}),
+		close:   make(chan struct{}),
+	}
+
+	h.empty = sync.NewCond(&h.mx)
+
+	return h
+}
+
+// Start listens for messages published by every replica if DSN is set.
+func (h *Hub) Start() error {
+	if h.cfg.DSN == "" {
+		return nil
+	}
+
+	h.listener = pq.NewListener(h.cfg.DSN, time.Second, time.Minute,
+		func(ev pq.ListenerEventType, err error) {
+			if err != nil {
+				logrus.WithError(err).Warn("hub listener event")
+			}
+		})
+
+	err := h.listener.Listen(h.cfg.Channel)
+	if err != nil {
+		h.listener.Close()
+		return fmt.Errorf("listen: %w", err)
+	}
+
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		h.listenLoop()
+	}()
+
+	return nil
+}
+
+// Close signals clients to send queued messages and disconnect, and waits
+// for them to unregister up to DrainTimeout. Repeated calls wait for the
+// first one to complete.
+func (h *Hub) Close() {
+	h.closeOnce.Do(h.shutdown)
+}
+
+func (h *Hub) shutdown() {
+	close(h.closing)
+
+	// Timer wakes the wait below, so nothing is left waiting for clients
+	// which don't unregister in time
+	timeout := time.AfterFunc(h.cfg.DrainTimeout, func() {
+		h.mx.Lock()
+		h.drainTimedOut = true
+		h.empty.Broadcast()
+		h.mx.Unlock()
+	})
+
+	h.mx.Lock()
+	for len(h.clients) > 0 && !h.drainTimedOut {
+		h.empty.Wait()
+	}
+	drained := len(h.clients) == 0
+	h.mx.Unlock()
+
+	timeout.Stop()
+
+	if !drained {
+		logrus.Warn("hub clients are not drained in time")
+	}
+
+	close(h.close)
+	h.wg.Wait()
+
+	if h.listener != nil {
+		h.listener.Close()
+	}
+}
+
+// Ping checks connection of the listener. Hub without DSN has nothing to
+// check.
+func (h *Hub) Ping() error {
+	if h.listener == nil {
+		return nil
+	}
+	return h.listener.Ping()
+}
+
+// Closing is closed when hub starts closing. Clients should send queued
+// messages and disconnect.
+func (h *Hub) Closing() <-chan struct{} {
+	return h.closing
+}
+
+func (h *Hub) Register(topics ...string) *Client {
+	c := &Client{
+		topics:  map[string]bool{},
+		queue:   make(chan Message, h.cfg.QueueSize),
+		dropped: make(chan struct{}),
+	}
+
+	for _, t := range topics {
+		c.topics[t] = true
+	}
+
+	h.mx.Lock()
+	h.clients[c] = struct{}{}
+	h.mx.Unlock()
+
+	return c
+}
+
+func (h *Hub) Unregister(c *Client) {
+	h.mx.Lock()
+	delete(h.clients, c)
+	if len(h.clients) == 0 {
+		h.empty.Broadcast()
+	}
+	h.mx.Unlock()
+}
+
+// Subscribe adds topics of the client.
+func (h *Hub) Subscribe(c *Client, topics ...string) {
+	h.mx.Lock()
+	for _, t := range topics {
+		c.topics[t] = true
+	}
+	h.mx.Unlock()
+}
+
+// Unsubscribe removes topics of the client.
+func (h *Hub) Unsubscribe(c *Client, topics ...string) {
+	h.mx.Lock()
+	for _, t := range topics {
+		delete(c.topics, t)
+	}
+	h.mx.Unlock()
+}
+
+// Topics returns topics with at least one client.
+func (h *Hub) Topics() []string {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+
+	seen := map[string]bool{}
+	var ts []string
+
+	for c := range h.clients {
+		for t := range c.topics {
+			if !seen[t] {
+				seen[t] = true
+				ts = append(ts, t)
+			}
+		}
+	}
+
+	return ts
+}
+
+// Broadcast queues the payload to clients of this replica subscribed to
+// the topic.
+func (h *Hub) Broadcast(topic string, payload interface{}) error {
+	p, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal payload: %w", err)
+	}
+
+	h.broadcast(Message{Topic: topic, Payload: p})
+
+	return nil
+}
+
+func (h *Hub) broadcast(m Message) {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+
+	for c := range h.clients {
+		if !c.topics[m.Topic] {
+			continue
+		}
+		select {
+		case c.queue <- m:
+		default:
+			logrus.WithField("topic", m.Topic).Warn("hub client dropped")
+			delete(h.clients, c)
+			close(c.dropped)
+			if len(h.clients) == 0 {
+				h.empty.Broadcast()
+			}
+		}
+	}
+}
+
+// Publish sends the payload to clients of every replica subscribed to the
+// topic on transaction commit.
+func (h *Hub) Publish(ctx context.Context, tx *sqlx.Tx, topic string,
+	payload interface{}) error {
+
+	p, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal payload: %w", err)
+	}
+
+	m, err := json.Marshal(Message{Topic: topic, Payload: p})
+	if err != nil {
+		return fmt.Errorf("marshal message: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `select pg_notify($1, $2)`, h.cfg.Channel, string(m))
+	if err != nil {
+		return fmt.Errorf("notify: %w", err)
+	}
+
+	return nil
+}
+
+func (h *Hub) listenLoop() {
+	for {
+		var n *pq.Notification
+
+		select {
+		case <-h.close:
+			return
+		case n = <-h.listener.Notify:
+		case <-time.After(90 * time.Second):
+			go h.listener.Ping()
+			continue
+		}
+
+		// Nil notification is sent after reconnect
+		if n == nil {
+			h.broadcast(Message{Topic: TopicReconnected})
+			continue
+		}
+
+		var m Message
+
+		err := json.Unmarshal([]byte(n.Extra), &m)
+		if err != nil {
+			logrus.WithError(err).Error("failed to unmarshal hub message")
+			continue
+		}
+
+		h.broadcast(m)
+	}
+}
@@ -0,0 +1,147 @@
+package hub
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBroadcast(t *testing.T) {
+	h := New(Config{QueueSize: 1})
+	defer h.Close()
+
+	a := h.Register("a")
+	ab := h.Register("a", "b")
+	defer h.Unregister(a)
+
+	tests := []struct {
+		topic  string
+		client *Client
+		want   bool
+	}{
+		{"a", a, true},
+		{"b", a, false},
+		{"a", ab, true},
+	}
+
+	for _, tt := range tests {
+		err := h.Broadcast(tt.topic, tt.topic)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case m := <-tt.client.Queue():
+			if !tt.want || m.Topic != tt.topic || string(m.Payload) != `"`+tt.topic+`"` {
+				t.Errorf("%s: received %s %s", tt.topic, m.Topic, m.Payload)
+			}
+		default:
+			if tt.want {
+				t.Errorf("%s: nothing received", tt.topic)
+			}
+		}
+	}
+
+	// Queue of ab holds "b" message, so the next one overflows it
+	h.Broadcast("a", nil)
+	h.Broadcast("a", nil)
+
+	select {
+	case <-ab.Dropped():
+	default:
+		t.Error("client with full queue isn't dropped")
+	}
+	for _, topic := range h.Topics() {
+		if topic == "b" {
+			t.Error("topic of dropped client is left")
+		}
+	}
+}
+
+// closeTime returns the time Close takes.
+func closeTime(h *Hub) time.Duration {
+	start := time.Now()
+	h.Close()
+	return time.Since(start)
+}
+
+func TestClose(t *testing.T) {
+	const timeout = 100 * time.Millisecond
+
+	tests := []struct {
+		name       string
+		unregister time.Duration
+		min, max   time.Duration
+	}{
+		{name: "no clients", max: timeout / 2},
+		{name: "drained", unregister: timeout / 5, min: timeout / 5,
+			max: timeout / 2},
+		{name: "timed out", unregister: -1, min: timeout, max: 3 * timeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goroutines := runtime.NumGoroutine()
+
+			h := New(Config{DrainTimeout: timeout})
+
+			if tt.unregister != 0 {
+				c := h.Register("a")
+				go func() {
+					<-h.Closing()
+					if tt.unregister > 0 {
+						time.Sleep(tt.unregister)
+						h.Unregister(c)
+					}
+				}()
+			}
+
+			d := closeTime(h)
+			if d < tt.min || d > tt.max {
+				t.Errorf("Close() took %s, want %s-%s", d, tt.min, tt.max)
+			}
+
+			// Nothing keeps waiting for clients after Close
+			deadline := time.Now().Add(time.Second)
+			for runtime.NumGoroutine() > goroutines &&
+				time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			if n := runtime.NumGoroutine(); n > goroutines {
+				buf := make([]byte, 1<<16)
+				t.Errorf("%d goroutines left after Close:\n%s", n-goroutines,
+					buf[:runtime.Stack(buf, true)])
+			}
+		})
+	}
+}
+
+func TestCloseTwice(t *testing.T) {
+	h := New(Config{DrainTimeout: 100 * time.Millisecond})
+	c := h.Register("a")
+
+	go func() {
+		<-h.Closing()
+		time.Sleep(20 * time.Millisecond)
+		h.Unregister(c)
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.Close()
+		}()
+	}
+	wg.Wait()
+
+	h.Close()
+
+	select {
+	case <-h.Closing():
+	default:
+		t.Error("hub isn't closing after Close")
+	}
+}
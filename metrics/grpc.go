@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// observeRPC counts the call by its status code and observes its latency.
+func observeRPC(method string, start time.Time, err error) {
+	GRPCRequests.WithLabelValues(method, status.Code(err).String()).Inc()
+	GRPCDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+// UnaryServerInterceptor counts gRPC calls and observes their latency.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (
+		interface{}, error) {
+
+		start := time.Now()
+
+		resp, err := handler(ctx, req)
+
+		observeRPC(info.FullMethod, start, err)
+
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor counts gRPC streams and observes their lifetime.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream,
+		info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+		start := time.Now()
+
+		err := handler(srv, ss)
+
+		observeRPC(info.FullMethod, start, err)
+
+		return err
+	}
+}
@@ -0,0 +1,167 @@
+// Package metrics collects Prometheus metrics of the service and exposes
+// them to be scraped from /metrics.
+package metrics
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+
+	"eapteka/apierr"
+)
+
+const namespace = "eapteka"
+
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests by route and status.",
+	}, []string{"method", "route", "status"})
+
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	WebsocketConnections = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "websocket_connections",
+		Help:      "Number of active websocket connections by endpoint.",
+	}, []string{"endpoint"})
+
+	RemindersFired = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reminders_fired_total",
+		Help:      "Number of fired reminders including snoozed ones.",
+	})
+
+	ReminderFireErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reminder_fire_errors_total",
+		Help:      "Number of failed attempts to fire a batch of reminders.",
+	})
+
+	Deliveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "deliveries_total",
+		Help:      "Number of delivery attempts by channel and resulting status.",
+	}, []string{"channel", "status"})
+
+	RecommendationRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "recommendation_runs_total",
+		Help:      "Number of recommendation runs by result.",
+	}, []string{"result"})
+
+	RecommendationDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "recommendation_run_duration_seconds",
+		Help:      "Duration of recommendation runs.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+	})
+
+	SearchDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "search_duration_seconds",
+		Help:      "Latency of search queries by searched entity.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"query"})
+
+	RateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_total",
+		Help:      "Number of requests rejected by rate limit.",
+	}, []string{"limit"})
+
+	GRPCRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_requests_total",
+		Help:      "Number of gRPC calls by method and status code.",
+	}, []string{"method", "code"})
+
+	GRPCDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_request_duration_seconds",
+		Help:      "gRPC call latency by method, stream lifetime for streams.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+)
+
+// Registry holds metrics of the service along with Go runtime and process
+// metrics.
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPDuration,
+		WebsocketConnections,
+		RemindersFired,
+		ReminderFireErrors,
+		Deliveries,
+		RecommendationRuns,
+		RecommendationDuration,
+		SearchDuration,
+		RateLimited,
+		GRPCRequests,
+		GRPCDuration,
+	)
+}
+
+// RegisterDB adds connection pool stats of the database.
+func RegisterDB(db *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// Since observes seconds elapsed since start. It is used as
+// defer metrics.Since(h, time.Now()).
+func Since(o prometheus.Observer, start time.Time) {
+	o.Observe(time.Since(start).Seconds())
+}
+
+// Middleware counts requests and observes their latency labeled by matched
+// route pattern rather than path, so path parameters don't blow up label
+// cardinality.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = apierr.Of(c, err).Status
+		}
+
+		method := c.Method()
+		route := c.Route().Path
+
+		HTTPRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+		HTTPDuration.WithLabelValues(method, route).Observe(
+			time.Since(start).Seconds())
+
+		return err
+	}
+}
+
+// Handler serves metrics in Prometheus exposition format.
+func Handler() fiber.Handler {
+	h := fasthttpadaptor.NewFastHTTPHandler(
+		promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+
+	return func(c *fiber.Ctx) error {
+		h(c.Context())
+		return nil
+	}
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"eapteka/apierr"
+)
+
+func TestMiddlewareStatus(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: apierr.Handler})
+	app.Use(Middleware())
+
+	app.Get("/ok", func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+	app.Get("/not_found", func(c *fiber.Ctx) error {
+		return apierr.NotFound("product not found")
+	})
+	app.Get("/rate_limited", func(c *fiber.Ctx) error {
+		return apierr.RateLimited("too many requests")
+	})
+	app.Get("/internal", func(c *fiber.Ctx) error {
+		return errors.New("boom")
+	})
+
+	tests := []struct {
+		route  string
+		status string
+	}{
+		{"/ok", "200"},
+		{"/not_found", "404"},
+		{"/rate_limited", "429"},
+		{"/internal", "500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.route, func(t *testing.T) {
+			_, err := app.Test(httptest.NewRequest("GET", tt.route, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c := HTTPRequests.WithLabelValues("GET", tt.route, tt.status)
+			if n := testutil.ToFloat64(c); n != 1 {
+				t.Errorf("requests with status %s = %v, want 1", tt.status, n)
+			}
+		})
+	}
+}
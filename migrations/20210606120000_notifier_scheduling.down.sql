@@ -0,0 +1,3 @@
+drop table reminder;
+
+alter table notifier drop column next_fire_at;
@@ -0,0 +1,11 @@
+alter table notifier add column next_fire_at timestamp with time zone;
+
+create index notifier_next_fire_at_idx on notifier (next_fire_at);
+
+create table reminder (
+    id bigserial primary key,
+    notifier_id bigint not null references notifier (id) on delete cascade,
+    scheduled_at timestamp with time zone not null,
+    created_at timestamp with time zone not null default now(),
+    unique (notifier_id, scheduled_at)
+);
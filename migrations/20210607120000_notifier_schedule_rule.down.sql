@@ -0,0 +1 @@
+alter table notifier drop column rule;
@@ -0,0 +1 @@
+alter table notifier add column rule jsonb;
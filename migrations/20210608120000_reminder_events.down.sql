@@ -0,0 +1,4 @@
+drop table reminder_event;
+
+alter table reminder drop column snooze_fired;
+alter table reminder drop column snoozed_until;
@@ -0,0 +1,15 @@
+alter table reminder add column snoozed_until timestamp with time zone;
+alter table reminder add column snooze_fired boolean not null default false;
+
+create index reminder_snoozed_until_idx on reminder (snoozed_until)
+    where not snooze_fired;
+
+create table reminder_event (
+    id bigserial primary key,
+    reminder_id bigint not null references reminder (id) on delete cascade,
+    type text not null check (type in ('taken', 'snoozed', 'skipped')),
+    snooze_until timestamp with time zone,
+    created_at timestamp with time zone not null default now()
+);
+
+create index reminder_event_reminder_id_idx on reminder_event (reminder_id);
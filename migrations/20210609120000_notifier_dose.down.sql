@@ -0,0 +1,4 @@
+alter table notifier drop column paused;
+alter table notifier drop column notes;
+alter table notifier drop column dose_unit;
+alter table notifier drop column dose_amount;
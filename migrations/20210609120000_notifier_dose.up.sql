@@ -0,0 +1,4 @@
+alter table notifier add column dose_amount double precision not null default 0;
+alter table notifier add column dose_unit text not null default '';
+alter table notifier add column notes text not null default '';
+alter table notifier add column paused boolean not null default false;
@@ -0,0 +1,4 @@
+drop table delivery;
+drop table delivery_channel;
+
+alter table notifier drop column user_id;
@@ -0,0 +1,29 @@
+alter table notifier add column user_id text not null default '';
+
+create table delivery_channel (
+    id bigserial primary key,
+    user_id text not null,
+    type text not null check (type in ('email', 'sms', 'webpush', 'webhook')),
+    address text not null,
+    params jsonb not null default '{}',
+    enabled boolean not null default true,
+    created_at timestamp with time zone not null default now()
+);
+
+create index delivery_channel_user_id_idx on delivery_channel (user_id);
+
+create table delivery (
+    id bigserial primary key,
+    reminder_id bigint not null references reminder (id) on delete cascade,
+    channel_id bigint not null references delivery_channel (id) on delete cascade,
+    status text not null default 'pending'
+        check (status in ('pending', 'sent', 'failed')),
+    attempts integer not null default 0,
+    next_attempt_at timestamp with time zone not null default now(),
+    locked_until timestamp with time zone,
+    last_error text not null default '',
+    created_at timestamp with time zone not null default now()
+);
+
+create index delivery_pending_idx on delivery (next_attempt_at)
+    where status = 'pending';
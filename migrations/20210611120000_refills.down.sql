@@ -0,0 +1,12 @@
+delete from delivery where reminder_id is null;
+
+alter table delivery drop constraint delivery_subject_check;
+alter table delivery drop column refill_id;
+alter table delivery alter column reminder_id set not null;
+
+drop table refill;
+
+drop index purchase_user_id_idx;
+
+alter table purchase drop column user_id;
+alter table product drop column pack_size;
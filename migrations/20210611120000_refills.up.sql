@@ -0,0 +1,21 @@
+alter table product add column pack_size integer not null default 0;
+alter table purchase add column user_id text not null default '';
+
+create index purchase_user_id_idx on purchase (user_id);
+
+create table refill (
+    id bigserial primary key,
+    user_id text not null,
+    product_id bigint not null references product (id),
+    purchase_id bigint not null references purchase (id),
+    run_out_at timestamp with time zone not null,
+    remaining double precision not null,
+    reorder_purchase_id bigint references purchase (id),
+    created_at timestamp with time zone not null default now(),
+    unique (user_id, product_id, purchase_id)
+);
+
+alter table delivery alter column reminder_id drop not null;
+alter table delivery add column refill_id bigint references refill (id) on delete cascade;
+alter table delivery add constraint delivery_subject_check
+    check ((reminder_id is null) != (refill_id is null));
@@ -0,0 +1,7 @@
+create table rate_limit (
+    key text primary key,
+    tokens double precision not null,
+    updated_at timestamp with time zone not null default now()
+);
+
+create index rate_limit_updated_at_idx on rate_limit (updated_at);
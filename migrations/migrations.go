@@ -1,11 +1,14 @@
 package migrations
 
 import (
+	"context"
 	"database/sql"
 	"embed"
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
@@ -58,3 +61,60 @@ func Migrate(dsn string) error {
 
 	return nil
 }
+
+// Latest returns version of the newest embedded migration.
+func Latest() (uint, error) {
+	es, err := migrations.ReadDir(".")
+	if err != nil {
+		return 0, fmt.Errorf("read migrations: %w", err)
+	}
+
+	var latest uint
+
+	for _, e := range es {
+		i := strings.IndexByte(e.Name(), '_')
+		if i < 0 {
+			continue
+		}
+		n, err := strconv.ParseUint(e.Name()[:i], 10, 64)
+		if err != nil {
+			continue
+		}
+		if uint(n) > latest {
+			latest = uint(n)
+		}
+	}
+
+	return latest, nil
+}
+
+// Check returns error if the database schema isn't migrated to the latest
+// embedded migration or the last migration failed.
+func Check(ctx context.Context, db *sql.DB) error {
+	latest, err := Latest()
+	if err != nil {
+		return err
+	}
+
+	var (
+		version uint
+		dirty   bool
+	)
+
+	err = db.QueryRowContext(ctx, `
+		select version, dirty from schema_migrations
+	`).Scan(&version, &dirty)
+	if err != nil {
+		return fmt.Errorf("select migration version: %w", err)
+	}
+
+	if dirty {
+		return fmt.Errorf("migration %d is dirty", version)
+	}
+
+	if version != latest {
+		return fmt.Errorf("migration version %d, expected %d", version, latest)
+	}
+
+	return nil
+}
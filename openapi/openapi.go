@@ -0,0 +1,95 @@
+// Package openapi serves OpenAPI 3 document of the API and Swagger UI to
+// browse it. The document also describes messages of websockets and is
+// the source of the generated client package.
+package openapi
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+
+	"github.com/gofiber/fiber/v2"
+	"gopkg.in/yaml.v2"
+)
+
+//go:embed openapi.yaml
+var fs embed.FS
+
+// spec is the document converted to JSON once on start.
+var spec []byte
+
+func init() {
+	data, err := fs.ReadFile("openapi.yaml")
+	if err != nil {
+		panic(err)
+	}
+
+	spec, err = toJSON(data)
+	if err != nil {
+		panic(fmt.Errorf("convert openapi.yaml: %w", err))
+	}
+}
+
+// toJSON converts YAML document to JSON. yaml.v2 decodes mappings with
+// interface{} keys, which encoding/json doesn't support.
+func toJSON(data []byte) ([]byte, error) {
+	var v interface{}
+
+	err := yaml.Unmarshal(data, &v)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(stringKeys(v))
+}
+
+func stringKeys(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = stringKeys(e)
+		}
+		return m
+	case []interface{}:
+		for i, e := range v {
+			v[i] = stringKeys(e)
+		}
+	}
+	return v
+}
+
+// Handler serves the document in JSON.
+func Handler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Type("json")
+		return c.Send(spec)
+	}
+}
+
+var uiPage = template.Must(template.New("ui").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>eapteka API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@3.51.1/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@3.51.1/swagger-ui-bundle.js"></script>
+  <script>
+    SwaggerUIBundle({url: {{.}}, dom_id: "#swagger-ui"});
+  </script>
+</body>
+</html>
+`))
+
+// UIHandler serves Swagger UI page of the document at specURL. UI assets
+// are loaded from unpkg CDN.
+func UIHandler(specURL string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Type("html")
+		return uiPage.Execute(c, specURL)
+	}
+}
@@ -0,0 +1,336 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gofiber/fiber/v2"
+
+	"eapteka/apierr"
+	"eapteka/ent"
+)
+
+// mainFile registers routes of the API.
+const mainFile = "../cmd/eapteka/main.go"
+
+// undocumented are the routes serving the document itself.
+var undocumented = map[string]bool{
+	"GET /openapi.json": true,
+	"GET /docs":         true,
+}
+
+// load loads the served document.
+func load(t *testing.T) *openapi3.T {
+	t.Helper()
+
+	doc, err := openapi3.NewLoader().LoadFromData(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = doc.Validate(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return doc
+}
+
+var routeParam = regexp.MustCompile(`:([a-z_]+)`)
+
+// routes returns "METHOD /path" of the api routes registered in main.go,
+// path parameters written as in the document.
+func routes(t *testing.T) []string {
+	t.Helper()
+
+	f, err := parser.ParseFile(token.NewFileSet(), mainFile, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rs []string
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if x, ok := sel.X.(*ast.Ident); !ok || x.Name != "api" {
+			return true
+		}
+		lit, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return true
+		}
+
+		switch sel.Sel.Name {
+		case "Get", "Post", "Put", "Patch", "Delete":
+			p, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rs = append(rs, strings.ToUpper(sel.Sel.Name)+" "+
+				routeParam.ReplaceAllString(p, "{$1}"))
+		}
+		return true
+	})
+
+	return rs
+}
+
+func TestRoutes(t *testing.T) {
+	doc := load(t)
+
+	documented := map[string]bool{}
+	for p, item := range doc.Paths {
+		for m := range item.Operations() {
+			documented[m+" "+p] = true
+		}
+	}
+
+	served := map[string]bool{}
+	for _, r := range routes(t) {
+		if undocumented[r] {
+			continue
+		}
+		served[r] = true
+		if !documented[r] {
+			t.Errorf("route %s isn't documented", r)
+		}
+	}
+	if len(served) == 0 {
+		t.Fatalf("no routes found in %s", mainFile)
+	}
+
+	for r := range documented {
+		if !served[r] {
+			t.Errorf("documented %s isn't served", r)
+		}
+	}
+}
+
+var (
+	now       = time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC)
+	later     = now.Add(time.Hour)
+	id        = int64(7)
+	substance = "Парацетамол"
+)
+
+var product = ent.Product{
+	ID:            1,
+	SubstanceID:   2,
+	Name:          "Парацетамол 500 мг",
+	Description:   "Таблетки",
+	Price:         50,
+	ImageID:       3,
+	SKU:           4,
+	PackSize:      20,
+	SubstanceName: &substance,
+}
+
+var notifier = ent.Notifier{
+	ID:        1,
+	UserID:    "u1",
+	ProductID: 1,
+	Schedule:  []string{"08:00", "20:00"},
+	Rule: &ent.ScheduleRule{
+		Weekdays:   []time.Weekday{time.Monday, time.Friday},
+		StartAt:    &now,
+		CourseDays: 10,
+	},
+	DoseAmount:  1,
+	DoseUnit:    "tablet",
+	ProductName: product.Name,
+}
+
+var reminder = ent.Reminder{ID: 1, NotifierID: 1, ScheduledAt: now,
+	CreatedAt: now, SnoozedUntil: &later}
+
+var channel = ent.DeliveryChannel{ID: 1, UserID: "u1",
+	Type: ent.ChannelWebPush, Address: "https://push.example.com/1",
+	Params:  ent.ChannelParams{"p256dh": "key", "auth": "secret"},
+	Enabled: true, CreatedAt: now}
+
+var refill = ent.Refill{ID: 1, UserID: "u1", ProductID: 1, PurchaseID: 2,
+	RunOutAt: later, Remaining: 2.5, ReorderPurchaseID: &id, CreatedAt: now,
+	ProductName: product.Name}
+
+// samples are responses of the operations with JSON body by "METHOD /path".
+var samples = map[string]interface{}{
+	"GET /query": ent.SearchResult{
+		Products:   []ent.Product{product, {ID: 5, Name: "Без вещества"}},
+		Substances: []ent.Substance{{ID: 2, Name: substance}},
+	},
+	"GET /products":              []ent.Product{product},
+	"GET /products/{product_id}": product,
+	"GET /substances": []ent.Substance{
+		{ID: 2, Name: substance, Products: []ent.Product{product}},
+	},
+	"GET /experts/{substance_id}": ent.Expert{ID: 1, SubstanceID: 2,
+		ExpertName: "Иванов", Title: "Врач", Text: "Принимать после еды"},
+	"GET /purchases": []ent.Purchase{{ID: 1, UserID: "u1", CreatedAt: now,
+		Products: []ent.Product{{ID: 1, Count: 2, PurchasePrice: 45}}}},
+	"POST /purchases": ent.Purchase{ID: 1, UserID: "u1", CreatedAt: now},
+	"GET /purchase_products": []ent.Product{
+		{ID: 1, SubstanceID: 2, Name: product.Name, Count: 2,
+			PurchasePrice: 45},
+	},
+	"GET /notifiers": []ent.Notifier{notifier, {ID: 2, UserID: "u2",
+		ProductID: 1, Schedule: []string{},
+		Rule: &ent.ScheduleRule{EveryHours: 8, StartAt: &now}}},
+	"POST /notifiers":       notifier,
+	"GET /notifiers/{id}":   notifier,
+	"PUT /notifiers/{id}":   notifier,
+	"PATCH /notifiers/{id}": notifier,
+	"GET /notifiers/{id}/adherence": []ent.Adherence{
+		{Period: now, Total: 10, Taken: 7, Skipped: 1, Missed: 2},
+	},
+	"GET /reminders": []ent.Reminder{reminder},
+	"GET /reminders/{id}/events": []ent.ReminderEvent{{ID: 1, ReminderID: 1,
+		Type: ent.ReminderSnoozed, SnoozeUntil: &later, CreatedAt: now}},
+	"POST /reminders/{id}/events": ent.ReminderEvent{ID: 1, ReminderID: 1,
+		Type: ent.ReminderTaken, CreatedAt: now},
+	"GET /reminders/{id}/deliveries": []ent.Delivery{{ID: 1,
+		ReminderID: &id, ChannelID: 1, Status: ent.DeliveryFailed,
+		Attempts: 3, NextAttemptAt: later, LastError: "timeout",
+		CreatedAt: now}},
+	"GET /users/{user_id}/channels":  []ent.DeliveryChannel{channel},
+	"POST /users/{user_id}/channels": channel,
+	"PATCH /channels/{id}":           channel,
+	"GET /webpush/vapid_public_key": map[string]string{
+		"public_key": "BOr3...",
+	},
+	"GET /users/{user_id}/refills": []ent.RefillPrediction{
+		{UserID: "u1", ProductID: 1, ProductName: product.Name,
+			PurchaseID: 2, Remaining: 3, DoseUnit: "tablet", RunOutAt: &later},
+		{UserID: "u1", ProductID: 2, ProductName: "Витамин D",
+			PurchaseID: 3, Remaining: 30, DoseUnit: "capsule"},
+	},
+	"GET /users/{user_id}/refills/history": []ent.Refill{refill},
+	"POST /refills/{id}/reorder":           ent.Purchase{ID: 7, UserID: "u1", CreatedAt: now},
+	"GET /recommendations": []ent.Recommendation{
+		{ProductID: 1, Score: 0.75, Reason: "bought_together", Product: &product},
+	},
+}
+
+// jsonValue converts v to the value decoded from its JSON.
+func jsonValue(t *testing.T, v interface{}) interface{} {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var jv interface{}
+	err = json.Unmarshal(data, &jv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return jv
+}
+
+func TestResponses(t *testing.T) {
+	doc := load(t)
+
+	var ops []string
+	for p, item := range doc.Paths {
+		for m, op := range item.Operations() {
+			for code, r := range op.Responses {
+				if !strings.HasPrefix(code, "2") ||
+					r.Value.Content.Get(fiber.MIMEApplicationJSON) == nil {
+					continue
+				}
+				ops = append(ops, m+" "+p+" "+code)
+			}
+		}
+	}
+	sort.Strings(ops)
+
+	for _, op := range ops {
+		op := op
+		t.Run(op, func(t *testing.T) {
+			f := strings.Fields(op)
+
+			sample, ok := samples[f[0]+" "+f[1]]
+			if !ok {
+				t.Fatal("no sample response")
+			}
+
+			schema := doc.Paths[f[1]].GetOperation(f[0]).Responses[f[2]].Value.
+				Content.Get(fiber.MIMEApplicationJSON).Schema.Value
+
+			err := schema.VisitJSON(jsonValue(t, sample),
+				openapi3.VisitAsResponse(), openapi3.MultiErrors())
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestErrorResponses(t *testing.T) {
+	schema := load(t).Components.Schemas["Error"].Value
+
+	tests := []error{
+		errors.New("boom"),
+		apierr.NotFound("notifier not found"),
+		apierr.Conflict("channel exists"),
+		apierr.Unauthorized("invalid token"),
+		apierr.RateLimited("too many requests"),
+		apierr.Validation("invalid notifier",
+			apierr.Field("schedule", "invalid time")),
+		apierr.InvalidField("product_id", errors.New("must be integer")),
+		fiber.ErrMethodNotAllowed,
+	}
+
+	for _, err := range tests {
+		t.Run(err.Error(), func(t *testing.T) {
+			app := fiber.New(fiber.Config{ErrorHandler: apierr.Handler})
+			app.Get("/", func(c *fiber.Ctx) error {
+				return err
+			})
+
+			resp, rerr := app.Test(httptest.NewRequest("GET", "/", nil))
+			if rerr != nil {
+				t.Fatal(rerr)
+			}
+			defer resp.Body.Close()
+
+			body, rerr := ioutil.ReadAll(resp.Body)
+			if rerr != nil {
+				t.Fatal(rerr)
+			}
+
+			var v interface{}
+			rerr = json.Unmarshal(body, &v)
+			if rerr != nil {
+				t.Fatalf("%v: %s", rerr, body)
+			}
+
+			rerr = schema.VisitJSON(v, openapi3.VisitAsResponse(),
+				openapi3.MultiErrors())
+			if rerr != nil {
+				t.Errorf("%v: %s", rerr, body)
+			}
+		})
+	}
+}
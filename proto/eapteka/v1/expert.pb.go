@@ -0,0 +1,258 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        (unknown)
+// source: eapteka/v1/expert.proto
+
+package eaptekav1
+
+import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Expert struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id          int64  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	SubstanceId int64  `protobuf:"varint,2,opt,name=substance_id,json=substanceId,proto3" json:"substance_id,omitempty"`
+	ExpertName  string `protobuf:"bytes,3,opt,name=expert_name,json=expertName,proto3" json:"expert_name,omitempty"`
+	Title       string `protobuf:"bytes,4,opt,name=title,proto3" json:"title,omitempty"`
+	Text        string `protobuf:"bytes,5,opt,name=text,proto3" json:"text,omitempty"`
+}
+
+func (x *Expert) Reset() {
+	*x = Expert{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_eapteka_v1_expert_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Expert) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Expert) ProtoMessage() {}
+
+func (x *Expert) ProtoReflect() protoreflect.Message {
+	mi := &file_eapteka_v1_expert_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Expert.ProtoReflect.Descriptor instead.
+func (*Expert) Descriptor() ([]byte, []int) {
+	return file_eapteka_v1_expert_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Expert) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Expert) GetSubstanceId() int64 {
+	if x != nil {
+		return x.SubstanceId
+	}
+	return 0
+}
+
+func (x *Expert) GetExpertName() string {
+	if x != nil {
+		return x.ExpertName
+	}
+	return ""
+}
+
+func (x *Expert) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Expert) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+type GetExpertRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SubstanceId int64 `protobuf:"varint,1,opt,name=substance_id,json=substanceId,proto3" json:"substance_id,omitempty"`
+}
+
+func (x *GetExpertRequest) Reset() {
+	*x = GetExpertRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_eapteka_v1_expert_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetExpertRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetExpertRequest) ProtoMessage() {}
+
+func (x *GetExpertRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_eapteka_v1_expert_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetExpertRequest.ProtoReflect.Descriptor instead.
+func (*GetExpertRequest) Descriptor() ([]byte, []int) {
+	return file_eapteka_v1_expert_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetExpertRequest) GetSubstanceId() int64 {
+	if x != nil {
+		return x.SubstanceId
+	}
+	return 0
+}
+
+var File_eapteka_v1_expert_proto protoreflect.FileDescriptor
+
+var file_eapteka_v1_expert_proto_rawDesc = []byte{
+	0x0a, 0x17, 0x65, 0x61, 0x70, 0x74, 0x65, 0x6b, 0x61, 0x2f, 0x76, 0x31, 0x2f, 0x65, 0x78, 0x70,
+	0x65, 0x72, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0a, 0x65, 0x61, 0x70, 0x74, 0x65,
+	0x6b, 0x61, 0x2e, 0x76, 0x31, 0x1a, 0x1c, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x61, 0x70,
+	0x69, 0x2f, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x22, 0x86, 0x01, 0x0a, 0x06, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x12, 0x0e,
+	0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x12, 0x21,
+	0x0a, 0x0c, 0x73, 0x75, 0x62, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x73, 0x75, 0x62, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x49,
+	0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x5f, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x4e, 0x61,
+	0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x78, 0x74,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x65, 0x78, 0x74, 0x22, 0x35, 0x0a, 0x10,
+	0x47, 0x65, 0x74, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x21, 0x0a, 0x0c, 0x73, 0x75, 0x62, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x73, 0x75, 0x62, 0x73, 0x74, 0x61, 0x6e, 0x63,
+	0x65, 0x49, 0x64, 0x32, 0x76, 0x0a, 0x0d, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x53, 0x65, 0x72,
+	0x76, 0x69, 0x63, 0x65, 0x12, 0x65, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x45, 0x78, 0x70, 0x65, 0x72,
+	0x74, 0x12, 0x1c, 0x2e, 0x65, 0x61, 0x70, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x47,
+	0x65, 0x74, 0x45, 0x78, 0x70, 0x65, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x12, 0x2e, 0x65, 0x61, 0x70, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78, 0x70,
+	0x65, 0x72, 0x74, 0x22, 0x26, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x20, 0x12, 0x1e, 0x2f, 0x61, 0x70,
+	0x69, 0x2f, 0x76, 0x31, 0x2f, 0x65, 0x78, 0x70, 0x65, 0x72, 0x74, 0x73, 0x2f, 0x7b, 0x73, 0x75,
+	0x62, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x7d, 0x42, 0x24, 0x5a, 0x22, 0x65,
+	0x61, 0x70, 0x74, 0x65, 0x6b, 0x61, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x65, 0x61, 0x70,
+	0x74, 0x65, 0x6b, 0x61, 0x2f, 0x76, 0x31, 0x3b, 0x65, 0x61, 0x70, 0x74, 0x65, 0x6b, 0x61, 0x76,
+	0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_eapteka_v1_expert_proto_rawDescOnce sync.Once
+	file_eapteka_v1_expert_proto_rawDescData = file_eapteka_v1_expert_proto_rawDesc
+)
+
+func file_eapteka_v1_expert_proto_rawDescGZIP() []byte {
+	file_eapteka_v1_expert_proto_rawDescOnce.Do(func() {
+		file_eapteka_v1_expert_proto_rawDescData = protoimpl.X.CompressGZIP(file_eapteka_v1_expert_proto_rawDescData)
+	})
+	return file_eapteka_v1_expert_proto_rawDescData
+}
+
+var file_eapteka_v1_expert_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_eapteka_v1_expert_proto_goTypes = []interface{}{
+	(*Expert)(nil),           // 0: eapteka.v1.Expert
+	(*GetExpertRequest)(nil), // 1: eapteka.v1.GetExpertRequest
+}
+var file_eapteka_v1_expert_proto_depIdxs = []int32{
+	1, // 0: eapteka.v1.ExpertService.GetExpert:input_type -> eapteka.v1.GetExpertRequest
+	0, // 1: eapteka.v1.ExpertService.GetExpert:output_type -> eapteka.v1.Expert
+	1, // [1:2] is the sub-list for method output_type
+	0, // [0:1] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_eapteka_v1_expert_proto_init() }
+func file_eapteka_v1_expert_proto_init() {
+	if File_eapteka_v1_expert_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_eapteka_v1_expert_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Expert); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_eapteka_v1_expert_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetExpertRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_eapteka_v1_expert_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_eapteka_v1_expert_proto_goTypes,
+		DependencyIndexes: file_eapteka_v1_expert_proto_depIdxs,
+		MessageInfos:      file_eapteka_v1_expert_proto_msgTypes,
+	}.Build()
+	File_eapteka_v1_expert_proto = out.File
+	file_eapteka_v1_expert_proto_rawDesc = nil
+	file_eapteka_v1_expert_proto_goTypes = nil
+	file_eapteka_v1_expert_proto_depIdxs = nil
+}
@@ -0,0 +1,101 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package eaptekav1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// ExpertServiceClient is the client API for ExpertService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ExpertServiceClient interface {
+	GetExpert(ctx context.Context, in *GetExpertRequest, opts ...grpc.CallOption) (*Expert, error)
+}
+
+type expertServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewExpertServiceClient(cc grpc.ClientConnInterface) ExpertServiceClient {
+	return &expertServiceClient{cc}
+}
+
+func (c *expertServiceClient) GetExpert(ctx context.Context, in *GetExpertRequest, opts ...grpc.CallOption) (*Expert, error) {
+	out := new(Expert)
+	err := c.cc.Invoke(ctx, "/eapteka.v1.ExpertService/GetExpert", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ExpertServiceServer is the server API for ExpertService service.
+// All implementations must embed UnimplementedExpertServiceServer
+// for forward compatibility
+type ExpertServiceServer interface {
+	GetExpert(context.Context, *GetExpertRequest) (*Expert, error)
+	mustEmbedUnimplementedExpertServiceServer()
+}
+
+// UnimplementedExpertServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedExpertServiceServer struct {
+}
+
+func (UnimplementedExpertServiceServer) GetExpert(context.Context, *GetExpertRequest) (*Expert, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetExpert not implemented")
+}
+func (UnimplementedExpertServiceServer) mustEmbedUnimplementedExpertServiceServer() {}
+
+// UnsafeExpertServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ExpertServiceServer will
+// result in compilation errors.
+type UnsafeExpertServiceServer interface {
+	mustEmbedUnimplementedExpertServiceServer()
+}
+
+func RegisterExpertServiceServer(s grpc.ServiceRegistrar, srv ExpertServiceServer) {
+	s.RegisterService(&ExpertService_ServiceDesc, srv)
+}
+
+func _ExpertService_GetExpert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetExpertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExpertServiceServer).GetExpert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/eapteka.v1.ExpertService/GetExpert",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExpertServiceServer).GetExpert(ctx, req.(*GetExpertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ExpertService_ServiceDesc is the grpc.ServiceDesc for ExpertService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ExpertService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "eapteka.v1.ExpertService",
+	HandlerType: (*ExpertServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetExpert",
+			Handler:    _ExpertService_GetExpert_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "eapteka/v1/expert.proto",
+}
@@ -0,0 +1,7 @@
+// Package eaptekav1 is the generated code of the gRPC API.
+package eaptekav1
+
+// Messages and services are generated by protoc with protoc-gen-go v1.27.1
+// and protoc-gen-go-grpc v1.1.0, run go generate ./proto/... after changing
+// .proto files.
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative eapteka/v1/catalog.proto eapteka/v1/expert.proto eapteka/v1/notifier.proto eapteka/v1/purchase.proto eapteka/v1/recommendation.proto
@@ -0,0 +1,1313 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        (unknown)
+// source: eapteka/v1/notifier.proto
+
+package eaptekav1
+
+import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Notifier struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id        int64  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId    string `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ProductId int64  `protobuf:"varint,3,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	// Daily times of reminders as "HH:MM".
+	Schedule    []string      `protobuf:"bytes,4,rep,name=schedule,proto3" json:"schedule,omitempty"`
+	Rule        *ScheduleRule `protobuf:"bytes,5,opt,name=rule,proto3" json:"rule,omitempty"`
+	DoseAmount  float64       `protobuf:"fixed64,6,opt,name=dose_amount,json=doseAmount,proto3" json:"dose_amount,omitempty"`
+	DoseUnit    string        `protobuf:"bytes,7,opt,name=dose_unit,json=doseUnit,proto3" json:"dose_unit,omitempty"`
+	Notes       string        `protobuf:"bytes,8,opt,name=notes,proto3" json:"notes,omitempty"`
+	Paused      bool          `protobuf:"varint,9,opt,name=paused,proto3" json:"paused,omitempty"`
+	ProductName string        `protobuf:"bytes,10,opt,name=product_name,json=productName,proto3" json:"product_name,omitempty"`
+	// iCalendar recurrence rule to import schedule from, input only.
+	Rrule string `protobuf:"bytes,11,opt,name=rrule,proto3" json:"rrule,omitempty"`
+}
+
+func (x *Notifier) Reset() {
+	*x = Notifier{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_eapteka_v1_notifier_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Notifier) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Notifier) ProtoMessage() {}
+
+func (x *Notifier) ProtoReflect() protoreflect.Message {
+	mi := &file_eapteka_v1_notifier_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Notifier.ProtoReflect.Descriptor instead.
+func (*Notifier) Descriptor() ([]byte, []int) {
+	return file_eapteka_v1_notifier_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Notifier) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Notifier) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Notifier) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *Notifier) GetSchedule() []string {
+	if x != nil {
+		return x.Schedule
+	}
+	return nil
+}
+
+func (x *Notifier) GetRule() *ScheduleRule {
+	if x != nil {
+		return x.Rule
+	}
+	return nil
+}
+
+func (x *Notifier) GetDoseAmount() float64 {
+	if x != nil {
+		return x.DoseAmount
+	}
+	return 0
+}
+
+func (x *Notifier) GetDoseUnit() string {
+	if x != nil {
+		return x.DoseUnit
+	}
+	return ""
+}
+
+func (x *Notifier) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+func (x *Notifier) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+func (x *Notifier) GetProductName() string {
+	if x != nil {
+		return x.ProductName
+	}
+	return ""
+}
+
+func (x *Notifier) GetRrule() string {
+	if x != nil {
+		return x.Rrule
+	}
+	return ""
+}
+
+// ScheduleRule limits and extends notifier daily schedule.
+type ScheduleRule struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Weekdays the notifier fires on from 0 (Sunday) to 6, every day if
+	// empty.
+	Weekdays []int32 `protobuf:"varint,1,rep,packed,name=weekdays,proto3" json:"weekdays,omitempty"`
+	// Fire every N hours starting from start_at instead of daily schedule
+	// times.
+	EveryHours int32                  `protobuf:"varint,2,opt,name=every_hours,json=everyHours,proto3" json:"every_hours,omitempty"`
+	StartAt    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=start_at,json=startAt,proto3" json:"start_at,omitempty"`
+	EndAt      *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=end_at,json=endAt,proto3" json:"end_at,omitempty"`
+	// Course length in days starting from start_at.
+	CourseDays int32 `protobuf:"varint,5,opt,name=course_days,json=courseDays,proto3" json:"course_days,omitempty"`
+	// Total number of reminders to fire.
+	PillCount int32 `protobuf:"varint,6,opt,name=pill_count,json=pillCount,proto3" json:"pill_count,omitempty"`
+}
+
+func (x *ScheduleRule) Reset() {
+	*x = ScheduleRule{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_eapteka_v1_notifier_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScheduleRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduleRule) ProtoMessage() {}
+
+func (x *ScheduleRule) ProtoReflect() protoreflect.Message {
+	mi := &file_eapteka_v1_notifier_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduleRule.ProtoReflect.Descriptor instead.
+func (*ScheduleRule) Descriptor() ([]byte, []int) {
+	return file_eapteka_v1_notifier_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ScheduleRule) GetWeekdays() []int32 {
+	if x != nil {
+		return x.Weekdays
+	}
+	return nil
+}
+
+func (x *ScheduleRule) GetEveryHours() int32 {
+	if x != nil {
+		return x.EveryHours
+	}
+	return 0
+}
+
+func (x *ScheduleRule) GetStartAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartAt
+	}
+	return nil
+}
+
+func (x *ScheduleRule) GetEndAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndAt
+	}
+	return nil
+}
+
+func (x *ScheduleRule) GetCourseDays() int32 {
+	if x != nil {
+		return x.CourseDays
+	}
+	return 0
+}
+
+func (x *ScheduleRule) GetPillCount() int32 {
+	if x != nil {
+		return x.PillCount
+	}
+	return 0
+}
+
+type ListNotifiersRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ListNotifiersRequest) Reset() {
+	*x = ListNotifiersRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_eapteka_v1_notifier_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListNotifiersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNotifiersRequest) ProtoMessage() {}
+
+func (x *ListNotifiersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_eapteka_v1_notifier_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNotifiersRequest.ProtoReflect.Descriptor instead.
+func (*ListNotifiersRequest) Descriptor() ([]byte, []int) {
+	return file_eapteka_v1_notifier_proto_rawDescGZIP(), []int{2}
+}
+
+type ListNotifiersResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Notifiers []*Notifier `protobuf:"bytes,1,rep,name=notifiers,proto3" json:"notifiers,omitempty"`
+}
+
+func (x *ListNotifiersResponse) Reset() {
+	*x = ListNotifiersResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_eapteka_v1_notifier_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListNotifiersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNotifiersResponse) ProtoMessage() {}
+
+func (x *ListNotifiersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_eapteka_v1_notifier_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNotifiersResponse.ProtoReflect.Descriptor instead.
+func (*ListNotifiersResponse) Descriptor() ([]byte, []int) {
+	return file_eapteka_v1_notifier_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListNotifiersResponse) GetNotifiers() []*Notifier {
+	if x != nil {
+		return x.Notifiers
+	}
+	return nil
+}
+
+type GetNotifierRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id int64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *GetNotifierRequest) Reset() {
+	*x = GetNotifierRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_eapteka_v1_notifier_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetNotifierRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNotifierRequest) ProtoMessage() {}
+
+func (x *GetNotifierRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_eapteka_v1_notifier_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNotifierRequest.ProtoReflect.Descriptor instead.
+func (*GetNotifierRequest) Descriptor() ([]byte, []int) {
+	return file_eapteka_v1_notifier_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetNotifierRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type CreateNotifierRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Notifier *Notifier `protobuf:"bytes,1,opt,name=notifier,proto3" json:"notifier,omitempty"`
+}
+
+func (x *CreateNotifierRequest) Reset() {
+	*x = CreateNotifierRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_eapteka_v1_notifier_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateNotifierRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateNotifierRequest) ProtoMessage() {}
+
+func (x *CreateNotifierRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_eapteka_v1_notifier_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateNotifierRequest.ProtoReflect.Descriptor instead.
+func (*CreateNotifierRequest) Descriptor() ([]byte, []int) {
+	return file_eapteka_v1_notifier_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CreateNotifierRequest) GetNotifier() *Notifier {
+	if x != nil {
+		return x.Notifier
+	}
+	return nil
+}
+
+type UpdateNotifierRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Notifier   *Notifier              `protobuf:"bytes,1,opt,name=notifier,proto3" json:"notifier,omitempty"`
+	UpdateMask *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+}
+
+func (x *UpdateNotifierRequest) Reset() {
+	*x = UpdateNotifierRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_eapteka_v1_notifier_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdateNotifierRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateNotifierRequest) ProtoMessage() {}
+
+func (x *UpdateNotifierRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_eapteka_v1_notifier_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateNotifierRequest.ProtoReflect.Descriptor instead.
+func (*UpdateNotifierRequest) Descriptor() ([]byte, []int) {
+	return file_eapteka_v1_notifier_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UpdateNotifierRequest) GetNotifier() *Notifier {
+	if x != nil {
+		return x.Notifier
+	}
+	return nil
+}
+
+func (x *UpdateNotifierRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+type DeleteNotifierRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id int64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *DeleteNotifierRequest) Reset() {
+	*x = DeleteNotifierRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_eapteka_v1_notifier_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteNotifierRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteNotifierRequest) ProtoMessage() {}
+
+func (x *DeleteNotifierRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_eapteka_v1_notifier_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteNotifierRequest.ProtoReflect.Descriptor instead.
+func (*DeleteNotifierRequest) Descriptor() ([]byte, []int) {
+	return file_eapteka_v1_notifier_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DeleteNotifierRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+// StreamRemindersRequest selects reminders of the user or notifiers, one
+// of them is required. Refills are sent to subscribers of the user only.
+type StreamRemindersRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UserId      string  `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	NotifierIds []int64 `protobuf:"varint,2,rep,packed,name=notifier_ids,json=notifierIds,proto3" json:"notifier_ids,omitempty"`
+}
+
+func (x *StreamRemindersRequest) Reset() {
+	*x = StreamRemindersRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_eapteka_v1_notifier_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamRemindersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamRemindersRequest) ProtoMessage() {}
+
+func (x *StreamRemindersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_eapteka_v1_notifier_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamRemindersRequest.ProtoReflect.Descriptor instead.
+func (*StreamRemindersRequest) Descriptor() ([]byte, []int) {
+	return file_eapteka_v1_notifier_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *StreamRemindersRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *StreamRemindersRequest) GetNotifierIds() []int64 {
+	if x != nil {
+		return x.NotifierIds
+	}
+	return nil
+}
+
+type StreamRemindersResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Event:
+	//	*StreamRemindersResponse_Reminder
+	//	*StreamRemindersResponse_Refill
+	Event isStreamRemindersResponse_Event `protobuf_oneof:"event"`
+}
+
+func (x *StreamRemindersResponse) Reset() {
+	*x = StreamRemindersResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_eapteka_v1_notifier_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamRemindersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamRemindersResponse) ProtoMessage() {}
+
+func (x *StreamRemindersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_eapteka_v1_notifier_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamRemindersResponse.ProtoReflect.Descriptor instead.
+func (*StreamRemindersResponse) Descriptor() ([]byte, []int) {
+	return file_eapteka_v1_notifier_proto_rawDescGZIP(), []int{9}
+}
+
+func (m *StreamRemindersResponse) GetEvent() isStreamRemindersResponse_Event {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
+func (x *StreamRemindersResponse) GetReminder() *Reminder {
+	if x, ok := x.GetEvent().(*StreamRemindersResponse_Reminder); ok {
+		return x.Reminder
+	}
+	return nil
+}
+
+func (x *StreamRemindersResponse) GetRefill() *Refill {
+	if x, ok := x.GetEvent().(*StreamRemindersResponse_Refill); ok {
+		return x.Refill
+	}
+	return nil
+}
+
+type isStreamRemindersResponse_Event interface {
+	isStreamRemindersResponse_Event()
+}
+
+type StreamRemindersResponse_Reminder struct {
+	Reminder *Reminder `protobuf:"bytes,1,opt,name=reminder,proto3,oneof"`
+}
+
+type StreamRemindersResponse_Refill struct {
+	Refill *Refill `protobuf:"bytes,2,opt,name=refill,proto3,oneof"`
+}
+
+func (*StreamRemindersResponse_Reminder) isStreamRemindersResponse_Event() {}
+
+func (*StreamRemindersResponse_Refill) isStreamRemindersResponse_Event() {}
+
+type Reminder struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ReminderId   int64                  `protobuf:"varint,1,opt,name=reminder_id,json=reminderId,proto3" json:"reminder_id,omitempty"`
+	NotifierId   int64                  `protobuf:"varint,2,opt,name=notifier_id,json=notifierId,proto3" json:"notifier_id,omitempty"`
+	ProductId    int64                  `protobuf:"varint,3,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	ProductName  string                 `protobuf:"bytes,4,opt,name=product_name,json=productName,proto3" json:"product_name,omitempty"`
+	ScheduledAt  *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=scheduled_at,json=scheduledAt,proto3" json:"scheduled_at,omitempty"`
+	SnoozedUntil *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=snoozed_until,json=snoozedUntil,proto3" json:"snoozed_until,omitempty"`
+	DoseAmount   float64                `protobuf:"fixed64,7,opt,name=dose_amount,json=doseAmount,proto3" json:"dose_amount,omitempty"`
+	DoseUnit     string                 `protobuf:"bytes,8,opt,name=dose_unit,json=doseUnit,proto3" json:"dose_unit,omitempty"`
+	Notes        string                 `protobuf:"bytes,9,opt,name=notes,proto3" json:"notes,omitempty"`
+}
+
+func (x *Reminder) Reset() {
+	*x = Reminder{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_eapteka_v1_notifier_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Reminder) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reminder) ProtoMessage() {}
+
+func (x *Reminder) ProtoReflect() protoreflect.Message {
+	mi := &file_eapteka_v1_notifier_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reminder.ProtoReflect.Descriptor instead.
+func (*Reminder) Descriptor() ([]byte, []int) {
+	return file_eapteka_v1_notifier_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Reminder) GetReminderId() int64 {
+	if x != nil {
+		return x.ReminderId
+	}
+	return 0
+}
+
+func (x *Reminder) GetNotifierId() int64 {
+	if x != nil {
+		return x.NotifierId
+	}
+	return 0
+}
+
+func (x *Reminder) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *Reminder) GetProductName() string {
+	if x != nil {
+		return x.ProductName
+	}
+	return ""
+}
+
+func (x *Reminder) GetScheduledAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ScheduledAt
+	}
+	return nil
+}
+
+func (x *Reminder) GetSnoozedUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SnoozedUntil
+	}
+	return nil
+}
+
+func (x *Reminder) GetDoseAmount() float64 {
+	if x != nil {
+		return x.DoseAmount
+	}
+	return 0
+}
+
+func (x *Reminder) GetDoseUnit() string {
+	if x != nil {
+		return x.DoseUnit
+	}
+	return ""
+}
+
+func (x *Reminder) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+// Refill is the notification that the user is running out of the product.
+type Refill struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id         int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId     string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ProductId  int64                  `protobuf:"varint,3,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	PurchaseId int64                  `protobuf:"varint,4,opt,name=purchase_id,json=purchaseId,proto3" json:"purchase_id,omitempty"`
+	RunOutAt   *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=run_out_at,json=runOutAt,proto3" json:"run_out_at,omitempty"`
+	Remaining  float64                `protobuf:"fixed64,6,opt,name=remaining,proto3" json:"remaining,omitempty"`
+	// Purchase made by one-click reorder.
+	ReorderPurchaseId *int64                 `protobuf:"varint,7,opt,name=reorder_purchase_id,json=reorderPurchaseId,proto3,oneof" json:"reorder_purchase_id,omitempty"`
+	CreatedAt         *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	ProductName       string                 `protobuf:"bytes,9,opt,name=product_name,json=productName,proto3" json:"product_name,omitempty"`
+}
+
+func (x *Refill) Reset() {
+	*x = Refill{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_eapteka_v1_notifier_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Refill) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Refill) ProtoMessage() {}
+
+func (x *Refill) ProtoReflect() protoreflect.Message {
+	mi := &file_eapteka_v1_notifier_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Refill.ProtoReflect.Descriptor instead.
+func (*Refill) Descriptor() ([]byte, []int) {
+	return file_eapteka_v1_notifier_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Refill) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Refill) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Refill) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *Refill) GetPurchaseId() int64 {
+	if x != nil {
+		return x.PurchaseId
+	}
+	return 0
+}
+
+func (x *Refill) GetRunOutAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RunOutAt
+	}
+	return nil
+}
+
+func (x *Refill) GetRemaining() float64 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+func (x *Refill) GetReorderPurchaseId() int64 {
+	if x != nil && x.ReorderPurchaseId != nil {
+		return *x.ReorderPurchaseId
+	}
+	return 0
+}
+
+func (x *Refill) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Refill) GetProductName() string {
+	if x != nil {
+		return x.ProductName
+	}
+	return ""
+}
+
+var File_eapteka_v1_notifier_proto protoreflect.FileDescriptor
+
+var file_eapteka_v1_notifier_proto_rawDesc = []byte{
+	0x0a, 0x19, 0x65, 0x61, 0x70, 0x74, 0x65, 0x6b, 0x61, 0x2f, 0x76, 0x31, 0x2f, 0x6e, 0x6f, 0x74,
+	0x69, 0x66, 0x69, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0a, 0x65, 0x61, 0x70,
+	0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x1a, 0x1c, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f,
+	0x61, 0x70, 0x69, 0x2f, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1b, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x1a, 0x20, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2f, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x5f, 0x6d, 0x61, 0x73, 0x6b, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xc1, 0x02, 0x0a, 0x08, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x69,
+	0x65, 0x72, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02,
+	0x69, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x75, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x70,
+	0x72, 0x6f, 0x64, 0x75, 0x63, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x09, 0x70, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x63,
+	0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x73, 0x63,
+	0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x12, 0x2c, 0x0a, 0x04, 0x72, 0x75, 0x6c, 0x65, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x65, 0x61, 0x70, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76,
+	0x31, 0x2e, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x04,
+	0x72, 0x75, 0x6c, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x6f, 0x73, 0x65, 0x5f, 0x61, 0x6d, 0x6f,
+	0x75, 0x6e, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x64, 0x6f, 0x73, 0x65, 0x41,
+	0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x6f, 0x73, 0x65, 0x5f, 0x75, 0x6e,
+	0x69, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x6f, 0x73, 0x65, 0x55, 0x6e,
+	0x69, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x61, 0x75, 0x73,
+	0x65, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x70, 0x61, 0x75, 0x73, 0x65, 0x64,
+	0x12, 0x21, 0x0a, 0x0c, 0x70, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74, 0x5f, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x70, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74, 0x4e,
+	0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x72, 0x75, 0x6c, 0x65, 0x18, 0x0b, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x72, 0x72, 0x75, 0x6c, 0x65, 0x22, 0xf5, 0x01, 0x0a, 0x0c, 0x53, 0x63,
+	0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x75, 0x6c, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x77, 0x65,
+	0x65, 0x6b, 0x64, 0x61, 0x79, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x05, 0x52, 0x08, 0x77, 0x65,
+	0x65, 0x6b, 0x64, 0x61, 0x79, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x65, 0x76, 0x65, 0x72, 0x79, 0x5f,
+	0x68, 0x6f, 0x75, 0x72, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x65, 0x76, 0x65,
+	0x72, 0x79, 0x48, 0x6f, 0x75, 0x72, 0x73, 0x12, 0x35, 0x0a, 0x08, 0x73, 0x74, 0x61, 0x72, 0x74,
+	0x5f, 0x61, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x07, 0x73, 0x74, 0x61, 0x72, 0x74, 0x41, 0x74, 0x12, 0x31,
+	0x0a, 0x06, 0x65, 0x6e, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x05, 0x65, 0x6e, 0x64, 0x41,
+	0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x6f, 0x75, 0x72, 0x73, 0x65, 0x5f, 0x64, 0x61, 0x79, 0x73,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x63, 0x6f, 0x75, 0x72, 0x73, 0x65, 0x44, 0x61,
+	0x79, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x69, 0x6c, 0x6c, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x70, 0x69, 0x6c, 0x6c, 0x43, 0x6f, 0x75, 0x6e,
+	0x74, 0x22, 0x16, 0x0a, 0x14, 0x4c, 0x69, 0x73, 0x74, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x65,
+	0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x4b, 0x0a, 0x15, 0x4c, 0x69, 0x73,
+	0x74, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x32, 0x0a, 0x09, 0x6e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x65, 0x61, 0x70, 0x74, 0x65, 0x6b, 0x61, 0x2e,
+	0x76, 0x31, 0x2e, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x52, 0x09, 0x6e, 0x6f, 0x74,
+	0x69, 0x66, 0x69, 0x65, 0x72, 0x73, 0x22, 0x24, 0x0a, 0x12, 0x47, 0x65, 0x74, 0x4e, 0x6f, 0x74,
+	0x69, 0x66, 0x69, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x22, 0x49, 0x0a, 0x15,
+	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x30, 0x0a, 0x08, 0x6e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x65,
+	0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x65, 0x61, 0x70, 0x74, 0x65, 0x6b,
+	0x61, 0x2e, 0x76, 0x31, 0x2e, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x52, 0x08, 0x6e,
+	0x6f, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x22, 0x86, 0x01, 0x0a, 0x15, 0x55, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x30, 0x0a, 0x08, 0x6e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x65, 0x61, 0x70, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31,
+	0x2e, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x52, 0x08, 0x6e, 0x6f, 0x74, 0x69, 0x66,
+	0x69, 0x65, 0x72, 0x12, 0x3b, 0x0a, 0x0b, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x5f, 0x6d, 0x61,
+	0x73, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64,
+	0x4d, 0x61, 0x73, 0x6b, 0x52, 0x0a, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4d, 0x61, 0x73, 0x6b,
+	0x22, 0x27, 0x0a, 0x15, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x69,
+	0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x22, 0x54, 0x0a, 0x16, 0x53, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x52, 0x65, 0x6d, 0x69, 0x6e, 0x64, 0x65, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x75, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x0c,
+	0x6e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x03, 0x52, 0x0b, 0x6e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x49, 0x64, 0x73, 0x22,
+	0x84, 0x01, 0x0a, 0x17, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x6d, 0x69, 0x6e, 0x64,
+	0x65, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x32, 0x0a, 0x08, 0x72,
+	0x65, 0x6d, 0x69, 0x6e, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e,
+	0x65, 0x61, 0x70, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x6d, 0x69, 0x6e,
+	0x64, 0x65, 0x72, 0x48, 0x00, 0x52, 0x08, 0x72, 0x65, 0x6d, 0x69, 0x6e, 0x64, 0x65, 0x72, 0x12,
+	0x2c, 0x0a, 0x06, 0x72, 0x65, 0x66, 0x69, 0x6c, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x12, 0x2e, 0x65, 0x61, 0x70, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x66,
+	0x69, 0x6c, 0x6c, 0x48, 0x00, 0x52, 0x06, 0x72, 0x65, 0x66, 0x69, 0x6c, 0x6c, 0x42, 0x07, 0x0a,
+	0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x22, 0xe2, 0x02, 0x0a, 0x08, 0x52, 0x65, 0x6d, 0x69, 0x6e,
+	0x64, 0x65, 0x72, 0x12, 0x1f, 0x0a, 0x0b, 0x72, 0x65, 0x6d, 0x69, 0x6e, 0x64, 0x65, 0x72, 0x5f,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x72, 0x65, 0x6d, 0x69, 0x6e, 0x64,
+	0x65, 0x72, 0x49, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x6e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72,
+	0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x6e, 0x6f, 0x74, 0x69, 0x66,
+	0x69, 0x65, 0x72, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74,
+	0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x70, 0x72, 0x6f, 0x64, 0x75,
+	0x63, 0x74, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x70, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74, 0x5f,
+	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x70, 0x72, 0x6f, 0x64,
+	0x75, 0x63, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x3d, 0x0a, 0x0c, 0x73, 0x63, 0x68, 0x65, 0x64,
+	0x75, 0x6c, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0b, 0x73, 0x63, 0x68, 0x65, 0x64,
+	0x75, 0x6c, 0x65, 0x64, 0x41, 0x74, 0x12, 0x3f, 0x0a, 0x0d, 0x73, 0x6e, 0x6f, 0x6f, 0x7a, 0x65,
+	0x64, 0x5f, 0x75, 0x6e, 0x74, 0x69, 0x6c, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0c, 0x73, 0x6e, 0x6f, 0x6f, 0x7a,
+	0x65, 0x64, 0x55, 0x6e, 0x74, 0x69, 0x6c, 0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x6f, 0x73, 0x65, 0x5f,
+	0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x64, 0x6f,
+	0x73, 0x65, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x6f, 0x73, 0x65,
+	0x5f, 0x75, 0x6e, 0x69, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x6f, 0x73,
+	0x65, 0x55, 0x6e, 0x69, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x18, 0x09,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x22, 0xf4, 0x02, 0x0a, 0x06,
+	0x52, 0x65, 0x66, 0x69, 0x6c, 0x6c, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x75, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12,
+	0x1d, 0x0a, 0x0a, 0x70, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x09, 0x70, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74, 0x49, 0x64, 0x12, 0x1f,
+	0x0a, 0x0b, 0x70, 0x75, 0x72, 0x63, 0x68, 0x61, 0x73, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0a, 0x70, 0x75, 0x72, 0x63, 0x68, 0x61, 0x73, 0x65, 0x49, 0x64, 0x12,
+	0x38, 0x0a, 0x0a, 0x72, 0x75, 0x6e, 0x5f, 0x6f, 0x75, 0x74, 0x5f, 0x61, 0x74, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
+	0x08, 0x72, 0x75, 0x6e, 0x4f, 0x75, 0x74, 0x41, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x6d,
+	0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x72, 0x65,
+	0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x33, 0x0a, 0x13, 0x72, 0x65, 0x6f, 0x72, 0x64,
+	0x65, 0x72, 0x5f, 0x70, 0x75, 0x72, 0x63, 0x68, 0x61, 0x73, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x11, 0x72, 0x65, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x50,
+	0x75, 0x72, 0x63, 0x68, 0x61, 0x73, 0x65, 0x49, 0x64, 0x88, 0x01, 0x01, 0x12, 0x39, 0x0a, 0x0a,
+	0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x63, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x70, 0x72, 0x6f, 0x64, 0x75,
+	0x63, 0x74, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x70,
+	0x72, 0x6f, 0x64, 0x75, 0x63, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x42, 0x16, 0x0a, 0x14, 0x5f, 0x72,
+	0x65, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x5f, 0x70, 0x75, 0x72, 0x63, 0x68, 0x61, 0x73, 0x65, 0x5f,
+	0x69, 0x64, 0x32, 0xd9, 0x05, 0x0a, 0x0f, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x53,
+	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x7a, 0x0a, 0x0d, 0x4c, 0x69, 0x73, 0x74, 0x4e, 0x6f,
+	0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x73, 0x12, 0x20, 0x2e, 0x65, 0x61, 0x70, 0x74, 0x65, 0x6b,
+	0x61, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x65,
+	0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x65, 0x61, 0x70, 0x74,
+	0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x4e, 0x6f, 0x74, 0x69, 0x66,
+	0x69, 0x65, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x24, 0x82, 0xd3,
+	0xe4, 0x93, 0x02, 0x1e, 0x12, 0x11, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x2f, 0x6e, 0x6f,
+	0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x73, 0x62, 0x09, 0x6e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x65,
+	0x72, 0x73, 0x12, 0x63, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x65,
+	0x72, 0x12, 0x1e, 0x2e, 0x65, 0x61, 0x70, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x47,
+	0x65, 0x74, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x14, 0x2e, 0x65, 0x61, 0x70, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x4e,
+	0x6f, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x22, 0x1e, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x18, 0x12,
+	0x16, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x2f, 0x6e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x65,
+	0x72, 0x73, 0x2f, 0x7b, 0x69, 0x64, 0x7d, 0x12, 0x6e, 0x0a, 0x0e, 0x43, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x12, 0x21, 0x2e, 0x65, 0x61, 0x70, 0x74,
+	0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4e, 0x6f, 0x74,
+	0x69, 0x66, 0x69, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x65,
+	0x61, 0x70, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x69,
+	0x65, 0x72, 0x22, 0x23, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1d, 0x22, 0x11, 0x2f, 0x61, 0x70, 0x69,
+	0x2f, 0x76, 0x31, 0x2f, 0x6e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x73, 0x3a, 0x08, 0x6e,
+	0x6f, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x12, 0xa9, 0x01, 0x0a, 0x0e, 0x55, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x12, 0x21, 0x2e, 0x65, 0x61, 0x70,
+	0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4e, 0x6f,
+	0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e,
+	0x65, 0x61, 0x70, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x4e, 0x6f, 0x74, 0x69, 0x66,
+	0x69, 0x65, 0x72, 0x22, 0x5e, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x58, 0x1a, 0x1f, 0x2f, 0x61, 0x70,
+	0x69, 0x2f, 0x76, 0x31, 0x2f, 0x6e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x73, 0x2f, 0x7b,
+	0x6e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x2e, 0x69, 0x64, 0x7d, 0x3a, 0x08, 0x6e, 0x6f,
+	0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x5a, 0x2b, 0x32, 0x1f, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76,
+	0x31, 0x2f, 0x6e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x73, 0x2f, 0x7b, 0x6e, 0x6f, 0x74,
+	0x69, 0x66, 0x69, 0x65, 0x72, 0x2e, 0x69, 0x64, 0x7d, 0x3a, 0x08, 0x6e, 0x6f, 0x74, 0x69, 0x66,
+	0x69, 0x65, 0x72, 0x12, 0x6b, 0x0a, 0x0e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4e, 0x6f, 0x74,
+	0x69, 0x66, 0x69, 0x65, 0x72, 0x12, 0x21, 0x2e, 0x65, 0x61, 0x70, 0x74, 0x65, 0x6b, 0x61, 0x2e,
+	0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x65,
+	0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79,
+	0x22, 0x1e, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x18, 0x2a, 0x16, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76,
+	0x31, 0x2f, 0x6e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x73, 0x2f, 0x7b, 0x69, 0x64, 0x7d,
+	0x12, 0x5c, 0x0a, 0x0f, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x6d, 0x69, 0x6e, 0x64,
+	0x65, 0x72, 0x73, 0x12, 0x22, 0x2e, 0x65, 0x61, 0x70, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31,
+	0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x6d, 0x69, 0x6e, 0x64, 0x65, 0x72, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x23, 0x2e, 0x65, 0x61, 0x70, 0x74, 0x65, 0x6b,
+	0x61, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x6d, 0x69, 0x6e,
+	0x64, 0x65, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x42, 0x24,
+	0x5a, 0x22, 0x65, 0x61, 0x70, 0x74, 0x65, 0x6b, 0x61, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f,
+	0x65, 0x61, 0x70, 0x74, 0x65, 0x6b, 0x61, 0x2f, 0x76, 0x31, 0x3b, 0x65, 0x61, 0x70, 0x74, 0x65,
+	0x6b, 0x61, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_eapteka_v1_notifier_proto_rawDescOnce sync.Once
+	file_eapteka_v1_notifier_proto_rawDescData = file_eapteka_v1_notifier_proto_rawDesc
+)
+
+func file_eapteka_v1_notifier_proto_rawDescGZIP() []byte {
+	file_eapteka_v1_notifier_proto_rawDescOnce.Do(func() {
+		file_eapteka_v1_notifier_proto_rawDescData = protoimpl.X.CompressGZIP(file_eapteka_v1_notifier_proto_rawDescData)
+	})
+	return file_eapteka_v1_notifier_proto_rawDescData
+}
+
+var file_eapteka_v1_notifier_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_eapteka_v1_notifier_proto_goTypes = []interface{}{
+	(*Notifier)(nil),                // 0: eapteka.v1.Notifier
+	(*ScheduleRule)(nil),            // 1: eapteka.v1.ScheduleRule
+	(*ListNotifiersRequest)(nil),    // 2: eapteka.v1.ListNotifiersRequest
+	(*ListNotifiersResponse)(nil),   // 3: eapteka.v1.ListNotifiersResponse
+	(*GetNotifierRequest)(nil),      // 4: eapteka.v1.GetNotifierRequest
+	(*CreateNotifierRequest)(nil),   // 5: eapteka.v1.CreateNotifierRequest
+	(*UpdateNotifierRequest)(nil),   // 6: eapteka.v1.UpdateNotifierRequest
+	(*DeleteNotifierRequest)(nil),   // 7: eapteka.v1.DeleteNotifierRequest
+	(*StreamRemindersRequest)(nil),  // 8: eapteka.v1.StreamRemindersRequest
+	(*StreamRemindersResponse)(nil), // 9: eapteka.v1.StreamRemindersResponse
+	(*Reminder)(nil),                // 10: eapteka.v1.Reminder
+	(*Refill)(nil),                  // 11: eapteka.v1.Refill
+	(*timestamppb.Timestamp)(nil),   // 12: google.protobuf.Timestamp
+	(*fieldmaskpb.FieldMask)(nil),   // 13: google.protobuf.FieldMask
+	(*emptypb.Empty)(nil),           // 14: google.protobuf.Empty
+}
+var file_eapteka_v1_notifier_proto_depIdxs = []int32{
+	1,  // 0: eapteka.v1.Notifier.rule:type_name -> eapteka.v1.ScheduleRule
+	12, // 1: eapteka.v1.ScheduleRule.start_at:type_name -> google.protobuf.Timestamp
+	12, // 2: eapteka.v1.ScheduleRule.end_at:type_name -> google.protobuf.Timestamp
+	0,  // 3: eapteka.v1.ListNotifiersResponse.notifiers:type_name -> eapteka.v1.Notifier
+	0,  // 4: eapteka.v1.CreateNotifierRequest.notifier:type_name -> eapteka.v1.Notifier
+	0,  // 5: eapteka.v1.UpdateNotifierRequest.notifier:type_name -> eapteka.v1.Notifier
+	13, // 6: eapteka.v1.UpdateNotifierRequest.update_mask:type_name -> google.protobuf.FieldMask
+	10, // 7: eapteka.v1.StreamRemindersResponse.reminder:type_name -> eapteka.v1.Reminder
+	11, // 8: eapteka.v1.StreamRemindersResponse.refill:type_name -> eapteka.v1.Refill
+	12, // 9: eapteka.v1.Reminder.scheduled_at:type_name -> google.protobuf.Timestamp
+	12, // 10: eapteka.v1.Reminder.snoozed_until:type_name -> google.protobuf.Timestamp
+	12, // 11: eapteka.v1.Refill.run_out_at:type_name -> google.protobuf.Timestamp
+	12, // 12: eapteka.v1.Refill.created_at:type_name -> google.protobuf.Timestamp
+	2,  // 13: eapteka.v1.NotifierService.ListNotifiers:input_type -> eapteka.v1.ListNotifiersRequest
+	4,  // 14: eapteka.v1.NotifierService.GetNotifier:input_type -> eapteka.v1.GetNotifierRequest
+	5,  // 15: eapteka.v1.NotifierService.CreateNotifier:input_type -> eapteka.v1.CreateNotifierRequest
+	6,  // 16: eapteka.v1.NotifierService.UpdateNotifier:input_type -> eapteka.v1.UpdateNotifierRequest
+	7,  // 17: eapteka.v1.NotifierService.DeleteNotifier:input_type -> eapteka.v1.DeleteNotifierRequest
+	8,  // 18: eapteka.v1.NotifierService.StreamReminders:input_type -> eapteka.v1.StreamRemindersRequest
+	3,  // 19: eapteka.v1.NotifierService.ListNotifiers:output_type -> eapteka.v1.ListNotifiersResponse
+	0,  // 20: eapteka.v1.NotifierService.GetNotifier:output_type -> eapteka.v1.Notifier
+	0,  // 21: eapteka.v1.NotifierService.CreateNotifier:output_type -> eapteka.v1.Notifier
+	0,  // 22: eapteka.v1.NotifierService.UpdateNotifier:output_type -> eapteka.v1.Notifier
+	14, // 23: eapteka.v1.NotifierService.DeleteNotifier:output_type -> google.protobuf.Empty
+	9,  // 24: eapteka.v1.NotifierService.StreamReminders:output_type -> eapteka.v1.StreamRemindersResponse
+	19, // [19:25] is the sub-list for method output_type
+	13, // [13:19] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_eapteka_v1_notifier_proto_init() }
+func file_eapteka_v1_notifier_proto_init() {
+	if File_eapteka_v1_notifier_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_eapteka_v1_notifier_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Notifier); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_eapteka_v1_notifier_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ScheduleRule); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_eapteka_v1_notifier_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListNotifiersRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_eapteka_v1_notifier_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListNotifiersResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_eapteka_v1_notifier_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetNotifierRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_eapteka_v1_notifier_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateNotifierRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_eapteka_v1_notifier_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdateNotifierRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_eapteka_v1_notifier_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeleteNotifierRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_eapteka_v1_notifier_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StreamRemindersRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_eapteka_v1_notifier_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StreamRemindersResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_eapteka_v1_notifier_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Reminder); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_eapteka_v1_notifier_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Refill); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_eapteka_v1_notifier_proto_msgTypes[9].OneofWrappers = []interface{}{
+		(*StreamRemindersResponse_Reminder)(nil),
+		(*StreamRemindersResponse_Refill)(nil),
+	}
+	file_eapteka_v1_notifier_proto_msgTypes[11].OneofWrappers = []interface{}{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_eapteka_v1_notifier_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_eapteka_v1_notifier_proto_goTypes,
+		DependencyIndexes: file_eapteka_v1_notifier_proto_depIdxs,
+		MessageInfos:      file_eapteka_v1_notifier_proto_msgTypes,
+	}.Build()
+	File_eapteka_v1_notifier_proto = out.File
+	file_eapteka_v1_notifier_proto_rawDesc = nil
+	file_eapteka_v1_notifier_proto_goTypes = nil
+	file_eapteka_v1_notifier_proto_depIdxs = nil
+}
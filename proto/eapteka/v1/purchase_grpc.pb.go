@@ -0,0 +1,181 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package eaptekav1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// PurchaseServiceClient is the client API for PurchaseService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PurchaseServiceClient interface {
+	// ListPurchases returns purchases of the user, newest first, every
+	// purchase if user_id is empty.
+	ListPurchases(ctx context.Context, in *ListPurchasesRequest, opts ...grpc.CallOption) (*ListPurchasesResponse, error)
+	// ListPurchaseProducts returns products of the purchase with count and
+	// purchase price.
+	ListPurchaseProducts(ctx context.Context, in *ListPurchaseProductsRequest, opts ...grpc.CallOption) (*ListPurchaseProductsResponse, error)
+	CreatePurchase(ctx context.Context, in *CreatePurchaseRequest, opts ...grpc.CallOption) (*Purchase, error)
+}
+
+type purchaseServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPurchaseServiceClient(cc grpc.ClientConnInterface) PurchaseServiceClient {
+	return &purchaseServiceClient{cc}
+}
+
+func (c *purchaseServiceClient) ListPurchases(ctx context.Context, in *ListPurchasesRequest, opts ...grpc.CallOption) (*ListPurchasesResponse, error) {
+	out := new(ListPurchasesResponse)
+	err := c.cc.Invoke(ctx, "/eapteka.v1.PurchaseService/ListPurchases", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *purchaseServiceClient) ListPurchaseProducts(ctx context.Context, in *ListPurchaseProductsRequest, opts ...grpc.CallOption) (*ListPurchaseProductsResponse, error) {
+	out := new(ListPurchaseProductsResponse)
+	err := c.cc.Invoke(ctx, "/eapteka.v1.PurchaseService/ListPurchaseProducts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *purchaseServiceClient) CreatePurchase(ctx context.Context, in *CreatePurchaseRequest, opts ...grpc.CallOption) (*Purchase, error) {
+	out := new(Purchase)
+	err := c.cc.Invoke(ctx, "/eapteka.v1.PurchaseService/CreatePurchase", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PurchaseServiceServer is the server API for PurchaseService service.
+// All implementations must embed UnimplementedPurchaseServiceServer
+// for forward compatibility
+type PurchaseServiceServer interface {
+	// ListPurchases returns purchases of the user, newest first, every
+	// purchase if user_id is empty.
+	ListPurchases(context.Context, *ListPurchasesRequest) (*ListPurchasesResponse, error)
+	// ListPurchaseProducts returns products of the purchase with count and
+	// purchase price.
+	ListPurchaseProducts(context.Context, *ListPurchaseProductsRequest) (*ListPurchaseProductsResponse, error)
+	CreatePurchase(context.Context, *CreatePurchaseRequest) (*Purchase, error)
+	mustEmbedUnimplementedPurchaseServiceServer()
+}
+
+// UnimplementedPurchaseServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedPurchaseServiceServer struct {
+}
+
+func (UnimplementedPurchaseServiceServer) ListPurchases(context.Context, *ListPurchasesRequest) (*ListPurchasesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPurchases not implemented")
+}
+func (UnimplementedPurchaseServiceServer) ListPurchaseProducts(context.Context, *ListPurchaseProductsRequest) (*ListPurchaseProductsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPurchaseProducts not implemented")
+}
+func (UnimplementedPurchaseServiceServer) CreatePurchase(context.Context, *CreatePurchaseRequest) (*Purchase, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePurchase not implemented")
+}
+func (UnimplementedPurchaseServiceServer) mustEmbedUnimplementedPurchaseServiceServer() {}
+
+// UnsafePurchaseServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PurchaseServiceServer will
+// result in compilation errors.
+type UnsafePurchaseServiceServer interface {
+	mustEmbedUnimplementedPurchaseServiceServer()
+}
+
+func RegisterPurchaseServiceServer(s grpc.ServiceRegistrar, srv PurchaseServiceServer) {
+	s.RegisterService(&PurchaseService_ServiceDesc, srv)
+}
+
+func _PurchaseService_ListPurchases_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPurchasesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PurchaseServiceServer).ListPurchases(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/eapteka.v1.PurchaseService/ListPurchases",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PurchaseServiceServer).ListPurchases(ctx, req.(*ListPurchasesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PurchaseService_ListPurchaseProducts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPurchaseProductsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PurchaseServiceServer).ListPurchaseProducts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/eapteka.v1.PurchaseService/ListPurchaseProducts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PurchaseServiceServer).ListPurchaseProducts(ctx, req.(*ListPurchaseProductsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PurchaseService_CreatePurchase_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePurchaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PurchaseServiceServer).CreatePurchase(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/eapteka.v1.PurchaseService/CreatePurchase",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PurchaseServiceServer).CreatePurchase(ctx, req.(*CreatePurchaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PurchaseService_ServiceDesc is the grpc.ServiceDesc for PurchaseService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PurchaseService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "eapteka.v1.PurchaseService",
+	HandlerType: (*PurchaseServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListPurchases",
+			Handler:    _PurchaseService_ListPurchases_Handler,
+		},
+		{
+			MethodName: "ListPurchaseProducts",
+			Handler:    _PurchaseService_ListPurchaseProducts_Handler,
+		},
+		{
+			MethodName: "CreatePurchase",
+			Handler:    _PurchaseService_CreatePurchase_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "eapteka/v1/purchase.proto",
+}
@@ -0,0 +1,171 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package eaptekav1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// RecommendationServiceClient is the client API for RecommendationService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RecommendationServiceClient interface {
+	ListRecommendations(ctx context.Context, in *ListRecommendationsRequest, opts ...grpc.CallOption) (*ListRecommendationsResponse, error)
+	// StreamRecommendations sends recommendations periodically picked for
+	// the user, like /ws/recommends does. The stream ends on server
+	// shutdown.
+	StreamRecommendations(ctx context.Context, in *StreamRecommendationsRequest, opts ...grpc.CallOption) (RecommendationService_StreamRecommendationsClient, error)
+}
+
+type recommendationServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRecommendationServiceClient(cc grpc.ClientConnInterface) RecommendationServiceClient {
+	return &recommendationServiceClient{cc}
+}
+
+func (c *recommendationServiceClient) ListRecommendations(ctx context.Context, in *ListRecommendationsRequest, opts ...grpc.CallOption) (*ListRecommendationsResponse, error) {
+	out := new(ListRecommendationsResponse)
+	err := c.cc.Invoke(ctx, "/eapteka.v1.RecommendationService/ListRecommendations", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *recommendationServiceClient) StreamRecommendations(ctx context.Context, in *StreamRecommendationsRequest, opts ...grpc.CallOption) (RecommendationService_StreamRecommendationsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &RecommendationService_ServiceDesc.Streams[0], "/eapteka.v1.RecommendationService/StreamRecommendations", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &recommendationServiceStreamRecommendationsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type RecommendationService_StreamRecommendationsClient interface {
+	Recv() (*Recommendation, error)
+	grpc.ClientStream
+}
+
+type recommendationServiceStreamRecommendationsClient struct {
+	grpc.ClientStream
+}
+
+func (x *recommendationServiceStreamRecommendationsClient) Recv() (*Recommendation, error) {
+	m := new(Recommendation)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// RecommendationServiceServer is the server API for RecommendationService service.
+// All implementations must embed UnimplementedRecommendationServiceServer
+// for forward compatibility
+type RecommendationServiceServer interface {
+	ListRecommendations(context.Context, *ListRecommendationsRequest) (*ListRecommendationsResponse, error)
+	// StreamRecommendations sends recommendations periodically picked for
+	// the user, like /ws/recommends does. The stream ends on server
+	// shutdown.
+	StreamRecommendations(*StreamRecommendationsRequest, RecommendationService_StreamRecommendationsServer) error
+	mustEmbedUnimplementedRecommendationServiceServer()
+}
+
+// UnimplementedRecommendationServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedRecommendationServiceServer struct {
+}
+
+func (UnimplementedRecommendationServiceServer) ListRecommendations(context.Context, *ListRecommendationsRequest) (*ListRecommendationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRecommendations not implemented")
+}
+func (UnimplementedRecommendationServiceServer) StreamRecommendations(*StreamRecommendationsRequest, RecommendationService_StreamRecommendationsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamRecommendations not implemented")
+}
+func (UnimplementedRecommendationServiceServer) mustEmbedUnimplementedRecommendationServiceServer() {}
+
+// UnsafeRecommendationServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RecommendationServiceServer will
+// result in compilation errors.
+type UnsafeRecommendationServiceServer interface {
+	mustEmbedUnimplementedRecommendationServiceServer()
+}
+
+func RegisterRecommendationServiceServer(s grpc.ServiceRegistrar, srv RecommendationServiceServer) {
+	s.RegisterService(&RecommendationService_ServiceDesc, srv)
+}
+
+func _RecommendationService_ListRecommendations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRecommendationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RecommendationServiceServer).ListRecommendations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/eapteka.v1.RecommendationService/ListRecommendations",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RecommendationServiceServer).ListRecommendations(ctx, req.(*ListRecommendationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RecommendationService_StreamRecommendations_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamRecommendationsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RecommendationServiceServer).StreamRecommendations(m, &recommendationServiceStreamRecommendationsServer{stream})
+}
+
+type RecommendationService_StreamRecommendationsServer interface {
+	Send(*Recommendation) error
+	grpc.ServerStream
+}
+
+type recommendationServiceStreamRecommendationsServer struct {
+	grpc.ServerStream
+}
+
+func (x *recommendationServiceStreamRecommendationsServer) Send(m *Recommendation) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// RecommendationService_ServiceDesc is the grpc.ServiceDesc for RecommendationService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RecommendationService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "eapteka.v1.RecommendationService",
+	HandlerType: (*RecommendationServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListRecommendations",
+			Handler:    _RecommendationService_ListRecommendations_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamRecommendations",
+			Handler:       _RecommendationService_StreamRecommendations_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "eapteka/v1/recommendation.proto",
+}
@@ -0,0 +1,99 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+type MemoryConfig struct {
+	// Expire is the idle time after which the bucket is forgotten. Buckets
+	// refilled slower than Expire are forgotten not yet full.
+	Expire time.Duration
+
+	// Tick is the interval of forgetting refilled and expired buckets.
+	Tick time.Duration
+}
+
+var MemoryConfigDefault = MemoryConfig{
+	Expire: time.Hour,
+	Tick:   time.Minute,
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+
+	// full is the time the bucket is refilled, it's forgotten then as the
+	// new one is full as well.
+	full time.Time
+}
+
+// Memory keeps buckets in memory of the replica, so every replica limits
+// requests it serves.
+type Memory struct {
+	cfg MemoryConfig
+
+	buckets map[string]*bucket
+	swept   time.Time
+	mx      sync.Mutex
+}
+
+func NewMemory(cfg MemoryConfig) *Memory {
+	if cfg.Expire == 0 {
+		cfg.Expire = MemoryConfigDefault.Expire
+	}
+	if cfg.Tick == 0 {
+		cfg.Tick = MemoryConfigDefault.Tick
+	}
+
+	return &Memory{
+		cfg:     cfg,
+		buckets: map[string]*bucket{},
+		swept:   time.Now(),
+	}
+}
+
+func (m *Memory) Take(_ context.Context, key string, l Limit) (
+	time.Duration, error) {
+
+	now := time.Now()
+
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	if now.Sub(m.swept) >= m.cfg.Tick {
+		m.sweep(now)
+	}
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.Burst)}
+		m.buckets[key] = b
+	} else {
+		b.tokens = math.Min(float64(l.Burst),
+			b.tokens+now.Sub(b.updated).Seconds()*l.Rate)
+	}
+	b.updated = now
+
+	if b.tokens < 1 {
+		b.full = now.Add(l.refill(b.tokens))
+		return l.wait(b.tokens), nil
+	}
+
+	b.tokens--
+	b.full = now.Add(l.refill(b.tokens))
+
+	return 0, nil
+}
+
+// sweep forgets refilled buckets and buckets idle for Expire.
+func (m *Memory) sweep(now time.Time) {
+	for k, b := range m.buckets {
+		if !now.Before(b.full) || now.Sub(b.updated) >= m.cfg.Expire {
+			delete(m.buckets, k)
+		}
+	}
+	m.swept = now
+}
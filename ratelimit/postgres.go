@@ -0,0 +1,152 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+type PostgresConfig struct {
+	// Expire is the idle time after which the bucket is deleted. Buckets
+	// refilled slower than Expire are deleted not yet full.
+	Expire time.Duration
+
+	// Tick is the interval of deleting expired buckets.
+	Tick time.Duration
+}
+
+var PostgresConfigDefault = PostgresConfig{
+	Expire: time.Hour,
+	Tick:   10 * time.Minute,
+}
+
+// Postgres keeps buckets in rate_limit table shared by replicas. The
+// bucket is refilled and the token is taken by a single upsert locking its
+// row, and elapsed time is measured by the Postgres clock, so concurrent
+// requests to different replicas take tokens of one bucket.
+type Postgres struct {
+	db  *sqlx.DB
+	cfg PostgresConfig
+
+	running int32
+
+	close chan struct{}
+	wg    sync.WaitGroup
+}
+
+func NewPostgres(db *sqlx.DB, cfg PostgresConfig) *Postgres {
+	if cfg.Expire == 0 {
+		cfg.Expire = PostgresConfigDefault.Expire
+	}
+	if cfg.Tick == 0 {
+		cfg.Tick = PostgresConfigDefault.Tick
+	}
+
+	return &Postgres{
+		db:    db,
+		cfg:   cfg,
+		close: make(chan struct{}),
+	}
+}
+
+func (p *Postgres) Start() {
+	atomic.StoreInt32(&p.running, 1)
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer atomic.StoreInt32(&p.running, 0)
+		p.expireLoop()
+	}()
+}
+
+func (p *Postgres) Stop() {
+	close(p.close)
+	p.wg.Wait()
+}
+
+// Running reports whether the expire loop is running.
+func (p *Postgres) Running() bool {
+	return atomic.LoadInt32(&p.running) == 1
+}
+
+func (p *Postgres) Take(ctx context.Context, key string, l Limit) (
+	time.Duration, error) {
+
+	var tokens float64
+
+	// The update is skipped if the refilled bucket has no whole token, so
+	// the rejected request doesn't return a row.
+	err := p.db.QueryRowxContext(ctx, `
+		-- name: take rate limit token
+		insert into rate_limit as r (key, tokens, updated_at)
+		values ($1, $3::float8 - 1, now())
+		on conflict (key) do update
+		set tokens = least($3::float8, r.tokens + $2::float8 *
+		        extract(epoch from now() - r.updated_at)::float8) - 1,
+		    updated_at = now()
+		where least($3::float8, r.tokens + $2::float8 *
+		        extract(epoch from now() - r.updated_at)::float8) >= 1
+		returning tokens
+	`, key, l.Rate, l.Burst).Scan(&tokens)
+	if err == nil {
+		return 0, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("take token: %w", err)
+	}
+
+	err = p.db.QueryRowxContext(ctx, `
+		-- name: select rate limit tokens
+		select least($3::float8, tokens + $2::float8 *
+		           extract(epoch from now() - updated_at)::float8)
+		from rate_limit
+		where key = $1
+	`, key, l.Rate, l.Burst).Scan(&tokens)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("select tokens: %w", err)
+	}
+
+	// The bucket is refilled or expired since the request was rejected
+	if err != nil || tokens >= 1 {
+		return time.Second, nil
+	}
+
+	return l.wait(tokens), nil
+}
+
+func (p *Postgres) expireLoop() {
+	t := time.NewTicker(p.cfg.Tick)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-p.close:
+			return
+		case <-t.C:
+			err := p.expire()
+			if err != nil {
+				logrus.WithError(err).Error("failed to expire rate limits")
+			}
+		}
+	}
+}
+
+// expire deletes buckets idle for Expire.
+func (p *Postgres) expire() error {
+	_, err := p.db.Exec(`
+		delete from rate_limit
+		where updated_at < now() - $1::float8 * interval '1 second'
+	`, p.cfg.Expire.Seconds())
+	if err != nil {
+		return fmt.Errorf("delete expired: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+// Package ratelimit limits request rate of API routes by token buckets
+// keyed by user or client IP. Every route has its own budget: the bucket
+// holds up to Burst tokens refilled at Rate per second, and the request
+// taking a token from the empty bucket is rejected with 429 and
+// Retry-After header.
+//
+// Buckets are kept in memory of the replica by default, Postgres store
+// shares them, so limits hold across replicas.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+
+	"eapteka/apierr"
+	"eapteka/metrics"
+	"eapteka/reqctx"
+)
+
+// Limit is the budget of the route.
+type Limit struct {
+	// Rate is the number of tokens added to the bucket per second.
+	Rate float64
+
+	// Burst is the capacity of the bucket, the number of requests allowed
+	// at once.
+	Burst int
+}
+
+// PerMinute returns limit of n requests per minute with the given burst.
+func PerMinute(n, burst int) Limit {
+	return Limit{Rate: float64(n) / 60, Burst: burst}
+}
+
+// wait returns time until the bucket with the given tokens has a whole
+// token.
+func (l Limit) wait(tokens float64) time.Duration {
+	return time.Duration((1 - tokens) / l.Rate * float64(time.Second))
+}
+
+// refill returns time until the bucket with the given tokens is full.
+func (l Limit) refill(tokens float64) time.Duration {
+	return time.Duration((float64(l.Burst) - tokens) / l.Rate *
+		float64(time.Second))
+}
+
+// Store keeps token buckets.
+type Store interface {
+	// Take takes a token from the bucket of the key. It returns zero if
+	// the token is taken or time until the bucket has one otherwise.
+	Take(ctx context.Context, key string, l Limit) (time.Duration, error)
+}
+
+// KeyFunc returns keys of the client the request is limited by. The
+// request takes a token from the bucket of every key.
+type KeyFunc func(c *fiber.Ctx) []string
+
+// IP keys requests by client IP, see fiber.Config.ProxyHeader for
+// services behind load balancer.
+func IP(c *fiber.Ctx) []string {
+	return []string{ip(c)}
+}
+
+func ip(c *fiber.Ctx) string {
+	return "ip:" + c.IP()
+}
+
+// UserOrIP keys requests by user_id query parameter and by client IP. The
+// user isn't authenticated, so clients varying user_id are limited by IP
+// as well.
+func UserOrIP(c *fiber.Ctx) []string {
+	if u := c.Query("user_id"); u != "" {
+		return []string{"user:" + u, ip(c)}
+	}
+	return IP(c)
+}
+
+type Config struct {
+	// Name of the budget, prefix of keys and label of metrics.
+	Name string
+
+	Limit Limit
+
+	// Key returns keys of the client, IP by default.
+	Key KeyFunc
+
+	// Store keeps buckets, memory of the replica by default.
+	Store Store
+}
+
+var ConfigDefault = Config{
+	Name:  "default",
+	Limit: PerMinute(60, 10),
+	Key:   IP,
+}
+
+// New returns middleware limiting request rate of the route. Requests are
+// let through if the store fails, so its outage doesn't take the API down.
+func New(cfg Config) fiber.Handler {
+	if cfg.Name == "" {
+		cfg.Name = ConfigDefault.Name
+	}
+	if cfg.Limit.Rate == 0 {
+		cfg.Limit.Rate = ConfigDefault.Limit.Rate
+	}
+	if cfg.Limit.Burst == 0 {
+		cfg.Limit.Burst = ConfigDefault.Limit.Burst
+	}
+	if cfg.Key == nil {
+		cfg.Key = ConfigDefault.Key
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewMemory(MemoryConfig{})
+	}
+
+	limited := metrics.RateLimited.WithLabelValues(cfg.Name)
+
+	return func(c *fiber.Ctx) error {
+		// Tokens are taken from every bucket, even if one of them is
+		// empty, so the rejected request still counts against the others
+		var wait time.Duration
+
+		for _, k := range cfg.Key(c) {
+			w, err := cfg.Store.Take(reqctx.Get(c), cfg.Name+":"+k, cfg.Limit)
+			if err != nil {
+				logrus.WithError(err).WithField("limit", cfg.Name).
+					Warn("failed to take rate limit token")
+				continue
+			}
+			if w > wait {
+				wait = w
+			}
+		}
+
+		if wait > 0 {
+			limited.Inc()
+			c.Set(fiber.HeaderRetryAfter, retryAfter(wait))
+			return apierr.RateLimited("too many requests, retry later")
+		}
+
+		return c.Next()
+	}
+}
+
+// retryAfter returns Retry-After header value of the wait, whole seconds
+// rounded up.
+func retryAfter(wait time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(wait.Seconds())))
+}
@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"eapteka/apierr"
+)
+
+func TestUserOrIP(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: apierr.Handler})
+	app.Get("/", New(Config{
+		Name:  "test",
+		Limit: Limit{Rate: 1e-6, Burst: 2},
+		Key:   UserOrIP,
+	}), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	// Requests are sent by one IP
+	tests := []struct {
+		name   string
+		query  string
+		status int
+	}{
+		{"first user", "?user_id=u1", fiber.StatusNoContent},
+		{"first user again", "?user_id=u1", fiber.StatusNoContent},
+		{"first user limited", "?user_id=u1", fiber.StatusTooManyRequests},
+		{"other user limited by ip", "?user_id=u2", fiber.StatusTooManyRequests},
+		{"no user limited by ip", "", fiber.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		resp, err := app.Test(httptest.NewRequest("GET", "/"+tt.query, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, resp.StatusCode,
+				tt.status)
+		}
+		if tt.status == fiber.StatusTooManyRequests &&
+			resp.Header.Get(fiber.HeaderRetryAfter) == "" {
+			t.Errorf("%s: Retry-After isn't set", tt.name)
+		}
+	}
+}
+
+func TestMemorySweep(t *testing.T) {
+	m := NewMemory(MemoryConfig{})
+	l := Limit{Rate: 1, Burst: 10}
+
+	for _, k := range []string{"idle", "drained", "used"} {
+		_, err := m.Take(context.Background(), k, l)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 20; i++ {
+		m.Take(context.Background(), "drained", l)
+	}
+
+	// idle is refilled in a second, used is taken again later
+	now := time.Now().Add(5 * time.Second)
+	m.buckets["used"].full = now.Add(time.Second)
+
+	m.sweep(now)
+
+	tests := []struct {
+		key  string
+		kept bool
+	}{
+		{"idle", false},
+		{"drained", true},
+		{"used", true},
+	}
+
+	for _, tt := range tests {
+		if _, ok := m.buckets[tt.key]; ok != tt.kept {
+			t.Errorf("bucket %s kept = %v, want %v", tt.key, ok, tt.kept)
+		}
+	}
+
+	m.sweep(now.Add(m.cfg.Expire))
+	if len(m.buckets) != 0 {
+		t.Errorf("%d buckets kept after Expire, want 0", len(m.buckets))
+	}
+}
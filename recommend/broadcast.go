@@ -0,0 +1,126 @@
+package recommend
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"eapteka/hub"
+	"eapteka/metrics"
+	"eapteka/tracing"
+)
+
+const topicPrefix = "recommendations:"
+
+// Topic returns hub topic of the user recommendations.
+func Topic(userID string) string {
+	return topicPrefix + userID
+}
+
+// Broadcaster periodically sends one of the recommendations to every user
+// with clients connected to this replica.
+type Broadcaster struct {
+	engine *Engine
+	hub    *hub.Hub
+	tick   time.Duration
+
+	running int32
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	close chan struct{}
+	wg    sync.WaitGroup
+}
+
+func NewBroadcaster(e *Engine, h *hub.Hub, tick time.Duration) *Broadcaster {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Broadcaster{
+		engine: e,
+		hub:    h,
+		tick:   tick,
+		ctx:    ctx,
+		cancel: cancel,
+		close:  make(chan struct{}),
+	}
+}
+
+func (b *Broadcaster) Start() {
+	atomic.StoreInt32(&b.running, 1)
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		defer atomic.StoreInt32(&b.running, 0)
+
+		t := time.NewTicker(b.tick)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-b.close:
+				return
+			case <-t.C:
+			}
+
+			b.broadcast()
+		}
+	}()
+}
+
+func (b *Broadcaster) Stop() {
+	close(b.close)
+	b.cancel()
+	b.wg.Wait()
+}
+
+// Running reports whether the broadcast loop is running.
+func (b *Broadcaster) Running() bool {
+	return atomic.LoadInt32(&b.running) == 1
+}
+
+func (b *Broadcaster) broadcast() {
+	var users []string
+	for _, t := range b.hub.Topics() {
+		if strings.HasPrefix(t, topicPrefix) {
+			users = append(users, strings.TrimPrefix(t, topicPrefix))
+		}
+	}
+
+	if len(users) == 0 {
+		return
+	}
+
+	ctx, span := tracing.Start(b.ctx, "recommend broadcast")
+	defer span.End()
+
+	start := time.Now()
+
+	rs, err := b.engine.RecommendUsers(ctx, users)
+	metrics.RecommendationDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.RecommendationRuns.WithLabelValues("error").Inc()
+		span.RecordError(err)
+		logrus.WithError(err).Error("failed to get recommendations")
+		return
+	}
+
+	metrics.RecommendationRuns.WithLabelValues("ok").Inc()
+
+	for userID, urs := range rs {
+		if len(urs) == 0 {
+			continue
+		}
+
+		err = b.hub.Broadcast(Topic(userID), urs[rand.Intn(len(urs))])
+		if err != nil {
+			logrus.WithError(err).Error("failed to broadcast recommendation")
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package recommend
+
+import (
+	"time"
+
+	"eapteka/ent"
+)
+
+const ReasonBoughtTogether = "bought_together"
+
+// CoPurchase recommends products other users bought together with the
+// products the user bought recently.
+type CoPurchase struct {
+	// Recent is the age of the user purchases recommendations are based on.
+	Recent time.Duration
+
+	// MinSupport is the minimum number of purchases containing both
+	// products.
+	MinSupport int
+}
+
+var CoPurchaseDefault = CoPurchase{
+	Recent:     30 * 24 * time.Hour,
+	MinSupport: 2,
+}
+
+func NewCoPurchase(c CoPurchase) CoPurchase {
+	if c.Recent == 0 {
+		c.Recent = CoPurchaseDefault.Recent
+	}
+	if c.MinSupport == 0 {
+		c.MinSupport = CoPurchaseDefault.MinSupport
+	}
+	return c
+}
+
+// Recommend returns products which are not in the user recent purchases
+// scored by confidence of buying them together with a recent product: the
+// share of purchases with the recent product which contain the recommended
+// one too.
+func (c CoPurchase) Recommend(history []Purchase, userID string,
+	now time.Time) []ent.Recommendation {
+
+	recent := map[int64]bool{}
+
+	for _, pu := range history {
+		if pu.UserID == userID && now.Sub(pu.CreatedAt) <= c.Recent {
+			for _, pID := range pu.ProductIDs {
+				recent[pID] = true
+			}
+		}
+	}
+
+	if len(recent) == 0 {
+		return nil
+	}
+
+	var (
+		count    = map[int64]int{}
+		together = map[int64]map[int64]int{}
+	)
+
+	for _, pu := range history {
+		for _, a := range pu.ProductIDs {
+			if !recent[a] {
+				continue
+			}
+			count[a]++
+			for _, b := range pu.ProductIDs {
+				if b == a || recent[b] {
+					continue
+				}
+				if together[a] == nil {
+					together[a] = map[int64]int{}
+				}
+				together[a][b]++
+			}
+		}
+	}
+
+	best := map[int64]float64{}
+
+	for a, bs := range together {
+		for b, n := range bs {
+			if n < c.MinSupport {
+				continue
+			}
+			score := float64(n) / float64(count[a])
+			if score > best[b] {
+				best[b] = score
+			}
+		}
+	}
+
+	rs := make([]ent.Recommendation, 0, len(best))
+	for pID, score := range best {
+		rs = append(rs, ent.Recommendation{
+			ProductID: pID,
+			Score:     score,
+			Reason:    ReasonBoughtTogether,
+		})
+	}
+
+	return rs
+}
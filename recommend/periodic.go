@@ -0,0 +1,121 @@
+package recommend
+
+import (
+	"time"
+
+	"eapteka/ent"
+)
+
+const ReasonPeriodic = "periodic"
+
+// Periodic recommends products the user buys regularly, e.g. monthly, when
+// the next purchase is due.
+type Periodic struct {
+	// MinInterval and MaxInterval bound interval between purchases
+	// considered regular.
+	MinInterval time.Duration
+	MaxInterval time.Duration
+
+	// SameVisit is the interval in which purchases of the product are
+	// counted as one.
+	SameVisit time.Duration
+
+	// MinRepeats is the number of regular intervals ending with the last
+	// purchase required to recommend the product.
+	MinRepeats int
+
+	// Lead is the time before the next purchase is due the product is
+	// recommended.
+	Lead time.Duration
+}
+
+var PeriodicDefault = Periodic{
+	MinInterval: 25 * 24 * time.Hour,
+	MaxInterval: 35 * 24 * time.Hour,
+	SameVisit:   24 * time.Hour,
+	MinRepeats:  2,
+	Lead:        3 * 24 * time.Hour,
+}
+
+func NewPeriodic(p Periodic) Periodic {
+	if p.MinInterval == 0 {
+		p.MinInterval = PeriodicDefault.MinInterval
+	}
+	if p.MaxInterval == 0 {
+		p.MaxInterval = PeriodicDefault.MaxInterval
+	}
+	if p.SameVisit == 0 {
+		p.SameVisit = PeriodicDefault.SameVisit
+	}
+	if p.MinRepeats == 0 {
+		p.MinRepeats = PeriodicDefault.MinRepeats
+	}
+	if p.Lead == 0 {
+		p.Lead = PeriodicDefault.Lead
+	}
+	return p
+}
+
+// Recommend returns products with at least MinRepeats regular intervals
+// between the user's latest purchases, if the next purchase is due within
+// Lead and the user hasn't missed it by more than MaxInterval. Score is the
+// share of regular intervals in the product history.
+func (p Periodic) Recommend(history []Purchase, userID string,
+	now time.Time) []ent.Recommendation {
+
+	visits := map[int64][]time.Time{}
+
+	for _, pu := range history {
+		if pu.UserID != userID {
+			continue
+		}
+		for _, pID := range pu.ProductIDs {
+			ts := visits[pID]
+			if len(ts) > 0 && pu.CreatedAt.Sub(ts[len(ts)-1]) < p.SameVisit {
+				continue
+			}
+			visits[pID] = append(ts, pu.CreatedAt)
+		}
+	}
+
+	var rs []ent.Recommendation
+
+	for pID, ts := range visits {
+		if len(ts) < p.MinRepeats+1 {
+			continue
+		}
+
+		var (
+			repeats int
+			sum     time.Duration
+		)
+
+		// Count regular intervals back from the last purchase
+		for i := len(ts) - 1; i > 0; i-- {
+			interval := ts[i].Sub(ts[i-1])
+			if interval < p.MinInterval || interval > p.MaxInterval {
+				break
+			}
+			repeats++
+			sum += interval
+		}
+
+		if repeats < p.MinRepeats {
+			continue
+		}
+
+		due := ts[len(ts)-1].Add(sum / time.Duration(repeats))
+
+		if now.Before(due.Add(-p.Lead)) || now.After(due.Add(p.MaxInterval)) {
+			continue
+		}
+
+		rs = append(rs, ent.Recommendation{
+			ProductID: pID,
+			Score:     float64(repeats) / float64(len(ts)-1),
+			Reason:    ReasonPeriodic,
+		})
+	}
+
+	return rs
+}
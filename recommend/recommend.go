@@ -0,0 +1,304 @@
+// Package recommend suggests products to users by their purchase history.
+// Strategies implement Recommender and work on purchases loaded in memory,
+// Engine keeps the history reloaded periodically and merges results of
+// several strategies.
+package recommend
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+
+	"eapteka/ent"
+	"eapteka/tracing"
+)
+
+// Purchase is the purchase of the history with its distinct products.
+type Purchase struct {
+	ID         int64
+	UserID     string
+	CreatedAt  time.Time
+	ProductIDs []int64
+}
+
+// Recommender returns products to recommend to the user by the history of
+// purchases of every user sorted by time.
+type Recommender interface {
+	Recommend(history []Purchase, userID string, now time.Time) []ent.Recommendation
+}
+
+type Config struct {
+	// Window is the age of the oldest purchase of the history.
+	Window time.Duration
+
+	// Limit is the maximum number of recommendations.
+	Limit int
+
+	// Reload is the interval of reloading the history, purchases made
+	// meanwhile aren't taken into account.
+	Reload time.Duration
+}
+
+var ConfigDefault = Config{
+	Window: 180 * 24 * time.Hour,
+	Limit:  10,
+	Reload: 10 * time.Minute,
+}
+
+// Engine merges recommendations of the strategies keeping the best score
+// of every product. The history is loaded by Reload and kept in memory, so
+// requests don't load it.
+type Engine struct {
+	db         *sqlx.DB
+	cfg        Config
+	strategies []Recommender
+
+	history []Purchase
+	mx      sync.RWMutex
+
+	running int32
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	close chan struct{}
+	wg    sync.WaitGroup
+}
+
+func New(db *sqlx.DB, cfg Config, strategies ...Recommender) *Engine {
+	if cfg.Window == 0 {
+		cfg.Window = ConfigDefault.Window
+	}
+	if cfg.Limit == 0 {
+		cfg.Limit = ConfigDefault.Limit
+	}
+	if cfg.Reload == 0 {
+		cfg.Reload = ConfigDefault.Reload
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Engine{
+		db:         db,
+		cfg:        cfg,
+		strategies: strategies,
+		ctx:        ctx,
+		cancel:     cancel,
+		close:      make(chan struct{}),
+	}
+}
+
+// Start reloads the history every Reload interval. The history should be
+// loaded by Reload before.
+func (e *Engine) Start() {
+	atomic.StoreInt32(&e.running, 1)
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		defer atomic.StoreInt32(&e.running, 0)
+
+		t := time.NewTicker(e.cfg.Reload)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-e.close:
+				return
+			case <-t.C:
+			}
+
+			// Recommendations are made by the previous history until
+			// the next reload
+			err := e.Reload(e.ctx)
+			if err != nil {
+				logrus.WithError(err).Error("failed to reload purchase history")
+			}
+		}
+	}()
+}
+
+func (e *Engine) Stop() {
+	close(e.close)
+	e.cancel()
+	e.wg.Wait()
+}
+
+// Running reports whether the reload loop is running.
+func (e *Engine) Running() bool {
+	return atomic.LoadInt32(&e.running) == 1
+}
+
+// Reload loads purchases made within Window.
+func (e *Engine) Reload(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "reload purchase history")
+	defer span.End()
+
+	history, err := Load(ctx, e.db, time.Now().Add(-e.cfg.Window))
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	e.mx.Lock()
+	e.history = history
+	e.mx.Unlock()
+
+	return nil
+}
+
+// Recommend returns recommendations for the user with products, best first.
+func (e *Engine) Recommend(ctx context.Context, userID string) (
+	[]ent.Recommendation, error) {
+
+	rs, err := e.RecommendUsers(ctx, []string{userID})
+	if err != nil {
+		return nil, err
+	}
+	return rs[userID], nil
+}
+
+// RecommendUsers returns recommendations for every user by the loaded
+// history.
+func (e *Engine) RecommendUsers(ctx context.Context, userIDs []string) (
+	map[string][]ent.Recommendation, error) {
+
+	ctx, span := tracing.Start(ctx, "recommend users")
+	defer span.End()
+
+	now := time.Now()
+
+	// History is replaced by Reload, not changed, so it's read unlocked
+	e.mx.RLock()
+	history := e.history
+	e.mx.RUnlock()
+
+	var (
+		rs  = map[string][]ent.Recommendation{}
+		pID = map[int64]bool{}
+	)
+
+	for _, userID := range userIDs {
+		urs := Merge(e.cfg.Limit, history, userID, now, e.strategies...)
+		for _, r := range urs {
+			pID[r.ProductID] = true
+		}
+		rs[userID] = urs
+	}
+
+	if len(pID) == 0 {
+		return rs, nil
+	}
+
+	pIDs := make([]int64, 0, len(pID))
+	for id := range pID {
+		pIDs = append(pIDs, id)
+	}
+
+	var ps []ent.Product
+
+	err := e.db.SelectContext(ctx, &ps, `
+		select p.id as id, p.name as name, description, price, image_id, sku,
+		       pack_size, s.name as substance_name, s.id as substance_id
+		from product p
+		    left join substance s on p.substance_id = s.id
+		where p.id = any($1)
+	`, pq.Array(pIDs))
+	if err != nil {
+		return nil, fmt.Errorf("select products: %w", err)
+	}
+
+	byID := map[int64]ent.Product{}
+	for _, p := range ps {
+		byID[p.ID] = p
+	}
+
+	for _, urs := range rs {
+		for i := range urs {
+			if p, ok := byID[urs[i].ProductID]; ok {
+				urs[i].Product = &p
+			}
+		}
+	}
+
+	return rs, nil
+}
+
+// Load returns purchases created since the given time sorted by time.
+func Load(ctx context.Context, db *sqlx.DB, since time.Time) ([]Purchase,
+	error) {
+
+	var rows []struct {
+		ID         int64         `db:"id"`
+		UserID     string        `db:"user_id"`
+		CreatedAt  time.Time     `db:"created_at"`
+		ProductIDs pq.Int64Array `db:"product_ids"`
+	}
+
+	err := db.SelectContext(ctx, &rows, `
+		select p.id as id, user_id, created_at,
+		       array_agg(distinct pp.product_id) as product_ids
+		from purchase as p
+		    join purchase_product as pp on p.id = pp.purchase_id
+		where created_at >= $1
+		group by p.id
+		order by created_at, p.id
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("select purchases: %w", err)
+	}
+
+	ps := make([]Purchase, 0, len(rows))
+	for _, r := range rows {
+		ps = append(ps, Purchase{
+			ID:         r.ID,
+			UserID:     r.UserID,
+			CreatedAt:  r.CreatedAt,
+			ProductIDs: r.ProductIDs,
+		})
+	}
+
+	return ps, nil
+}
+
+// Merge returns up to limit recommendations of the strategies sorted by
+// score, the best scored reason is kept for products recommended several
+// times.
+func Merge(limit int, history []Purchase, userID string, now time.Time,
+	strategies ...Recommender) []ent.Recommendation {
+
+	best := map[int64]ent.Recommendation{}
+
+	for _, s := range strategies {
+		for _, r := range s.Recommend(history, userID, now) {
+			if b, ok := best[r.ProductID]; !ok || r.Score > b.Score {
+				best[r.ProductID] = r
+			}
+		}
+	}
+
+	rs := make([]ent.Recommendation, 0, len(best))
+	for _, r := range best {
+		rs = append(rs, r)
+	}
+
+	sort.Slice(rs, func(i, j int) bool {
+		if rs[i].Score != rs[j].Score {
+			return rs[i].Score > rs[j].Score
+		}
+		return rs[i].ProductID < rs[j].ProductID
+	})
+
+	if limit > 0 && len(rs) > limit {
+		rs = rs[:limit]
+	}
+
+	return rs
+}
@@ -0,0 +1,224 @@
+package recommend
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"eapteka/ent"
+)
+
+var now = time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// daysAgo returns time of the purchase made days before now.
+func daysAgo(days int) time.Time {
+	return now.AddDate(0, 0, -days)
+}
+
+// fixture is the purchase history of several users sorted by time:
+//   - u1 recently bought product 1, which others buy with 2 and 3;
+//   - u4 bought 4 and 5 together once;
+//   - u5 buys 7, 9 and 11 monthly, 8 irregularly and stopped buying 10.
+var fixture = sortHistory([]Purchase{
+	{ID: 1, UserID: "u2", CreatedAt: daysAgo(60), ProductIDs: []int64{1, 2}},
+	{ID: 2, UserID: "u3", CreatedAt: daysAgo(50), ProductIDs: []int64{1, 2, 3}},
+	{ID: 3, UserID: "u2", CreatedAt: daysAgo(40), ProductIDs: []int64{1, 3}},
+	{ID: 4, UserID: "u4", CreatedAt: daysAgo(20), ProductIDs: []int64{4, 5}},
+	{ID: 5, UserID: "u1", CreatedAt: daysAgo(10), ProductIDs: []int64{1}},
+	{ID: 6, UserID: "u3", CreatedAt: daysAgo(5), ProductIDs: []int64{1, 2}},
+
+	{ID: 10, UserID: "u5", CreatedAt: daysAgo(150), ProductIDs: []int64{10}},
+	{ID: 11, UserID: "u5", CreatedAt: daysAgo(140), ProductIDs: []int64{9}},
+	{ID: 12, UserID: "u5", CreatedAt: daysAgo(130), ProductIDs: []int64{9}},
+	{ID: 13, UserID: "u5", CreatedAt: daysAgo(120), ProductIDs: []int64{10}},
+	{ID: 14, UserID: "u5", CreatedAt: daysAgo(100), ProductIDs: []int64{9}},
+	{ID: 15, UserID: "u5", CreatedAt: daysAgo(90), ProductIDs: []int64{10}},
+	{ID: 16, UserID: "u5", CreatedAt: daysAgo(88), ProductIDs: []int64{7, 8, 11}},
+	{ID: 17, UserID: "u5", CreatedAt: daysAgo(88).Add(2 * time.Hour), ProductIDs: []int64{11}},
+	{ID: 18, UserID: "u5", CreatedAt: daysAgo(80), ProductIDs: []int64{8}},
+	{ID: 19, UserID: "u5", CreatedAt: daysAgo(70), ProductIDs: []int64{9}},
+	{ID: 20, UserID: "u5", CreatedAt: daysAgo(58), ProductIDs: []int64{7, 11}},
+	{ID: 21, UserID: "u5", CreatedAt: daysAgo(40), ProductIDs: []int64{9}},
+	{ID: 22, UserID: "u5", CreatedAt: daysAgo(28), ProductIDs: []int64{7, 8, 11}},
+})
+
+func sortHistory(ps []Purchase) []Purchase {
+	sort.Slice(ps, func(i, j int) bool {
+		return ps[i].CreatedAt.Before(ps[j].CreatedAt)
+	})
+	return ps
+}
+
+// scores returns scores of the recommendations by product.
+func scores(t *testing.T, rs []ent.Recommendation, reason string) map[int64]float64 {
+	t.Helper()
+
+	s := map[int64]float64{}
+	for _, r := range rs {
+		if r.Reason != reason {
+			t.Errorf("product %d reason = %q, want %q", r.ProductID, r.Reason,
+				reason)
+		}
+		s[r.ProductID] = r.Score
+	}
+	return s
+}
+
+func equalScores(a, b map[int64]float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for pID, s := range a {
+		if d := s - b[pID]; d > 1e-9 || d < -1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCoPurchase(t *testing.T) {
+	tests := []struct {
+		name   string
+		c      CoPurchase
+		userID string
+		want   map[int64]float64
+	}{{
+		name:   "bought together",
+		c:      NewCoPurchase(CoPurchase{}),
+		userID: "u1",
+		want:   map[int64]float64{2: 3.0 / 5, 3: 2.0 / 5},
+	}, {
+		name:   "min support",
+		c:      NewCoPurchase(CoPurchase{MinSupport: 3}),
+		userID: "u1",
+		want:   map[int64]float64{2: 3.0 / 5},
+	}, {
+		name:   "recent products excluded",
+		c:      NewCoPurchase(CoPurchase{}),
+		userID: "u3",
+		want:   map[int64]float64{3: 2.0 / 5},
+	}, {
+		name:   "old purchases ignored",
+		c:      NewCoPurchase(CoPurchase{Recent: 24 * time.Hour}),
+		userID: "u1",
+		want:   map[int64]float64{},
+	}, {
+		name:   "below support",
+		c:      NewCoPurchase(CoPurchase{}),
+		userID: "u4",
+		want:   map[int64]float64{},
+	}, {
+		name:   "unknown user",
+		c:      NewCoPurchase(CoPurchase{}),
+		userID: "u0",
+		want:   map[int64]float64{},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scores(t, tt.c.Recommend(fixture, tt.userID, now),
+				ReasonBoughtTogether)
+			if !equalScores(got, tt.want) {
+				t.Errorf("Recommend() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPeriodic(t *testing.T) {
+	tests := []struct {
+		name   string
+		p      Periodic
+		userID string
+		now    time.Time
+		want   map[int64]float64
+	}{{
+		// 7 and 11 are due in 2 days, 11 bought twice in one visit; 9 was
+		// due 10 days ago after 3 regular intervals of 4
+		name:   "due",
+		p:      NewPeriodic(Periodic{}),
+		userID: "u5",
+		now:    now,
+		want:   map[int64]float64{7: 1, 9: 3.0 / 4, 11: 1},
+	}, {
+		name:   "not due yet",
+		p:      NewPeriodic(Periodic{}),
+		userID: "u5",
+		now:    now.AddDate(0, 0, -5),
+		want:   map[int64]float64{9: 3.0 / 4},
+	}, {
+		name:   "more repeats required",
+		p:      NewPeriodic(Periodic{MinRepeats: 3}),
+		userID: "u5",
+		now:    now,
+		want:   map[int64]float64{9: 3.0 / 4},
+	}, {
+		name:   "other user",
+		p:      NewPeriodic(Periodic{}),
+		userID: "u1",
+		now:    now,
+		want:   map[int64]float64{},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scores(t, tt.p.Recommend(fixture, tt.userID, tt.now),
+				ReasonPeriodic)
+			if !equalScores(got, tt.want) {
+				t.Errorf("Recommend() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fixed recommends the same products to everyone.
+type fixed []ent.Recommendation
+
+func (f fixed) Recommend([]Purchase, string, time.Time) []ent.Recommendation {
+	return f
+}
+
+func TestMerge(t *testing.T) {
+	other := fixed{
+		{ProductID: 2, Score: 0.5, Reason: "other"},
+		{ProductID: 3, Score: 0.9, Reason: "other"},
+		{ProductID: 6, Score: 0.4, Reason: "other"},
+	}
+
+	tests := []struct {
+		name  string
+		limit int
+		want  []ent.Recommendation
+	}{{
+		name: "best score kept",
+		want: []ent.Recommendation{
+			{ProductID: 3, Score: 0.9, Reason: "other"},
+			{ProductID: 2, Score: 0.6, Reason: ReasonBoughtTogether},
+			{ProductID: 6, Score: 0.4, Reason: "other"},
+		},
+	}, {
+		name:  "limit",
+		limit: 2,
+		want: []ent.Recommendation{
+			{ProductID: 3, Score: 0.9, Reason: "other"},
+			{ProductID: 2, Score: 0.6, Reason: ReasonBoughtTogether},
+		},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Merge(tt.limit, fixture, "u1", now,
+				NewCoPurchase(CoPurchase{}), other)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Merge() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				g, w := got[i], tt.want[i]
+				if g.ProductID != w.ProductID || g.Reason != w.Reason ||
+					g.Score-w.Score > 1e-9 || w.Score-g.Score > 1e-9 {
+					t.Errorf("Merge()[%d] = %+v, want %+v", i, g, w)
+				}
+			}
+		})
+	}
+}
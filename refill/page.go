@@ -0,0 +1,101 @@
+package refill
+
+import (
+	"errors"
+	"html/template"
+
+	"github.com/gofiber/fiber/v2"
+
+	"eapteka/apierr"
+	"eapteka/ent"
+	"eapteka/reqctx"
+)
+
+var reorderPage = template.Must(template.New("reorder").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>Повторный заказ</title>
+</head>
+<body>
+{{- with .Purchase}}
+  <h1>Заказ №{{.ID}} оформлен</h1>
+  <ul>
+  {{- range .Products}}
+    <li>{{.Name}} — {{.Count}} шт.</li>
+  {{- end}}
+  </ul>
+{{- else}}
+  {{- with .Refill}}
+  <h1>{{.ProductName}}</h1>
+  <p>Лекарство закончится {{.RunOutAt.Format "02.01.2006"}}.</p>
+  <form method="post">
+    <button type="submit">Заказать ещё</button>
+  </form>
+  {{- end}}
+{{- end}}
+</body>
+</html>
+`))
+
+type reorderPageData struct {
+	Refill   ent.Refill
+	Purchase *ent.Purchase
+}
+
+// ConfirmHandler serves the page linked from refill reminders: it shows the
+// refill with a button posting back to ReorderHandler, so link previews and
+// mail scanners opening the link don't order anything. Already reordered
+// refill shows its purchase.
+func (p *Predictor) ConfirmHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return apierr.InvalidField("id", err)
+		}
+
+		r, err := p.Refill(reqctx.Get(c), int64(id))
+		if err != nil {
+			if errors.Is(err, ErrRefillNotFound) {
+				return apierr.NotFound(err.Error())
+			}
+			return err
+		}
+
+		d := reorderPageData{Refill: r}
+
+		if r.ReorderPurchaseID != nil {
+			pu, err := p.Reorder(reqctx.Get(c), r.ID)
+			if err != nil {
+				return err
+			}
+			d.Purchase = &pu
+		}
+
+		c.Type("html")
+		return reorderPage.Execute(c, d)
+	}
+}
+
+// ReorderHandler reorders the refill from the confirm page form and shows
+// the purchase.
+func (p *Predictor) ReorderHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return apierr.InvalidField("id", err)
+		}
+
+		pu, err := p.Reorder(reqctx.Get(c), int64(id))
+		if err != nil {
+			if errors.Is(err, ErrRefillNotFound) {
+				return apierr.NotFound(err.Error())
+			}
+			return err
+		}
+
+		c.Type("html")
+		return reorderPage.Execute(c, reorderPageData{Purchase: &pu})
+	}
+}
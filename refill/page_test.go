@@ -0,0 +1,54 @@
+package refill
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"eapteka/ent"
+)
+
+func TestReorderPage(t *testing.T) {
+	r := ent.Refill{ID: 1, ProductName: "Парацетамол",
+		RunOutAt: time.Date(2021, 6, 3, 8, 0, 0, 0, time.UTC)}
+	pu := ent.Purchase{ID: 7, Products: []ent.Product{
+		{Name: "Парацетамол", Count: 2}}}
+
+	tests := []struct {
+		name    string
+		data    reorderPageData
+		want    []string
+		notWant []string
+	}{{
+		name:    "confirm",
+		data:    reorderPageData{Refill: r},
+		want:    []string{"Парацетамол", "03.06.2021", `<form method="post">`},
+		notWant: []string{"Заказ №"},
+	}, {
+		name:    "reordered",
+		data:    reorderPageData{Refill: r, Purchase: &pu},
+		want:    []string{"Заказ №7 оформлен", "Парацетамол — 2 шт."},
+		notWant: []string{"<form"},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			err := reorderPage.Execute(&b, tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(b.String(), s) {
+					t.Errorf("page doesn't contain %q:\n%s", s, b.String())
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(b.String(), s) {
+					t.Errorf("page contains %q:\n%s", s, b.String())
+				}
+			}
+		})
+	}
+}
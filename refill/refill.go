@@ -0,0 +1,430 @@
+// Package refill predicts when users run out of products they take by
+// notifiers and reminds them to reorder beforehand.
+package refill
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+
+	"eapteka/ent"
+	"eapteka/scheduler"
+)
+
+// maxDoses bounds doses simulated while looking for the run out time.
+const maxDoses = 10000
+
+var ErrRefillNotFound = errors.New("refill not found")
+
+const topicPrefix = "refills:"
+
+// Topic returns hub topic of the user refills.
+func Topic(userID string) string {
+	return topicPrefix + userID
+}
+
+type Config struct {
+	// Tick is the interval of predicting run out times.
+	Tick time.Duration
+
+	// Lead is the time before running out the user is reminded to refill.
+	Lead time.Duration
+
+	// Horizon limits how far notifier schedules are simulated.
+	Horizon time.Duration
+
+	// OnRefill is called for every created refill in the transaction which
+	// creates it, so the refill is created only if OnRefill succeeds. It is
+	// used to enqueue deliveries and publish the refill to clients.
+	OnRefill func(ctx context.Context, tx *sqlx.Tx, r ent.Refill) error
+}
+
+var ConfigDefault = Config{
+	Tick:    time.Hour,
+	Lead:    3 * 24 * time.Hour,
+	Horizon: 180 * 24 * time.Hour,
+}
+
+// Predictor estimates remaining supply of the product as pack sizes of the
+// user purchases minus doses of reminders fired since the first purchase,
+// and simulates notifier schedules to find the first dose the supply
+// doesn't cover. Refill is stored with unique (user_id, product_id,
+// purchase_id), so the user is reminded once per last purchase even if
+// several replicas run predictors.
+type Predictor struct {
+	db  *sqlx.DB
+	cfg Config
+
+	running int32
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	close chan struct{}
+	wg    sync.WaitGroup
+}
+
+func New(db *sqlx.DB, cfg Config) *Predictor {
+	if cfg.Tick == 0 {
+		cfg.Tick = ConfigDefault.Tick
+	}
+	if cfg.Lead == 0 {
+		cfg.Lead = ConfigDefault.Lead
+	}
+	if cfg.Horizon == 0 {
+		cfg.Horizon = ConfigDefault.Horizon
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Predictor{
+		db:     db,
+		cfg:    cfg,
+		ctx:    ctx,
+		cancel: cancel,
+		close:  make(chan struct{}),
+	}
+}
+
+func (p *Predictor) Start() {
+	atomic.StoreInt32(&p.running, 1)
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer atomic.StoreInt32(&p.running, 0)
+		p.checkLoop()
+	}()
+}
+
+func (p *Predictor) Stop() {
+	close(p.close)
+	p.cancel()
+	p.wg.Wait()
+}
+
+// Running reports whether the check loop is running.
+func (p *Predictor) Running() bool {
+	return atomic.LoadInt32(&p.running) == 1
+}
+
+type supplyNotifier struct {
+	ent.Notifier
+	Fired int `db:"fired"`
+}
+
+// Predict returns supply predictions of products the user takes by
+// notifiers with dose and bought at least once.
+func (p *Predictor) Predict(ctx context.Context, userID string) (
+	[]ent.RefillPrediction, error) {
+
+	var ns []supplyNotifier
+
+	err := p.db.SelectContext(ctx, &ns, `
+		select n.id as id, n.user_id as user_id, product_id, schedule, rule,
+		       dose_amount, dose_unit, notes, paused, p.name as product_name,
+		       (select count(*) from reminder r where r.notifier_id = n.id) as fired
+		from notifier n
+		    join product p on p.id = n.product_id
+		where n.user_id = $1 and not paused and dose_amount > 0 and
+		      p.pack_size > 0
+		order by product_id, n.id
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("select notifiers: %w", err)
+	}
+
+	now := time.Now()
+	ps := []ent.RefillPrediction{}
+
+	for _, g := range byProduct(ns) {
+		pr, ok, err := p.predict(ctx, userID, g, now)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			ps = append(ps, pr)
+		}
+	}
+
+	return ps, nil
+}
+
+// byProduct splits notifiers ordered by product into groups of the same
+// product.
+func byProduct(ns []supplyNotifier) [][]supplyNotifier {
+	var gs [][]supplyNotifier
+
+	for len(ns) > 0 {
+		i := 1
+		for i < len(ns) && ns[i].ProductID == ns[0].ProductID {
+			i++
+		}
+		gs = append(gs, ns[:i])
+		ns = ns[i:]
+	}
+
+	return gs
+}
+
+// predict returns supply prediction of the product taken by given
+// notifiers, false if the user hasn't bought it.
+func (p *Predictor) predict(ctx context.Context, userID string,
+	ns []supplyNotifier, now time.Time) (ent.RefillPrediction, bool, error) {
+
+	pr := ent.RefillPrediction{
+		UserID:      userID,
+		ProductID:   ns[0].ProductID,
+		ProductName: ns[0].ProductName,
+		DoseUnit:    ns[0].DoseUnit,
+	}
+
+	var bought struct {
+		Units      float64    `db:"units"`
+		FirstAt    *time.Time `db:"first_at"`
+		PurchaseID *int64     `db:"purchase_id"`
+	}
+
+	err := p.db.QueryRowxContext(ctx, `
+		select coalesce(sum(pp.count * p.pack_size), 0) as units,
+		       min(pu.created_at) as first_at,
+		       (array_agg(pu.id order by pu.created_at desc))[1] as purchase_id
+		from purchase pu
+		    join purchase_product pp on pp.purchase_id = pu.id
+		    join product p on p.id = pp.product_id
+		where pu.user_id = $1 and pp.product_id = $2
+	`, userID, pr.ProductID).StructScan(&bought)
+	if err != nil {
+		return pr, false, fmt.Errorf("select purchases: %w", err)
+	}
+
+	if bought.FirstAt == nil {
+		return pr, false, nil
+	}
+
+	pr.PurchaseID = *bought.PurchaseID
+
+	nIDs := make([]int64, 0, len(ns))
+	for _, n := range ns {
+		nIDs = append(nIDs, n.ID)
+	}
+
+	var doses []dose
+
+	err = p.db.SelectContext(ctx, &doses, `
+		select r.notifier_id,
+		       exists (
+		           select from reminder_event e
+		           where e.reminder_id = r.id and e.type = 'skipped'
+		       ) as skipped
+		from reminder r
+		where r.notifier_id = any($1) and r.scheduled_at >= $2 and
+		      r.scheduled_at <= $3
+	`, pq.Array(nIDs), *bought.FirstAt, now)
+	if err != nil {
+		return pr, false, fmt.Errorf("select doses: %w", err)
+	}
+
+	pr.Remaining = bought.Units - consumed(ns, doses)
+	if pr.Remaining < 0 {
+		pr.Remaining = 0
+	}
+
+	if t, ok := runOut(ns, pr.Remaining, now, now.Add(p.cfg.Horizon)); ok {
+		pr.RunOutAt = &t
+	}
+
+	return pr, true, nil
+}
+
+// dose is the fired reminder of the notifier.
+type dose struct {
+	NotifierID int64 `db:"notifier_id"`
+	Skipped    bool  `db:"skipped"`
+}
+
+// consumed returns amount taken by the doses of the notifiers, skipped
+// doses aren't taken.
+func consumed(ns []supplyNotifier, doses []dose) float64 {
+	amounts := make(map[int64]float64, len(ns))
+	for _, n := range ns {
+		amounts[n.ID] = n.DoseAmount
+	}
+
+	var c float64
+	for _, d := range doses {
+		if !d.Skipped {
+			c += amounts[d.NotifierID]
+		}
+	}
+
+	return c
+}
+
+// runOut simulates doses of the notifiers after now and returns time of the
+// first one which exceeds the remaining supply, false if there is no such
+// dose until the given time.
+func runOut(ns []supplyNotifier, remaining float64, now,
+	until time.Time) (time.Time, bool) {
+
+	type occurrence struct {
+		next  time.Time
+		ok    bool
+		fired int
+	}
+
+	occs := make([]occurrence, len(ns))
+	for i, n := range ns {
+		occs[i].fired = n.Fired
+		occs[i].next, occs[i].ok = scheduler.Next(n.Schedule, n.Rule, n.Fired,
+			now)
+	}
+
+	for d := 0; d < maxDoses; d++ {
+		earliest := -1
+		for i, o := range occs {
+			if o.ok && (earliest < 0 || o.next.Before(occs[earliest].next)) {
+				earliest = i
+			}
+		}
+
+		if earliest < 0 || occs[earliest].next.After(until) {
+			return time.Time{}, false
+		}
+
+		o := &occs[earliest]
+		n := ns[earliest]
+
+		if remaining < n.DoseAmount {
+			return o.next, true
+		}
+
+		remaining -= n.DoseAmount
+		o.fired++
+		o.next, o.ok = scheduler.Next(n.Schedule, n.Rule, o.fired, o.next)
+	}
+
+	return time.Time{}, false
+}
+
+func (p *Predictor) checkLoop() {
+	t := time.NewTicker(p.cfg.Tick)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-p.close:
+			return
+		case <-t.C:
+		}
+
+		err := p.check(p.ctx, time.Now())
+		if err != nil {
+			logrus.WithError(err).Error("failed to check refills")
+		}
+	}
+}
+
+// check creates refills of products which run out within lead time.
+func (p *Predictor) check(ctx context.Context, now time.Time) error {
+	var userIDs []string
+
+	err := p.db.SelectContext(ctx, &userIDs, `
+		select distinct user_id from notifier where not paused
+	`)
+	if err != nil {
+		return fmt.Errorf("select users: %w", err)
+	}
+
+	// Failure of one user is logged, so it doesn't stop refills of the
+	// others
+	for _, userID := range userIDs {
+		// Pass is interrupted on shutdown
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		ps, err := p.Predict(ctx, userID)
+		if err != nil {
+			logrus.WithError(err).WithField("user_id", userID).
+				Error("failed to predict refills")
+			continue
+		}
+
+		for _, pr := range ps {
+			if pr.RunOutAt == nil || pr.RunOutAt.Sub(now) > p.cfg.Lead {
+				continue
+			}
+
+			err = p.create(ctx, pr)
+			if err != nil {
+				logrus.WithError(err).WithFields(logrus.Fields{
+					"user_id":    userID,
+					"product_id": pr.ProductID,
+				}).Error("failed to create refill")
+			}
+		}
+	}
+
+	return nil
+}
+
+// create stores refill of the prediction unless it already exists and
+// passes it to OnRefill.
+func (p *Predictor) create(ctx context.Context,
+	pr ent.RefillPrediction) (err error) {
+
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var r ent.Refill
+
+	err = tx.QueryRowxContext(ctx, `
+		insert into refill(user_id, product_id, purchase_id, run_out_at, remaining)
+		values ($1, $2, $3, $4, $5)
+		on conflict do nothing
+		returning id, user_id, product_id, purchase_id, run_out_at, remaining,
+		          reorder_purchase_id, created_at
+	`, pr.UserID, pr.ProductID, pr.PurchaseID, *pr.RunOutAt,
+		pr.Remaining).StructScan(&r)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Already created
+			tx.Rollback()
+			return nil
+		}
+		return fmt.Errorf("insert refill: %w", err)
+	}
+
+	r.ProductName = pr.ProductName
+
+	if p.cfg.OnRefill != nil {
+		err = p.cfg.OnRefill(ctx, tx, r)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+package refill
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"eapteka/ent"
+)
+
+func notifier(id, productID int64, dose float64, schedule ...string) supplyNotifier {
+	return supplyNotifier{Notifier: ent.Notifier{ID: id, ProductID: productID,
+		Schedule: schedule, DoseAmount: dose}}
+}
+
+func TestRunOut(t *testing.T) {
+	// Tuesday 09:00
+	now := time.Date(2021, 6, 1, 9, 0, 0, 0, time.UTC)
+	at := func(days, hour int) time.Time {
+		return time.Date(2021, 6, 1+days, hour, 0, 0, 0, time.UTC)
+	}
+
+	daily := notifier(1, 1, 1, "08:00:UTC", "20:00:UTC")
+
+	course := notifier(1, 1, 1, "08:00:UTC", "20:00:UTC")
+	course.Rule = &ent.ScheduleRule{PillCount: 4}
+	course.Fired = 2
+
+	tests := []struct {
+		name      string
+		ns        []supplyNotifier
+		remaining float64
+		until     time.Time
+		want      time.Time
+		wantOK    bool
+	}{{
+		name:      "single notifier",
+		ns:        []supplyNotifier{daily},
+		remaining: 3,
+		until:     at(30, 0),
+		want:      at(2, 8),
+		wantOK:    true,
+	}, {
+		name: "several notifiers of product",
+		ns: []supplyNotifier{
+			notifier(1, 1, 1, "08:00:UTC"),
+			notifier(2, 1, 2, "14:00:UTC"),
+		},
+		remaining: 4,
+		until:     at(30, 0),
+		want:      at(1, 14),
+		wantOK:    true,
+	}, {
+		name:      "remaining less than dose",
+		ns:        []supplyNotifier{daily},
+		remaining: 0.5,
+		until:     at(30, 0),
+		want:      at(0, 20),
+		wantOK:    true,
+	}, {
+		name:      "remaining equal to dose",
+		ns:        []supplyNotifier{daily},
+		remaining: 1,
+		until:     at(30, 0),
+		want:      at(1, 8),
+		wantOK:    true,
+	}, {
+		name:      "dose at horizon",
+		ns:        []supplyNotifier{daily},
+		remaining: 0,
+		until:     at(0, 20),
+		want:      at(0, 20),
+		wantOK:    true,
+	}, {
+		name:      "dose after horizon",
+		ns:        []supplyNotifier{daily},
+		remaining: 0,
+		until:     at(0, 19),
+	}, {
+		name:      "enough until horizon",
+		ns:        []supplyNotifier{daily},
+		remaining: 100,
+		until:     at(2, 0),
+	}, {
+		name:      "course ends",
+		ns:        []supplyNotifier{course},
+		remaining: 2,
+		until:     at(30, 0),
+	}, {
+		name:      "course ends before supply",
+		ns:        []supplyNotifier{course},
+		remaining: 1,
+		until:     at(30, 0),
+		want:      at(1, 8),
+		wantOK:    true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := runOut(tt.ns, tt.remaining, now, tt.until)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("runOut() = %v, %v, want %v, %v", got, ok, tt.want,
+					tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestByProduct(t *testing.T) {
+	tests := []struct {
+		name     string
+		products []int64
+		want     [][]int64
+	}{
+		{name: "empty"},
+		{name: "single", products: []int64{1}, want: [][]int64{{1}}},
+		{
+			name:     "several",
+			products: []int64{1, 1, 2, 3, 3, 3},
+			want:     [][]int64{{1, 1}, {2}, {3, 3, 3}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ns []supplyNotifier
+			for i, p := range tt.products {
+				ns = append(ns, notifier(int64(i+1), p, 1))
+			}
+
+			var got [][]int64
+			for _, g := range byProduct(ns) {
+				var ps []int64
+				for _, n := range g {
+					ps = append(ps, n.ProductID)
+				}
+				got = append(got, ps)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("byProduct() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConsumed(t *testing.T) {
+	ns := []supplyNotifier{
+		notifier(1, 1, 1),
+		notifier(2, 1, 0.5),
+	}
+
+	tests := []struct {
+		name  string
+		doses []dose
+		want  float64
+	}{
+		{name: "none"},
+		{
+			name:  "every notifier",
+			doses: []dose{{NotifierID: 1}, {NotifierID: 2}, {NotifierID: 1}},
+			want:  2.5,
+		},
+		{
+			name: "skipped",
+			doses: []dose{{NotifierID: 1}, {NotifierID: 1, Skipped: true},
+				{NotifierID: 2, Skipped: true}},
+			want: 1,
+		},
+		{
+			name:  "all skipped",
+			doses: []dose{{NotifierID: 1, Skipped: true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := consumed(ns, tt.doses)
+			if got != tt.want {
+				t.Errorf("consumed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
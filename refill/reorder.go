@@ -0,0 +1,144 @@
+package refill
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"eapteka/ent"
+)
+
+// Refills returns refills of the user, latest first.
+func (p *Predictor) Refills(ctx context.Context, userID string) (
+	[]ent.Refill, error) {
+
+	rs := []ent.Refill{}
+
+	err := p.db.SelectContext(ctx, &rs, `
+		select r.id as id, user_id, product_id, purchase_id, run_out_at,
+		       remaining, reorder_purchase_id, r.created_at as created_at,
+		       p.name as product_name
+		from refill r
+		    join product p on p.id = r.product_id
+		where user_id = $1
+		order by r.created_at desc
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return rs, nil
+}
+
+// Refill returns refill by its ID.
+func (p *Predictor) Refill(ctx context.Context, refillID int64) (ent.Refill,
+	error) {
+
+	var r ent.Refill
+
+	err := p.db.QueryRowxContext(ctx, `
+		select r.id as id, user_id, product_id, purchase_id, run_out_at,
+		       remaining, reorder_purchase_id, r.created_at as created_at,
+		       p.name as product_name
+		from refill r
+		    join product p on p.id = r.product_id
+		where r.id = $1
+	`, refillID).StructScan(&r)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ent.Refill{}, ErrRefillNotFound
+		}
+		return ent.Refill{}, fmt.Errorf("select refill: %w", err)
+	}
+
+	return r, nil
+}
+
+// Reorder creates purchase of the refill product in the same count as in
+// the last purchase at current price. Repeated reorder of the same refill
+// returns the purchase created first.
+func (p *Predictor) Reorder(ctx context.Context, refillID int64) (
+	_ ent.Purchase, err error) {
+
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return ent.Purchase{}, fmt.Errorf("begin tx: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var r ent.Refill
+
+	err = tx.QueryRowxContext(ctx, `
+		select id, user_id, product_id, purchase_id, run_out_at, remaining,
+		       reorder_purchase_id, created_at
+		from refill where id = $1
+		for update
+	`, refillID).StructScan(&r)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = ErrRefillNotFound
+			return ent.Purchase{}, err
+		}
+		return ent.Purchase{}, fmt.Errorf("select refill: %w", err)
+	}
+
+	var pu ent.Purchase
+
+	if r.ReorderPurchaseID != nil {
+		err = tx.QueryRowxContext(ctx, `
+			select * from purchase where id = $1
+		`, *r.ReorderPurchaseID).StructScan(&pu)
+		if err != nil {
+			return ent.Purchase{}, fmt.Errorf("select purchase: %w", err)
+		}
+	} else {
+		err = tx.QueryRowxContext(ctx, `
+			insert into purchase(user_id) values ($1) returning *
+		`, r.UserID).StructScan(&pu)
+		if err != nil {
+			return ent.Purchase{}, fmt.Errorf("insert purchase: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			insert into purchase_product(purchase_id, product_id, count, price)
+			select $1, pp.product_id, pp.count, p.price
+			from purchase_product pp
+			    join product p on p.id = pp.product_id
+			where pp.purchase_id = $2 and pp.product_id = $3
+		`, pu.ID, r.PurchaseID, r.ProductID)
+		if err != nil {
+			return ent.Purchase{}, fmt.Errorf("insert purchase products: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			update refill set reorder_purchase_id = $2 where id = $1
+		`, r.ID, pu.ID)
+		if err != nil {
+			return ent.Purchase{}, fmt.Errorf("update refill: %w", err)
+		}
+	}
+
+	err = tx.SelectContext(ctx, &pu.Products, `
+		select p.*, pp.count as count, pp.price as purchase_price
+		from purchase_product pp
+		    join product p on p.id = pp.product_id
+		where pp.purchase_id = $1
+		order by p.id
+	`, pu.ID)
+	if err != nil {
+		return ent.Purchase{}, fmt.Errorf("select purchase products: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return ent.Purchase{}, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return pu, nil
+}
@@ -0,0 +1,91 @@
+// Package reqctx carries context of the request through fiber middlewares
+// and handlers. Handlers pass it to every DB call, so queries are
+// cancelled when the request times out or the server aborts requests on
+// shutdown.
+//
+// fasthttp doesn't report client disconnects to handlers, the request
+// timeout bounds the work done for a gone client.
+package reqctx
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	key    = "reqctx"
+	errKey = "reqctx.err"
+)
+
+type Config struct {
+	// Timeout is the deadline of every request.
+	Timeout time.Duration
+
+	// Abort is closed when requests still running must be cancelled, e.g.
+	// once shutdown grace period is over.
+	Abort <-chan struct{}
+}
+
+var ConfigDefault = Config{
+	Timeout: 10 * time.Second,
+}
+
+// Get returns context of the request, background context if it isn't set.
+func Get(c *fiber.Ctx) context.Context {
+	if ctx, ok := c.Locals(key).(context.Context); ok {
+		return ctx
+	}
+	return context.Background()
+}
+
+// Set replaces context of the request, e.g. with context of its span.
+func Set(c *fiber.Ctx, ctx context.Context) {
+	c.Locals(key, ctx)
+}
+
+// Err returns why context of the request was done when its handlers
+// returned: context.DeadlineExceeded if the request timed out,
+// context.Canceled if it was aborted, nil otherwise. Unlike Get(c).Err(),
+// it is valid after Middleware has released the context, e.g. in the
+// error handler.
+func Err(c *fiber.Ctx) error {
+	err, _ := c.Locals(errKey).(error)
+	return err
+}
+
+// Middleware sets context of the request with timeout, derived from the
+// context set by preceding middlewares.
+func Middleware(cfg Config) fiber.Handler {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = ConfigDefault.Timeout
+	}
+
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(Get(c), cfg.Timeout)
+		defer cancel()
+
+		if cfg.Abort != nil {
+			go func() {
+				select {
+				case <-cfg.Abort:
+					cancel()
+				case <-ctx.Done():
+				}
+			}()
+		}
+
+		Set(c, ctx)
+
+		err := c.Next()
+
+		// Context is cancelled on return, so the reason is saved for the
+		// error handler
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			c.Locals(errKey, ctxErr)
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,217 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"eapteka/ent"
+)
+
+// missedAfter is the time after which the reminder without taken or
+// skipped event is considered missed.
+const missedAfter = time.Hour
+
+const maxSnooze = 24 * time.Hour
+
+var (
+	ErrReminderNotFound = errors.New("reminder not found")
+	ErrInvalidEvent     = errors.New("invalid reminder event")
+)
+
+// AddEvent records taken, snoozed or skipped event of the reminder. Snoozed
+// reminder is fired again at SnoozeUntil by any replica's scheduler.
+func (s *Scheduler) AddEvent(ctx context.Context, e ent.ReminderEvent) (
+	ent.ReminderEvent, error) {
+
+	now := time.Now()
+
+	switch e.Type {
+	case ent.ReminderTaken, ent.ReminderSkipped:
+		e.SnoozeUntil = nil
+	case ent.ReminderSnoozed:
+		if e.SnoozeMinutes > 0 {
+			t := now.Add(time.Duration(e.SnoozeMinutes) * time.Minute)
+			e.SnoozeUntil = &t
+		}
+		if e.SnoozeUntil == nil || !e.SnoozeUntil.After(now) ||
+			e.SnoozeUntil.Sub(now) > maxSnooze {
+			return ent.ReminderEvent{}, fmt.Errorf(
+				"%w: snooze time must be in the next %s", ErrInvalidEvent,
+				maxSnooze)
+		}
+	default:
+		return ent.ReminderEvent{}, fmt.Errorf("%w: unknown type %q",
+			ErrInvalidEvent, e.Type)
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return ent.ReminderEvent{}, fmt.Errorf("begin tx: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var done bool
+
+	err = tx.QueryRowxContext(ctx, `
+		select exists(
+			select from reminder_event
+			where reminder_id = r.id and type in ('taken', 'skipped')
+		)
+		from reminder r where id = $1
+		for update
+	`, e.ReminderID).Scan(&done)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = ErrReminderNotFound
+			return ent.ReminderEvent{}, err
+		}
+		return ent.ReminderEvent{}, fmt.Errorf("select reminder: %w", err)
+	}
+
+	if done {
+		err = fmt.Errorf("%w: reminder is already taken or skipped",
+			ErrInvalidEvent)
+		return ent.ReminderEvent{}, err
+	}
+
+	err = tx.QueryRowxContext(ctx, `
+		insert into reminder_event(reminder_id, type, snooze_until)
+		values ($1, $2, $3)
+		returning id, reminder_id, type, snooze_until, created_at
+	`, e.ReminderID, e.Type, e.SnoozeUntil).StructScan(&e)
+	if err != nil {
+		return ent.ReminderEvent{}, fmt.Errorf("insert event: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		update reminder set snoozed_until = $2, snooze_fired = false
+		where id = $1
+	`, e.ReminderID, e.SnoozeUntil)
+	if err != nil {
+		return ent.ReminderEvent{}, fmt.Errorf("update reminder: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return ent.ReminderEvent{}, fmt.Errorf("commit tx: %w", err)
+	}
+
+	e.SnoozeMinutes = 0
+
+	return e, nil
+}
+
+// Events returns events of the reminder in chronological order.
+func (s *Scheduler) Events(ctx context.Context, reminderID int64) (
+	[]ent.ReminderEvent, error) {
+
+	es := []ent.ReminderEvent{}
+
+	err := s.db.SelectContext(ctx, &es, `
+		select id, reminder_id, type, snooze_until, created_at
+		from reminder_event where reminder_id = $1
+		order by created_at
+	`, reminderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return es, nil
+}
+
+// Adherence returns counts of the notifier reminders by outcome grouped by
+// day or week in the given location. Reminder without taken or skipped
+// event is missed an hour after its scheduled time or snooze end.
+func (s *Scheduler) Adherence(ctx context.Context, notifierID int64,
+	period string, loc *time.Location, from, to time.Time) ([]ent.Adherence,
+	error) {
+
+	if period != "day" && period != "week" {
+		return nil, fmt.Errorf("unsupported period %q", period)
+	}
+
+	var outcomes []outcome
+
+	err := s.db.SelectContext(ctx, &outcomes, `
+		select r.scheduled_at, r.snoozed_until, e.type
+		from reminder r
+		    left join lateral (
+		        select type from reminder_event
+		        where reminder_id = r.id and type in ('taken', 'skipped')
+		        order by created_at desc
+		        limit 1
+		    ) e on true
+		where r.notifier_id = $1 and r.scheduled_at >= $2 and r.scheduled_at < $3
+	`, notifierID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return adherence(outcomes, period, loc, time.Now()), nil
+}
+
+// outcome is the reminder with its last taken or skipped event type, empty
+// if there is none.
+type outcome struct {
+	ScheduledAt  time.Time      `db:"scheduled_at"`
+	SnoozedUntil *time.Time     `db:"snoozed_until"`
+	Type         sql.NullString `db:"type"`
+}
+
+// adherence counts outcomes by day or week, starting on Monday, in the
+// given location in chronological order.
+func adherence(outcomes []outcome, period string, loc *time.Location,
+	now time.Time) []ent.Adherence {
+
+	byPeriod := map[time.Time]*ent.Adherence{}
+
+	for _, o := range outcomes {
+		t := o.ScheduledAt.In(loc)
+		p := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		if period == "week" {
+			p = p.AddDate(0, 0, -(int(p.Weekday())+6)%7)
+		}
+
+		a, ok := byPeriod[p]
+		if !ok {
+			a = &ent.Adherence{Period: p}
+			byPeriod[p] = a
+		}
+
+		a.Total++
+
+		due := o.ScheduledAt
+		if o.SnoozedUntil != nil {
+			due = *o.SnoozedUntil
+		}
+
+		switch {
+		case o.Type.String == ent.ReminderTaken:
+			a.Taken++
+		case o.Type.String == ent.ReminderSkipped:
+			a.Skipped++
+		case due.Before(now.Add(-missedAfter)):
+			a.Missed++
+		}
+	}
+
+	as := make([]ent.Adherence, 0, len(byPeriod))
+	for _, a := range byPeriod {
+		as = append(as, *a)
+	}
+
+	sort.Slice(as, func(i, j int) bool {
+		return as[i].Period.Before(as[j].Period)
+	})
+
+	return as
+}
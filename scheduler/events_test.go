@@ -0,0 +1,96 @@
+package scheduler
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"eapteka/ent"
+)
+
+func TestAdherence(t *testing.T) {
+	msk := mustLoad(t, "Europe/Moscow")
+
+	// Wednesday noon
+	now := time.Date(2021, 6, 2, 12, 0, 0, 0, time.UTC)
+	ptr := func(t time.Time) *time.Time { return &t }
+	typ := func(s string) sql.NullString { return sql.NullString{String: s, Valid: true} }
+
+	// Monday 31 May to Wednesday 2 June, times in UTC
+	at := func(day, hour int) time.Time {
+		return time.Date(2021, 5, 31+day, hour, 0, 0, 0, time.UTC)
+	}
+
+	outcomes := []outcome{
+		{ScheduledAt: at(0, 8), Type: typ(ent.ReminderTaken)},
+		{ScheduledAt: at(0, 20), Type: typ(ent.ReminderSkipped)},
+		{ScheduledAt: at(1, 8)},
+		// 23:00 UTC is next day in Moscow
+		{ScheduledAt: at(1, 23), Type: typ(ent.ReminderTaken)},
+		// Snoozed till the missed window isn't over yet
+		{ScheduledAt: at(2, 8), SnoozedUntil: ptr(now.Add(-30 * time.Minute))},
+		// Scheduled less than an hour ago
+		{ScheduledAt: now.Add(-30 * time.Minute)},
+		// Previous week in every location
+		{ScheduledAt: time.Date(2021, 5, 29, 8, 0, 0, 0, time.UTC)},
+	}
+
+	tests := []struct {
+		name   string
+		period string
+		loc    *time.Location
+		want   []ent.Adherence
+	}{{
+		name:   "day",
+		period: "day",
+		loc:    time.UTC,
+		want: []ent.Adherence{
+			{Period: time.Date(2021, 5, 29, 0, 0, 0, 0, time.UTC), Total: 1, Missed: 1},
+			{Period: at(0, 0), Total: 2, Taken: 1, Skipped: 1},
+			{Period: at(1, 0), Total: 2, Taken: 1, Missed: 1},
+			{Period: at(2, 0), Total: 2},
+		},
+	}, {
+		name:   "day in location",
+		period: "day",
+		loc:    msk,
+		want: []ent.Adherence{
+			{Period: time.Date(2021, 5, 29, 0, 0, 0, 0, msk), Total: 1, Missed: 1},
+			{Period: time.Date(2021, 5, 31, 0, 0, 0, 0, msk), Total: 2, Taken: 1, Skipped: 1},
+			{Period: time.Date(2021, 6, 1, 0, 0, 0, 0, msk), Total: 1, Missed: 1},
+			{Period: time.Date(2021, 6, 2, 0, 0, 0, 0, msk), Total: 3, Taken: 1},
+		},
+	}, {
+		name:   "week",
+		period: "week",
+		loc:    msk,
+		want: []ent.Adherence{
+			{Period: time.Date(2021, 5, 24, 0, 0, 0, 0, msk), Total: 1, Missed: 1},
+			{Period: time.Date(2021, 5, 31, 0, 0, 0, 0, msk), Total: 6, Taken: 2,
+				Skipped: 1, Missed: 1},
+		},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := adherence(outcomes, tt.period, tt.loc, now)
+			if len(got) != len(tt.want) {
+				t.Fatalf("adherence() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				g, w := got[i], tt.want[i]
+				if !g.Period.Equal(w.Period) || g.Period.Location() != tt.loc {
+					t.Errorf("[%d] period = %s, want %s", i, g.Period, w.Period)
+				}
+				g.Period, w.Period = time.Time{}, time.Time{}
+				if g != w {
+					t.Errorf("[%d] = %+v, want %+v", i, g, w)
+				}
+			}
+		})
+	}
+
+	if got := adherence(nil, "day", time.UTC, now); got == nil || len(got) != 0 {
+		t.Errorf("adherence() of no reminders = %#v, want empty", got)
+	}
+}
@@ -0,0 +1,215 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"eapteka/ent"
+)
+
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// ParseRRule imports notifier schedule and rule from iCalendar (RFC 5545)
+// recurrence. The value is either a bare RRULE or DTSTART and RRULE lines,
+// e.g.:
+//
+//	DTSTART;TZID=Europe/Moscow:20210601T080000
+//	RRULE:FREQ=WEEKLY;BYDAY=MO,WE,FR;BYHOUR=8,20;COUNT=30
+//
+// Supported are DAILY and WEEKLY frequencies with interval 1, which map to
+// daily schedule times, and HOURLY frequency, which maps to every_hours.
+// BYDAY, BYHOUR, BYMINUTE, COUNT and UNTIL parts are supported.
+func ParseRRule(s string) ([]string, *ent.ScheduleRule, error) {
+	var (
+		dtStart *time.Time
+		rrule   string
+	)
+
+	loc := time.UTC
+
+	for _, line := range strings.Fields(s) {
+		switch {
+		case strings.HasPrefix(line, "DTSTART"):
+			t, l, err := parseDTStart(line)
+			if err != nil {
+				return nil, nil, fmt.Errorf("parse DTSTART: %w", err)
+			}
+			dtStart, loc = &t, l
+		case strings.HasPrefix(line, "RRULE:"):
+			rrule = strings.TrimPrefix(line, "RRULE:")
+		case strings.HasPrefix(line, "FREQ="):
+			rrule = line
+		default:
+			return nil, nil, fmt.Errorf("unsupported line %q", line)
+		}
+	}
+
+	if rrule == "" {
+		return nil, nil, errors.New("RRULE is required")
+	}
+
+	var (
+		r        = &ent.ScheduleRule{StartAt: dtStart}
+		freq     string
+		interval = 1
+		hours    []int
+		minutes  []int
+	)
+
+	for _, part := range strings.Split(rrule, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, nil, fmt.Errorf("invalid RRULE part %q", part)
+		}
+
+		k, v := kv[0], kv[1]
+
+		var err error
+
+		switch k {
+		case "FREQ":
+			freq = v
+		case "INTERVAL":
+			interval, err = strconv.Atoi(v)
+			if err == nil && interval < 1 {
+				err = errors.New("must be positive")
+			}
+		case "BYDAY":
+			for _, d := range strings.Split(v, ",") {
+				wd, ok := rruleWeekdays[d]
+				if !ok {
+					return nil, nil, fmt.Errorf("unsupported BYDAY %q", d)
+				}
+				r.Weekdays = append(r.Weekdays, wd)
+			}
+		case "BYHOUR":
+			hours, err = parseInts(v, 0, 23)
+		case "BYMINUTE":
+			minutes, err = parseInts(v, 0, 59)
+		case "COUNT":
+			r.PillCount, err = strconv.Atoi(v)
+		case "UNTIL":
+			var t time.Time
+			t, err = parseICalTime(v, loc)
+			r.EndAt = &t
+		case "WKST":
+		default:
+			return nil, nil, fmt.Errorf("unsupported RRULE part %q", k)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("parse %s: %w", k, err)
+		}
+	}
+
+	switch freq {
+	case "HOURLY":
+		if dtStart == nil {
+			return nil, nil, errors.New("DTSTART is required for HOURLY")
+		}
+		if len(hours) != 0 || len(minutes) != 0 {
+			return nil, nil, errors.New("BYHOUR and BYMINUTE are unsupported for HOURLY")
+		}
+		r.EveryHours = interval
+		return nil, r, nil
+
+	case "DAILY", "WEEKLY":
+		if interval != 1 {
+			return nil, nil, fmt.Errorf("INTERVAL is unsupported for %s", freq)
+		}
+		if freq == "WEEKLY" && len(r.Weekdays) == 0 {
+			if dtStart == nil {
+				return nil, nil, errors.New("DTSTART or BYDAY is required for WEEKLY")
+			}
+			r.Weekdays = []time.Weekday{dtStart.Weekday()}
+		}
+		if len(hours) == 0 || len(minutes) == 0 {
+			if dtStart == nil {
+				return nil, nil, errors.New("DTSTART or BYHOUR and BYMINUTE are required")
+			}
+			if len(hours) == 0 {
+				hours = []int{dtStart.Hour()}
+			}
+			if len(minutes) == 0 {
+				minutes = []int{dtStart.Minute()}
+			}
+		}
+
+		var schedule []string
+		for _, h := range hours {
+			for _, m := range minutes {
+				schedule = append(schedule,
+					FormatTime(time.Date(0, 0, 0, h, m, 0, 0, loc)))
+			}
+		}
+		return schedule, r, nil
+	}
+
+	return nil, nil, fmt.Errorf("unsupported FREQ %q", freq)
+}
+
+// parseDTStart parses "DTSTART;TZID=Zone:20210601T080000" or
+// "DTSTART:20210601T080000Z" line.
+func parseDTStart(line string) (time.Time, *time.Location, error) {
+	i := strings.LastIndex(line, ":")
+	if i < 0 {
+		return time.Time{}, nil, errors.New("invalid format")
+	}
+
+	params, value := line[:i], line[i+1:]
+
+	loc := time.UTC
+
+	for _, p := range strings.Split(params, ";")[1:] {
+		if strings.HasPrefix(p, "TZID=") {
+			l, err := time.LoadLocation(strings.TrimPrefix(p, "TZID="))
+			if err != nil {
+				return time.Time{}, nil, err
+			}
+			loc = l
+		}
+	}
+
+	t, err := parseICalTime(value, loc)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+
+	return t, loc, nil
+}
+
+func parseICalTime(v string, loc *time.Location) (time.Time, error) {
+	switch {
+	case strings.HasSuffix(v, "Z"):
+		return time.Parse("20060102T150405Z", v)
+	case strings.Contains(v, "T"):
+		return time.ParseInLocation("20060102T150405", v, loc)
+	default:
+		return time.ParseInLocation("20060102", v, loc)
+	}
+}
+
+func parseInts(s string, min, max int) ([]int, error) {
+	var is []int
+	for _, p := range strings.Split(s, ",") {
+		i, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, err
+		}
+		if i < min || i > max {
+			return nil, fmt.Errorf("%d out of range", i)
+		}
+		is = append(is, i)
+	}
+	return is, nil
+}
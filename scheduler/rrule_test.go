@@ -0,0 +1,190 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"eapteka/ent"
+)
+
+// ruleString formats the rule for comparison, time pointers by value.
+func ruleString(r *ent.ScheduleRule) string {
+	if r == nil {
+		return "<nil>"
+	}
+
+	ts := func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+
+	return fmt.Sprintf("weekdays=%v every=%d start=%s end=%s days=%d pills=%d",
+		r.Weekdays, r.EveryHours, ts(r.StartAt), ts(r.EndAt), r.CourseDays,
+		r.PillCount)
+}
+
+func TestParseRRule(t *testing.T) {
+	msk := mustLoad(t, "Europe/Moscow")
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		name     string
+		s        string
+		schedule []string
+		rule     *ent.ScheduleRule
+		wantErr  string
+	}{{
+		name: "weekly with start",
+		s: "DTSTART;TZID=Europe/Moscow:20210601T080000\n" +
+			"RRULE:FREQ=WEEKLY;BYDAY=MO,WE,FR;BYHOUR=8,20;COUNT=30",
+		schedule: []string{"08:00:Europe/Moscow", "20:00:Europe/Moscow"},
+		rule: &ent.ScheduleRule{
+			Weekdays:  []time.Weekday{time.Monday, time.Wednesday, time.Friday},
+			StartAt:   ptr(time.Date(2021, 6, 1, 8, 0, 0, 0, msk)),
+			PillCount: 30,
+		},
+	}, {
+		name:     "bare daily",
+		s:        "FREQ=DAILY;BYHOUR=9;BYMINUTE=0,30",
+		schedule: []string{"09:00:UTC", "09:30:UTC"},
+		rule:     &ent.ScheduleRule{},
+	}, {
+		name:     "daily from start",
+		s:        "DTSTART:20210601T073000Z RRULE:FREQ=DAILY;UNTIL=20210610T000000Z",
+		schedule: []string{"07:30:UTC"},
+		rule: &ent.ScheduleRule{
+			StartAt: ptr(time.Date(2021, 6, 1, 7, 30, 0, 0, time.UTC)),
+			EndAt:   ptr(time.Date(2021, 6, 10, 0, 0, 0, 0, time.UTC)),
+		},
+	}, {
+		name: "until date in zone",
+		s: "DTSTART;TZID=Europe/Moscow:20210601T080000\n" +
+			"RRULE:FREQ=DAILY;UNTIL=20210610",
+		schedule: []string{"08:00:Europe/Moscow"},
+		rule: &ent.ScheduleRule{
+			StartAt: ptr(time.Date(2021, 6, 1, 8, 0, 0, 0, msk)),
+			EndAt:   ptr(time.Date(2021, 6, 10, 0, 0, 0, 0, msk)),
+		},
+	}, {
+		name:     "weekly on start weekday",
+		s:        "DTSTART:20210601T080000Z\nRRULE:FREQ=WEEKLY;WKST=MO",
+		schedule: []string{"08:00:UTC"},
+		rule: &ent.ScheduleRule{
+			Weekdays: []time.Weekday{time.Tuesday},
+			StartAt:  ptr(time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC)),
+		},
+	}, {
+		name: "hourly",
+		s:    "DTSTART:20210601T060000Z\nRRULE:FREQ=HOURLY;INTERVAL=8;COUNT=21",
+		rule: &ent.ScheduleRule{
+			EveryHours: 8,
+			StartAt:    ptr(time.Date(2021, 6, 1, 6, 0, 0, 0, time.UTC)),
+			PillCount:  21,
+		},
+	}, {
+		name:    "no rrule",
+		s:       "DTSTART:20210601T060000Z",
+		wantErr: "RRULE is required",
+	}, {
+		name:    "unsupported line",
+		s:       "EXDATE:20210601T060000Z RRULE:FREQ=DAILY;BYHOUR=8;BYMINUTE=0",
+		wantErr: "unsupported line",
+	}, {
+		name:    "invalid start",
+		s:       "DTSTART:yesterday RRULE:FREQ=DAILY",
+		wantErr: "parse DTSTART",
+	}, {
+		name:    "unknown zone",
+		s:       "DTSTART;TZID=Mars/Olympus:20210601T080000 RRULE:FREQ=DAILY",
+		wantErr: "parse DTSTART",
+	}, {
+		name:    "unsupported freq",
+		s:       "FREQ=MONTHLY;BYHOUR=8;BYMINUTE=0",
+		wantErr: "unsupported FREQ",
+	}, {
+		name:    "unsupported part",
+		s:       "FREQ=DAILY;BYMONTH=1",
+		wantErr: "unsupported RRULE part",
+	}, {
+		name:    "invalid part",
+		s:       "FREQ=DAILY;BYHOUR",
+		wantErr: "invalid RRULE part",
+	}, {
+		name:    "unsupported day",
+		s:       "FREQ=WEEKLY;BYDAY=1MO;BYHOUR=8;BYMINUTE=0",
+		wantErr: "unsupported BYDAY",
+	}, {
+		name:    "hour out of range",
+		s:       "FREQ=DAILY;BYHOUR=24;BYMINUTE=0",
+		wantErr: "parse BYHOUR",
+	}, {
+		name:    "minute out of range",
+		s:       "FREQ=DAILY;BYHOUR=8;BYMINUTE=60",
+		wantErr: "parse BYMINUTE",
+	}, {
+		name:    "zero interval",
+		s:       "DTSTART:20210601T060000Z RRULE:FREQ=HOURLY;INTERVAL=0",
+		wantErr: "parse INTERVAL",
+	}, {
+		name:    "daily interval",
+		s:       "FREQ=DAILY;INTERVAL=2;BYHOUR=8;BYMINUTE=0",
+		wantErr: "INTERVAL is unsupported for DAILY",
+	}, {
+		name:    "daily without time",
+		s:       "FREQ=DAILY;BYHOUR=8",
+		wantErr: "DTSTART or BYHOUR and BYMINUTE are required",
+	}, {
+		name:    "weekly without day",
+		s:       "FREQ=WEEKLY;BYHOUR=8;BYMINUTE=0",
+		wantErr: "DTSTART or BYDAY is required",
+	}, {
+		name:    "hourly without start",
+		s:       "FREQ=HOURLY;INTERVAL=8",
+		wantErr: "DTSTART is required for HOURLY",
+	}, {
+		name:    "hourly by hour",
+		s:       "DTSTART:20210601T060000Z RRULE:FREQ=HOURLY;BYHOUR=8",
+		wantErr: "unsupported for HOURLY",
+	}, {
+		name:    "invalid count",
+		s:       "FREQ=DAILY;BYHOUR=8;BYMINUTE=0;COUNT=many",
+		wantErr: "parse COUNT",
+	}, {
+		name:    "invalid until",
+		s:       "FREQ=DAILY;BYHOUR=8;BYMINUTE=0;UNTIL=soon",
+		wantErr: "parse UNTIL",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, rule, err := ParseRRule(tt.s)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseRRule() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if strings.Join(schedule, ",") != strings.Join(tt.schedule, ",") {
+				t.Errorf("schedule = %v, want %v", schedule, tt.schedule)
+			}
+			if ruleString(rule) != ruleString(tt.rule) {
+				t.Errorf("rule = %s, want %s", ruleString(rule),
+					ruleString(tt.rule))
+			}
+
+			// Imported notifier must pass validation
+			_, err = Validate(schedule, rule)
+			if err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+		})
+	}
+}
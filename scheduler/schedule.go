@@ -0,0 +1,207 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"eapteka/ent"
+)
+
+// maxNextIterations bounds occurrences skipped by weekday filter while
+// looking for the next one.
+const maxNextIterations = 1000
+
+// ParseTime parses daily schedule time in "HH:MM:Zone" format, e.g.
+// "08:30:Europe/Moscow".
+func ParseTime(s string) (time.Time, error) {
+	ps := strings.Split(s, ":")
+	if len(ps) != 3 {
+		return time.Time{}, fmt.Errorf("invalid time format")
+	}
+
+	h, err := strconv.Atoi(ps[0])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse hour: %w", err)
+	}
+	if h < 0 || h > 23 {
+		return time.Time{}, fmt.Errorf("hour out of range")
+	}
+
+	m, err := strconv.Atoi(ps[1])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse minute: %w", err)
+	}
+	if m < 0 || m > 59 {
+		return time.Time{}, fmt.Errorf("minute out of range")
+	}
+
+	tz, err := time.LoadLocation(ps[2])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse time zone: %w", err)
+	}
+
+	return time.Date(0, 0, 0, h, m, 0, 0, tz), nil
+}
+
+// FormatTime formats daily schedule time in "HH:MM:Zone" format.
+func FormatTime(t time.Time) string {
+	return t.Format("15:04:") + t.Location().String()
+}
+
+// Validate checks notifier schedule and rule, and returns the schedule with
+// normalized times.
+func Validate(schedule []string, rule *ent.ScheduleRule) ([]string, error) {
+	var r ent.ScheduleRule
+	if rule != nil {
+		r = *rule
+	}
+
+	if len(schedule) == 0 && r.EveryHours == 0 {
+		return nil, errors.New("either schedule or every_hours is required")
+	}
+	if len(schedule) != 0 && r.EveryHours != 0 {
+		return nil, errors.New("schedule and every_hours are mutually exclusive")
+	}
+
+	normalized := make([]string, 0, len(schedule))
+
+	for _, s := range schedule {
+		t, err := ParseTime(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule time %q: %w", s, err)
+		}
+		normalized = append(normalized, FormatTime(t))
+	}
+
+	for _, wd := range r.Weekdays {
+		if wd < time.Sunday || wd > time.Saturday {
+			return nil, fmt.Errorf("invalid weekday %d", wd)
+		}
+	}
+
+	switch {
+	case r.EveryHours < 0:
+		return nil, errors.New("every_hours must be positive")
+	case r.EveryHours > 0 && r.StartAt == nil:
+		return nil, errors.New("start_at is required with every_hours")
+	case r.CourseDays < 0:
+		return nil, errors.New("course_days must be positive")
+	case r.CourseDays > 0 && r.StartAt == nil:
+		return nil, errors.New("start_at is required with course_days")
+	case r.PillCount < 0:
+		return nil, errors.New("pill_count must be positive")
+	case r.StartAt != nil && r.EndAt != nil && !r.EndAt.After(*r.StartAt):
+		return nil, errors.New("end_at must be after start_at")
+	}
+
+	return normalized, nil
+}
+
+// Next returns the earliest occurrence of the schedule limited by the rule
+// strictly after the given time. Fired is the number of already fired
+// reminders, used to limit course by pill count. It returns false if the
+// schedule has no more occurrences.
+func Next(schedule []string, rule *ent.ScheduleRule, fired int,
+	after time.Time) (time.Time, bool) {
+
+	var r ent.ScheduleRule
+	if rule != nil {
+		r = *rule
+	}
+
+	if r.PillCount > 0 && fired >= r.PillCount {
+		return time.Time{}, false
+	}
+
+	end := r.EndAt
+	if r.CourseDays > 0 && r.StartAt != nil {
+		courseEnd := r.StartAt.AddDate(0, 0, r.CourseDays)
+		if end == nil || courseEnd.Before(*end) {
+			end = &courseEnd
+		}
+	}
+
+	if r.StartAt != nil && after.Before(*r.StartAt) {
+		after = r.StartAt.Add(-time.Nanosecond)
+	}
+
+	weekdays := map[time.Weekday]bool{}
+	for _, wd := range r.Weekdays {
+		weekdays[wd] = true
+	}
+
+	for i := 0; i < maxNextIterations; i++ {
+		var (
+			next time.Time
+			ok   bool
+		)
+
+		if r.EveryHours > 0 {
+			next, ok = nextInterval(*r.StartAt,
+				time.Duration(r.EveryHours)*time.Hour, after)
+		} else {
+			next, ok = nextDaily(schedule, after)
+		}
+
+		if !ok || (end != nil && next.After(*end)) {
+			return time.Time{}, false
+		}
+
+		if len(weekdays) == 0 || weekdays[next.Weekday()] {
+			return next, true
+		}
+
+		after = next
+	}
+
+	return time.Time{}, false
+}
+
+// nextInterval returns the earliest of start + k*interval strictly after
+// the given time.
+func nextInterval(start time.Time, interval time.Duration,
+	after time.Time) (time.Time, bool) {
+
+	if after.Before(start) {
+		return start, true
+	}
+
+	k := after.Sub(start)/interval + 1
+
+	return start.Add(k * interval), true
+}
+
+// nextDaily returns the earliest occurrence of the daily schedule times
+// strictly after the given time.
+func nextDaily(schedule []string, after time.Time) (time.Time, bool) {
+	var (
+		next  time.Time
+		found bool
+	)
+
+	for _, s := range schedule {
+		t, err := ParseTime(s)
+		if err != nil {
+			continue
+		}
+
+		a := after.In(t.Location())
+
+		c := time.Date(a.Year(), a.Month(), a.Day(), t.Hour(), t.Minute(),
+			0, 0, t.Location())
+		if !c.After(after) {
+			c = time.Date(a.Year(), a.Month(), a.Day()+1, t.Hour(), t.Minute(),
+				0, 0, t.Location())
+		}
+
+		if !found || c.Before(next) {
+			next = c
+			found = true
+		}
+	}
+
+	return next, found
+}
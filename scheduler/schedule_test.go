@@ -0,0 +1,324 @@
+package scheduler
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"eapteka/ent"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    string
+		wantErr bool
+	}{
+		{s: "08:30:Europe/Moscow", want: "08:30:Europe/Moscow"},
+		{s: "8:05:UTC", want: "08:05:UTC"},
+		{s: "23:59:Asia/Vladivostok", want: "23:59:Asia/Vladivostok"},
+		{s: "24:00:UTC", wantErr: true},
+		{s: "12:60:UTC", wantErr: true},
+		{s: "-1:00:UTC", wantErr: true},
+		{s: "08:30", wantErr: true},
+		{s: "aa:30:UTC", wantErr: true},
+		{s: "08:bb:UTC", wantErr: true},
+		{s: "08:30:Mars/Olympus", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTime(tt.s)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTime(%q) error = %v, want error %v", tt.s, err,
+				tt.wantErr)
+			continue
+		}
+		if err == nil && FormatTime(got) != tt.want {
+			t.Errorf("ParseTime(%q) = %s, want %s", tt.s, FormatTime(got),
+				tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	start := time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC)
+	before := start.Add(-time.Hour)
+
+	tests := []struct {
+		name     string
+		schedule []string
+		rule     *ent.ScheduleRule
+		want     []string
+		wantErr  string
+	}{{
+		name:     "daily",
+		schedule: []string{"8:00:UTC", "20:00:Europe/Moscow"},
+		want:     []string{"08:00:UTC", "20:00:Europe/Moscow"},
+	}, {
+		name: "every hours",
+		rule: &ent.ScheduleRule{EveryHours: 8, StartAt: &start},
+		want: []string{},
+	}, {
+		name:     "course",
+		schedule: []string{"08:00:UTC"},
+		rule: &ent.ScheduleRule{Weekdays: []time.Weekday{time.Monday},
+			StartAt: &start, CourseDays: 10, PillCount: 5},
+		want: []string{"08:00:UTC"},
+	}, {
+		name:    "empty",
+		wantErr: "either schedule or every_hours is required",
+	}, {
+		name:     "both",
+		schedule: []string{"08:00:UTC"},
+		rule:     &ent.ScheduleRule{EveryHours: 8, StartAt: &start},
+		wantErr:  "mutually exclusive",
+	}, {
+		name:     "invalid time",
+		schedule: []string{"08:00"},
+		wantErr:  `invalid schedule time "08:00"`,
+	}, {
+		name:     "invalid weekday",
+		schedule: []string{"08:00:UTC"},
+		rule:     &ent.ScheduleRule{Weekdays: []time.Weekday{7}},
+		wantErr:  "invalid weekday 7",
+	}, {
+		name:    "negative every hours",
+		rule:    &ent.ScheduleRule{EveryHours: -1},
+		wantErr: "every_hours must be positive",
+	}, {
+		name:    "every hours without start",
+		rule:    &ent.ScheduleRule{EveryHours: 8},
+		wantErr: "start_at is required with every_hours",
+	}, {
+		name:     "course without start",
+		schedule: []string{"08:00:UTC"},
+		rule:     &ent.ScheduleRule{CourseDays: 10},
+		wantErr:  "start_at is required with course_days",
+	}, {
+		name:     "negative course",
+		schedule: []string{"08:00:UTC"},
+		rule:     &ent.ScheduleRule{CourseDays: -1},
+		wantErr:  "course_days must be positive",
+	}, {
+		name:     "negative pill count",
+		schedule: []string{"08:00:UTC"},
+		rule:     &ent.ScheduleRule{PillCount: -1},
+		wantErr:  "pill_count must be positive",
+	}, {
+		name:     "end before start",
+		schedule: []string{"08:00:UTC"},
+		rule:     &ent.ScheduleRule{StartAt: &start, EndAt: &before},
+		wantErr:  "end_at must be after start_at",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Validate(tt.schedule, tt.rule)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Validate() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got == nil || strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Validate() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	msk := mustLoad(t, "Europe/Moscow")
+
+	// Tuesday
+	day := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	at := func(days, hour, min int) time.Time {
+		return day.AddDate(0, 0, days).Add(time.Duration(hour)*time.Hour +
+			time.Duration(min)*time.Minute)
+	}
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	daily := []string{"08:00:UTC", "20:00:UTC"}
+
+	tests := []struct {
+		name     string
+		schedule []string
+		rule     *ent.ScheduleRule
+		fired    int
+		after    time.Time
+		want     time.Time
+		wantOK   bool
+	}{{
+		name:     "later today",
+		schedule: daily,
+		after:    at(0, 9, 0),
+		want:     at(0, 20, 0),
+		wantOK:   true,
+	}, {
+		name:     "tomorrow",
+		schedule: daily,
+		after:    at(0, 21, 0),
+		want:     at(1, 8, 0),
+		wantOK:   true,
+	}, {
+		name:     "strictly after",
+		schedule: daily,
+		after:    at(0, 8, 0),
+		want:     at(0, 20, 0),
+		wantOK:   true,
+	}, {
+		name:     "time zone",
+		schedule: []string{"08:00:Europe/Moscow"},
+		after:    at(0, 6, 0),
+		want:     time.Date(2021, 6, 2, 8, 0, 0, 0, msk),
+		wantOK:   true,
+	}, {
+		name:     "time zone same day",
+		schedule: []string{"08:00:Europe/Moscow"},
+		after:    at(0, 4, 0),
+		want:     time.Date(2021, 6, 1, 8, 0, 0, 0, msk),
+		wantOK:   true,
+	}, {
+		name:     "weekdays",
+		schedule: daily,
+		rule: &ent.ScheduleRule{
+			Weekdays: []time.Weekday{time.Monday, time.Friday},
+		},
+		after:  at(0, 9, 0),
+		want:   at(3, 8, 0),
+		wantOK: true,
+	}, {
+		name:     "not started",
+		schedule: daily,
+		rule:     &ent.ScheduleRule{StartAt: ptr(at(5, 12, 0))},
+		after:    at(0, 9, 0),
+		want:     at(5, 20, 0),
+		wantOK:   true,
+	}, {
+		name:     "ended",
+		schedule: daily,
+		rule:     &ent.ScheduleRule{EndAt: ptr(at(0, 19, 0))},
+		after:    at(0, 9, 0),
+	}, {
+		name:     "end inclusive",
+		schedule: daily,
+		rule:     &ent.ScheduleRule{EndAt: ptr(at(0, 20, 0))},
+		after:    at(0, 9, 0),
+		want:     at(0, 20, 0),
+		wantOK:   true,
+	}, {
+		name:     "course ended",
+		schedule: daily,
+		rule: &ent.ScheduleRule{StartAt: ptr(at(0, 0, 0)),
+			CourseDays: 2},
+		after: at(1, 21, 0),
+	}, {
+		name:     "course before end at",
+		schedule: daily,
+		rule: &ent.ScheduleRule{StartAt: ptr(at(0, 0, 0)),
+			CourseDays: 2, EndAt: ptr(at(10, 0, 0))},
+		after:  at(1, 9, 0),
+		want:   at(1, 20, 0),
+		wantOK: true,
+	}, {
+		name:     "pills left",
+		schedule: daily,
+		rule:     &ent.ScheduleRule{PillCount: 3},
+		fired:    2,
+		after:    at(0, 9, 0),
+		want:     at(0, 20, 0),
+		wantOK:   true,
+	}, {
+		name:     "pills taken",
+		schedule: daily,
+		rule:     &ent.ScheduleRule{PillCount: 3},
+		fired:    3,
+		after:    at(0, 9, 0),
+	}, {
+		name: "every hours",
+		rule: &ent.ScheduleRule{EveryHours: 8,
+			StartAt: ptr(at(0, 7, 30))},
+		after:  at(1, 0, 0),
+		want:   at(1, 7, 30),
+		wantOK: true,
+	}, {
+		name: "every hours on start",
+		rule: &ent.ScheduleRule{EveryHours: 8,
+			StartAt: ptr(at(0, 7, 30))},
+		after:  at(0, 7, 30),
+		want:   at(0, 15, 30),
+		wantOK: true,
+	}, {
+		name: "every hours before start",
+		rule: &ent.ScheduleRule{EveryHours: 8,
+			StartAt: ptr(at(2, 7, 30))},
+		after:  at(0, 0, 0),
+		want:   at(2, 7, 30),
+		wantOK: true,
+	}, {
+		name: "every hours on weekdays",
+		rule: &ent.ScheduleRule{EveryHours: 12,
+			StartAt:  ptr(at(0, 6, 0)),
+			Weekdays: []time.Weekday{time.Thursday}},
+		after:  at(0, 7, 0),
+		want:   at(2, 6, 0),
+		wantOK: true,
+	}, {
+		name:     "no weekday matches",
+		schedule: daily,
+		rule: &ent.ScheduleRule{Weekdays: []time.Weekday{time.Monday},
+			EndAt: ptr(at(4, 0, 0))},
+		after: at(0, 9, 0),
+	}, {
+		name:     "empty schedule",
+		schedule: []string{},
+		after:    at(0, 9, 0),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Next(tt.schedule, tt.rule, tt.fired, tt.after)
+			if ok != tt.wantOK {
+				t.Fatalf("Next() ok = %v, want %v (got %s)", ok, tt.wantOK, got)
+			}
+			if ok && !got.Equal(tt.want) {
+				t.Errorf("Next() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextSequence(t *testing.T) {
+	start := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	rule := &ent.ScheduleRule{StartAt: &start, PillCount: 4}
+
+	var got []string
+
+	after := start
+	for fired := 0; ; fired++ {
+		next, ok := Next([]string{"08:00:UTC", "20:00:UTC"}, rule, fired, after)
+		if !ok {
+			break
+		}
+		got = append(got, next.Format("02 15:04"))
+		after = next
+	}
+
+	want := "01 08:00,01 20:00,02 08:00,02 20:00"
+	if strings.Join(got, ",") != want {
+		t.Errorf("fired at %v, want %s", got, want)
+	}
+}
@@ -0,0 +1,264 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+
+	"eapteka/ent"
+	"eapteka/metrics"
+)
+
+const topicPrefix = "reminders:"
+
+// Topic returns hub topic of the user reminders.
+func Topic(userID string) string {
+	return topicPrefix + userID
+}
+
+type Config struct {
+	// Tick is the interval of checking for due notifiers.
+	Tick time.Duration
+
+	// MaxLateness is the maximum delay of the reminder after which it is
+	// skipped, e.g. when every replica was down at its scheduled time.
+	MaxLateness time.Duration
+
+	// BatchSize is the maximum number of notifiers processed in one
+	// transaction.
+	BatchSize int
+
+	// OnFire is called for every fired reminder in the transaction which
+	// fires it, so the reminder is fired only if OnFire succeeds. It is
+	// used to enqueue deliveries and publish the reminder to clients.
+	OnFire func(ctx context.Context, tx *sqlx.Tx, r ent.Reminder) error
+}
+
+var ConfigDefault = Config{
+	Tick:        10 * time.Second,
+	MaxLateness: 10 * time.Minute,
+	BatchSize:   100,
+}
+
+// Scheduler fires notifier reminders. Next fire time of every notifier is
+// kept in the DB and due notifiers are locked with "skip locked", so any
+// number of replicas may run schedulers concurrently. Each fired reminder
+// is stored with unique (notifier_id, scheduled_at) and passed to OnFire in
+// the same transaction, thus every occurrence is handled exactly once.
+type Scheduler struct {
+	db  *sqlx.DB
+	cfg Config
+
+	running int32
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	close chan struct{}
+	wg    sync.WaitGroup
+}
+
+func New(db *sqlx.DB, cfg Config) *Scheduler {
+	if cfg.Tick == 0 {
+		cfg.Tick = ConfigDefault.Tick
+	}
+	if cfg.MaxLateness == 0 {
+		cfg.MaxLateness = ConfigDefault.MaxLateness
+	}
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = ConfigDefault.BatchSize
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Scheduler{
+		db:     db,
+		cfg:    cfg,
+		ctx:    ctx,
+		cancel: cancel,
+		close:  make(chan struct{}),
+	}
+}
+
+func (s *Scheduler) Start() {
+	atomic.StoreInt32(&s.running, 1)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer atomic.StoreInt32(&s.running, 0)
+		s.fireLoop()
+	}()
+}
+
+func (s *Scheduler) Stop() {
+	close(s.close)
+	s.cancel()
+	s.wg.Wait()
+}
+
+// Running reports whether the fire loop is running.
+func (s *Scheduler) Running() bool {
+	return atomic.LoadInt32(&s.running) == 1
+}
+
+func (s *Scheduler) fireLoop() {
+	t := time.NewTicker(s.cfg.Tick)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-s.close:
+			return
+		case <-t.C:
+		}
+
+		for {
+			n, err := s.fire(s.ctx, time.Now())
+			if err != nil {
+				metrics.ReminderFireErrors.Inc()
+				logrus.WithError(err).Error("failed to fire reminders")
+				break
+			}
+			if n < s.cfg.BatchSize {
+				break
+			}
+		}
+	}
+}
+
+// fire processes one batch of due notifiers and snoozed reminders and
+// returns the biggest of their sizes.
+func (s *Scheduler) fire(ctx context.Context, now time.Time) (count int,
+	err error) {
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var ns []struct {
+		ID         int64             `db:"id"`
+		Schedule   pq.StringArray    `db:"schedule"`
+		Rule       *ent.ScheduleRule `db:"rule"`
+		NextFireAt *time.Time        `db:"next_fire_at"`
+		Fired      int               `db:"fired"`
+	}
+
+	err = tx.SelectContext(ctx, &ns, `
+		select id, schedule, rule, next_fire_at,
+		       (select count(*) from reminder r where r.notifier_id = n.id) as fired
+		from notifier n
+		where not paused and (next_fire_at is null or next_fire_at <= $1)
+		order by next_fire_at nulls first
+		limit $2
+		for update skip locked
+	`, now, s.cfg.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("select due notifiers: %w", err)
+	}
+
+	fired := 0
+
+	for _, n := range ns {
+		// Notifier without next fire time is new or changed, its next
+		// fire time is computed without firing
+		if n.NextFireAt != nil && now.Sub(*n.NextFireAt) <= s.cfg.MaxLateness {
+			var r ent.Reminder
+
+			err = tx.QueryRowxContext(ctx, `
+				insert into reminder(notifier_id, scheduled_at) values ($1, $2)
+				on conflict do nothing
+				returning id, notifier_id, scheduled_at, created_at, snoozed_until
+			`, n.ID, *n.NextFireAt).StructScan(&r)
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				// Already fired
+			case err != nil:
+				return 0, fmt.Errorf("insert reminder: %w", err)
+			default:
+				n.Fired++
+				err = s.notify(ctx, tx, r)
+				if err != nil {
+					return 0, err
+				}
+				fired++
+			}
+		}
+
+		// Notifier without valid schedule is never selected again
+		var nextFireAt interface{} = "infinity"
+		if next, ok := Next(n.Schedule, n.Rule, n.Fired, now); ok {
+			nextFireAt = next
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			update notifier set next_fire_at = $2 where id = $1
+		`, n.ID, nextFireAt)
+		if err != nil {
+			return 0, fmt.Errorf("update next fire time: %w", err)
+		}
+	}
+
+	// Fire snoozed reminders again
+	var rs []ent.Reminder
+
+	err = tx.SelectContext(ctx, &rs, `
+		update reminder set snooze_fired = true
+		where id in (
+			select id from reminder
+			where not snooze_fired and snoozed_until <= $1
+			limit $2
+			for update skip locked
+		)
+		returning id, notifier_id, scheduled_at, created_at, snoozed_until
+	`, now, s.cfg.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("update snoozed reminders: %w", err)
+	}
+
+	for _, r := range rs {
+		err = s.notify(ctx, tx, r)
+		if err != nil {
+			return 0, err
+		}
+		fired++
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, fmt.Errorf("commit tx: %w", err)
+	}
+
+	metrics.RemindersFired.Add(float64(fired))
+
+	if len(rs) > len(ns) {
+		return len(rs), nil
+	}
+
+	return len(ns), nil
+}
+
+// notify passes the fired reminder to OnFire.
+func (s *Scheduler) notify(ctx context.Context, tx *sqlx.Tx,
+	r ent.Reminder) error {
+
+	if s.cfg.OnFire == nil {
+		return nil
+	}
+	return s.cfg.OnFire(ctx, tx, r)
+}
@@ -0,0 +1,184 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"eapteka/apierr"
+	"eapteka/ent"
+	"eapteka/metrics"
+)
+
+var (
+	ErrProductNotFound = apierr.NotFound("product not found")
+	ErrExpertNotFound  = apierr.NotFound("expert not found")
+)
+
+// Search returns products and substances with names similar to the
+// keyword, most similar first.
+func (s *Store) Search(ctx context.Context, keyword string) (
+	ent.SearchResult, error) {
+
+	if utf8.RuneCountInString(keyword) < s.cfg.MinKeywordLength {
+		return ent.SearchResult{}, apierr.Validation("too short keyword",
+			apierr.Field("k", fmt.Sprintf("must be at least %d characters",
+				s.cfg.MinKeywordLength)))
+	}
+
+	var (
+		wg    sync.WaitGroup
+		ps    = []ent.Product{}
+		psErr error
+		ss    = []ent.Substance{}
+		ssErr error
+	)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer metrics.Since(metrics.SearchDuration.WithLabelValues("products"),
+			time.Now())
+		psErr = s.db.SelectContext(ctx, &ps, `
+			-- name: search products
+			select substance_id, p.id as id, p.name as name, description,
+			       price, image_id, sku, s.name as substance_name
+			from product p
+			    left join substance s on p.substance_id = s.id
+			where p.name % $1
+			order by similarity(p.name, $1) desc
+		`, keyword)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer metrics.Since(metrics.SearchDuration.WithLabelValues("substances"),
+			time.Now())
+		ssErr = s.db.SelectContext(ctx, &ss, `
+			-- name: search substances
+			select * from substance s
+			where s.name % $1
+			order by similarity(name, $1) desc
+		`, keyword)
+	}()
+
+	wg.Wait()
+
+	if psErr != nil {
+		return ent.SearchResult{}, fmt.Errorf("search products: %w", psErr)
+	}
+	if ssErr != nil {
+		return ent.SearchResult{}, fmt.Errorf("search substances: %w", ssErr)
+	}
+
+	return ent.SearchResult{Products: ps, Substances: ss}, nil
+}
+
+func (s *Store) Product(ctx context.Context, id int64) (ent.Product, error) {
+	var p ent.Product
+
+	err := s.db.QueryRowxContext(ctx, `
+		select substance_id, p.id as id, p.name as name, description,
+			   price, image_id, sku, s.name as substance_name
+		from product p
+			left join substance s on p.substance_id = s.id
+		where p.id = $1
+	`, id).StructScan(&p)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ent.Product{}, ErrProductNotFound
+		}
+		return ent.Product{}, fmt.Errorf("select product: %w", err)
+	}
+
+	return p, nil
+}
+
+// Products returns products of the substance, every product if
+// substanceID is zero.
+func (s *Store) Products(ctx context.Context, substanceID int64) (
+	[]ent.Product, error) {
+
+	ps := []ent.Product{}
+
+	var err error
+
+	if substanceID != 0 {
+		err = s.db.SelectContext(ctx, &ps, `
+			select p.id as id, p.name as name, description, price, image_id,
+					sku, s.name as substance_name, s.id as substance_id
+			from product p
+				left join substance s on p.substance_id = s.id
+			where substance_id = $1
+			order by id desc
+		`, substanceID)
+	} else {
+		err = s.db.SelectContext(ctx, &ps, `
+			select p.id as id, p.name as name, description, price, image_id,
+					sku, s.name as substance_name, s.id as substance_id
+			from product p
+				left join substance s on p.substance_id = s.id
+			order by id desc
+		`)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("select products: %w", err)
+	}
+
+	return ps, nil
+}
+
+// Substances returns substance of the product, every substance if
+// productID is zero.
+func (s *Store) Substances(ctx context.Context, productID int64) (
+	[]ent.Substance, error) {
+
+	ss := []ent.Substance{}
+
+	var err error
+
+	if productID != 0 {
+		err = s.db.SelectContext(ctx, &ss, `
+			select s.id as id, name
+			from substance s
+				left join product p on p.substance_id = s.id
+			where p.id = $1
+			order by id desc
+		`, productID)
+	} else {
+		err = s.db.SelectContext(ctx, &ss, `
+			select id, name
+			from substance s
+			order by id desc
+		`)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("select substances: %w", err)
+	}
+
+	return ss, nil
+}
+
+// Expert returns expert opinion on the substance.
+func (s *Store) Expert(ctx context.Context, substanceID int64) (
+	ent.Expert, error) {
+
+	var e ent.Expert
+
+	err := s.db.QueryRowxContext(ctx, `
+		select * from expert where substance_id = $1
+	`, substanceID).StructScan(&e)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ent.Expert{}, ErrExpertNotFound
+		}
+		return ent.Expert{}, fmt.Errorf("select expert: %w", err)
+	}
+
+	return e, nil
+}
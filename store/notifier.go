@@ -0,0 +1,344 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"eapteka/apierr"
+	"eapteka/ent"
+	"eapteka/scheduler"
+)
+
+var ErrNotifierNotFound = apierr.NotFound("notifier not found")
+
+const notifierSelect = `
+	select n.id as id, user_id, product_id, schedule, rule, dose_amount,
+	       dose_unit, notes, paused, p.name as product_name
+	from notifier as n left join product p on p.id = n.product_id
+`
+
+// Notifiers returns every notifier.
+func (s *Store) Notifiers() []ent.Notifier {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	ns := make([]ent.Notifier, 0, len(s.notifiers))
+	for _, n := range s.notifiers {
+		ns = append(ns, n)
+	}
+
+	return ns
+}
+
+// Notifier returns notifier from the cache or loads it from DB if it was
+// created by another replica.
+func (s *Store) Notifier(ctx context.Context, id int64) (ent.Notifier, error) {
+	s.mx.RLock()
+	n, ok := s.notifiers[id]
+	s.mx.RUnlock()
+	if ok {
+		return n, nil
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	err := s.db.QueryRowxContext(ctx, notifierSelect+` where n.id = $1`,
+		id).StructScan(&n)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ent.Notifier{}, ErrNotifierNotFound
+		}
+		return ent.Notifier{}, fmt.Errorf("select notifier: %w", err)
+	}
+
+	s.notifiers[n.ID] = n
+
+	return n, nil
+}
+
+// prepareNotifier validates notifier and fills its derived fields in the
+// transaction changing it.
+func prepareNotifier(ctx context.Context, tx *sqlx.Tx, n *ent.Notifier) error {
+	err := tx.QueryRowxContext(ctx, `
+		select name from product where id = $1
+	`, n.ProductID).Scan(&n.ProductName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return apierr.Validation("product not found",
+				apierr.Field("product_id", "product not found"))
+		}
+		return fmt.Errorf("select product: %w", err)
+	}
+
+	if n.RRule != "" {
+		n.Schedule, n.Rule, err = scheduler.ParseRRule(n.RRule)
+		if err != nil {
+			return apierr.InvalidField("rrule", err)
+		}
+		n.RRule = ""
+	}
+
+	n.Schedule, err = scheduler.Validate(n.Schedule, n.Rule)
+	if err != nil {
+		return apierr.InvalidField("schedule", err)
+	}
+
+	if n.DoseAmount < 0 {
+		return apierr.Validation("negative dose amount",
+			apierr.Field("dose_amount", "must not be negative"))
+	}
+
+	return nil
+}
+
+func (s *Store) CreateNotifier(ctx context.Context, n ent.Notifier) (
+	_ ent.Notifier, err error) {
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return ent.Notifier{}, fmt.Errorf("begin tx: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	err = prepareNotifier(ctx, tx, &n)
+	if err != nil {
+		return ent.Notifier{}, err
+	}
+
+	err = tx.QueryRowxContext(ctx, `
+		insert into notifier(user_id, product_id, schedule, rule, dose_amount,
+		                     dose_unit, notes, paused)
+		values ($1, $2, $3, $4, $5, $6, $7, $8)
+		returning id
+	`, n.UserID, n.ProductID, pq.Array(n.Schedule), n.Rule, n.DoseAmount,
+		n.DoseUnit, n.Notes, n.Paused).Scan(&n.ID)
+	if err != nil {
+		return ent.Notifier{}, fmt.Errorf("insert notifier: %w", err)
+	}
+
+	err = s.commitChange(ctx, tx, n.ID)
+	if err != nil {
+		return ent.Notifier{}, err
+	}
+
+	s.cache(n)
+
+	return n, nil
+}
+
+// UpdateNotifier changes the notifier by update called with its copy, e.g.
+// replacing it or changing some fields only. The notifier is read from DB
+// and locked until the change is committed, so concurrent changes made by
+// any replica aren't lost.
+func (s *Store) UpdateNotifier(ctx context.Context, id int64,
+	update func(n *ent.Notifier) error) (_ ent.Notifier, err error) {
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return ent.Notifier{}, fmt.Errorf("begin tx: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var old ent.Notifier
+
+	err = tx.QueryRowxContext(ctx, notifierSelect+`
+		where n.id = $1
+		for update of n
+	`, id).StructScan(&old)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.uncache(id)
+			return ent.Notifier{}, ErrNotifierNotFound
+		}
+		return ent.Notifier{}, fmt.Errorf("select notifier: %w", err)
+	}
+
+	n := old
+	if old.Rule != nil {
+		r := *old.Rule
+		n.Rule = &r
+	}
+
+	err = update(&n)
+	if err != nil {
+		return ent.Notifier{}, err
+	}
+
+	n.ID = old.ID
+
+	err = prepareNotifier(ctx, tx, &n)
+	if err != nil {
+		return ent.Notifier{}, err
+	}
+
+	// Next fire time is recomputed by scheduler if schedule is changed or
+	// notifier is resumed
+	reschedule := !reflect.DeepEqual(n.Schedule, old.Schedule) ||
+		!reflect.DeepEqual(n.Rule, old.Rule) || (old.Paused && !n.Paused)
+
+	_, err = tx.ExecContext(ctx, `
+		update notifier
+		set product_id = $2, schedule = $3, rule = $4, dose_amount = $5,
+		    dose_unit = $6, notes = $7, paused = $8, user_id = $9,
+		    next_fire_at = case when $10 then null else next_fire_at end
+		where id = $1
+	`, n.ID, n.ProductID, pq.Array(n.Schedule), n.Rule, n.DoseAmount,
+		n.DoseUnit, n.Notes, n.Paused, n.UserID, reschedule)
+	if err != nil {
+		return ent.Notifier{}, fmt.Errorf("update notifier: %w", err)
+	}
+
+	err = s.commitChange(ctx, tx, n.ID)
+	if err != nil {
+		return ent.Notifier{}, err
+	}
+
+	s.cache(n)
+
+	return n, nil
+}
+
+func (s *Store) DeleteNotifier(ctx context.Context, id int64) (err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	_, err = tx.ExecContext(ctx, `delete from notifier where id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete notifier: %w", err)
+	}
+
+	err = s.commitChange(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	s.uncache(id)
+
+	return nil
+}
+
+// commitChange calls OnChange of the notifier and commits the transaction.
+func (s *Store) commitChange(ctx context.Context, tx *sqlx.Tx, id int64) error {
+	if s.cfg.OnChange != nil {
+		err := s.cfg.OnChange(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := tx.Commit()
+	if err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+
+	return nil
+}
+
+// cache puts the committed notifier to the cache. Changes of the same
+// notifier committed concurrently may be cached out of order, the change
+// published by commitChange refreshes it from DB afterwards.
+func (s *Store) cache(n ent.Notifier) {
+	s.mx.Lock()
+	s.notifiers[n.ID] = n
+	s.mx.Unlock()
+}
+
+// uncache removes the deleted notifier from the cache.
+func (s *Store) uncache(id int64) {
+	s.mx.Lock()
+	delete(s.notifiers, id)
+	s.mx.Unlock()
+}
+
+// Refresh reloads the notifier changed by another replica into the cache.
+func (s *Store) Refresh(ctx context.Context, id int64) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	var n ent.Notifier
+
+	err := s.db.QueryRowxContext(ctx, notifierSelect+` where n.id = $1`,
+		id).StructScan(&n)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			delete(s.notifiers, id)
+			return nil
+		}
+		return fmt.Errorf("select notifier: %w", err)
+	}
+
+	s.notifiers[n.ID] = n
+
+	return nil
+}
+
+// SubscribedUsers returns users whose notifiers may match the subscription,
+// the subscribed user and owners of the subscribed notifiers. Unknown
+// notifiers are ignored.
+func (s *Store) SubscribedUsers(ctx context.Context,
+	sub ent.NotifierSubscription) ([]string, error) {
+
+	var users []string
+	if sub.UserID != "" {
+		users = append(users, sub.UserID)
+	}
+
+	for _, id := range sub.NotifierIDs {
+		n, err := s.Notifier(ctx, id)
+		if err != nil {
+			if errors.Is(err, ErrNotifierNotFound) {
+				continue
+			}
+			return nil, err
+		}
+
+		known := false
+		for _, u := range users {
+			known = known || u == n.UserID
+		}
+		if !known {
+			users = append(users, n.UserID)
+		}
+	}
+
+	return users, nil
+}
+
+// Subscribed reports whether reminders of the notifier are sent to the
+// client with given subscription. Empty subscription selects nothing.
+func Subscribed(s ent.NotifierSubscription, n ent.Notifier) bool {
+	if s.UserID != "" && s.UserID == n.UserID {
+		return true
+	}
+	for _, id := range s.NotifierIDs {
+		if id == n.ID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package store
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"eapteka/ent"
+)
+
+func TestSubscribed(t *testing.T) {
+	n := ent.Notifier{ID: 1, UserID: "u1"}
+
+	tests := []struct {
+		name string
+		sub  ent.NotifierSubscription
+		want bool
+	}{
+		{"empty", ent.NotifierSubscription{}, false},
+		{"user", ent.NotifierSubscription{UserID: "u1"}, true},
+		{"other user", ent.NotifierSubscription{UserID: "u2"}, false},
+		{"notifier", ent.NotifierSubscription{NotifierIDs: []int64{2, 1}}, true},
+		{"other notifier", ent.NotifierSubscription{NotifierIDs: []int64{2}}, false},
+		{"other user, notifier", ent.NotifierSubscription{
+			UserID:      "u2",
+			NotifierIDs: []int64{1},
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Subscribed(tt.sub, n); got != tt.want {
+				t.Errorf("Subscribed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubscribedUsers(t *testing.T) {
+	s := &Store{notifiers: map[int64]ent.Notifier{
+		1: {ID: 1, UserID: "u1"},
+		2: {ID: 2, UserID: "u2"},
+		3: {ID: 3, UserID: "u1"},
+	}}
+
+	tests := []struct {
+		name string
+		sub  ent.NotifierSubscription
+		want []string
+	}{
+		{"empty", ent.NotifierSubscription{}, nil},
+		{"user", ent.NotifierSubscription{UserID: "u1"}, []string{"u1"}},
+		{"notifiers", ent.NotifierSubscription{NotifierIDs: []int64{1, 2, 3}},
+			[]string{"u1", "u2"}},
+		{"user, notifiers", ent.NotifierSubscription{
+			UserID:      "u2",
+			NotifierIDs: []int64{2, 3},
+		}, []string{"u2", "u1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.SubscribedUsers(context.Background(), tt.sub)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SubscribedUsers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"eapteka/ent"
+)
+
+// Purchases returns purchases of the user, newest first, every purchase
+// if userID is empty.
+func (s *Store) Purchases(ctx context.Context, userID string) (
+	[]ent.Purchase, error) {
+
+	ps := []ent.Purchase{}
+
+	err := s.db.SelectContext(ctx, &ps, `
+		select * from purchase
+		where $1 = '' or user_id = $1
+		order by created_at desc
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("select purchases: %w", err)
+	}
+
+	return ps, nil
+}
+
+// PurchaseProducts returns products of the purchase with count and
+// purchase price.
+func (s *Store) PurchaseProducts(ctx context.Context, purchaseID int64) (
+	[]ent.Product, error) {
+
+	ps := []ent.Product{}
+
+	err := s.db.SelectContext(ctx, &ps, `
+		select p.id as id, substance_id, p.name as name, description, image_id,
+				s.name as substance_name, count, pp.price as purchase_price
+		from purchase_product pp
+		    left join product p on pp.product_id = p.id
+		    left join substance s on s.id = p.substance_id
+		where purchase_id = $1`, purchaseID)
+	if err != nil {
+		return nil, fmt.Errorf("select purchase products: %w", err)
+	}
+
+	return ps, nil
+}
+
+// CreatePurchase creates purchase of the user and returns it with its
+// products.
+func (s *Store) CreatePurchase(ctx context.Context, userID string,
+	pps []ent.PurchaseProduct) (_ ent.Purchase, err error) {
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return ent.Purchase{}, fmt.Errorf("begin tx: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var p ent.Purchase
+
+	err = tx.QueryRowxContext(ctx, `
+		insert into purchase(user_id) values ($1) returning *
+	`, userID).StructScan(&p)
+	if err != nil {
+		return ent.Purchase{}, fmt.Errorf("insert purchase: %w", err)
+	}
+
+	var pIDs []int64
+	for _, pp := range pps {
+		_, err = tx.ExecContext(ctx, `
+			insert into purchase_product(purchase_id, product_id, count, price)
+			values ($1, $2, $3, $4)
+		`, p.ID, pp.ProductID, pp.Count, pp.Price)
+		if err != nil {
+			return ent.Purchase{}, fmt.Errorf("insert purchase product: %w", err)
+		}
+		pIDs = append(pIDs, pp.ProductID)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return ent.Purchase{}, fmt.Errorf("commit tx: %w", err)
+	}
+
+	err = s.db.SelectContext(ctx, &p.Products, `
+		select * from product where id = ANY($1::BIGINT[])
+		order by id asc
+	`, pq.Array(pIDs))
+	if err != nil {
+		return ent.Purchase{}, fmt.Errorf("select products: %w", err)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,73 @@
+// Package store reads and changes catalog, purchases and notifiers for the
+// REST and gRPC APIs, so both validate requests and report errors the same
+// way. Errors of invalid requests are apierr errors.
+package store
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+
+	"eapteka/ent"
+)
+
+type Config struct {
+	// MinKeywordLength is the minimum number of characters of the search
+	// keyword.
+	MinKeywordLength int
+
+	// OnChange is called in the transaction which creates, changes or
+	// deletes the notifier, e.g. to publish its id, so other replicas
+	// Refresh it.
+	OnChange func(ctx context.Context, tx *sqlx.Tx, id int64) error
+}
+
+var ConfigDefault = Config{
+	MinKeywordLength: 3,
+}
+
+type Store struct {
+	db  *sqlx.DB
+	cfg Config
+
+	// notifiers mirrors notifier table. Changes are committed without mx
+	// lock and cached after commit. Changes of other replicas, and of this
+	// one cached out of order, are applied by Refresh and Load.
+	notifiers map[int64]ent.Notifier
+	mx        sync.RWMutex
+}
+
+func New(db *sqlx.DB, cfg Config) *Store {
+	if cfg.MinKeywordLength == 0 {
+		cfg.MinKeywordLength = ConfigDefault.MinKeywordLength
+	}
+
+	return &Store{
+		db:        db,
+		cfg:       cfg,
+		notifiers: map[int64]ent.Notifier{},
+	}
+}
+
+// Load fills notifiers cache replacing its content, e.g. when changes of
+// other replicas might be missed.
+func (s *Store) Load(ctx context.Context) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	var ns []ent.Notifier
+
+	err := s.db.SelectContext(ctx, &ns, notifierSelect)
+	if err != nil {
+		return fmt.Errorf("select notifiers: %w", err)
+	}
+
+	s.notifiers = make(map[int64]ent.Notifier, len(ns))
+	for _, n := range ns {
+		s.notifiers[n.ID] = n
+	}
+
+	return nil
+}
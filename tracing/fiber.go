@@ -0,0 +1,82 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"eapteka/apierr"
+	"eapteka/reqctx"
+)
+
+// headerCarrier adapts fasthttp request headers to propagation.TextMapCarrier.
+type headerCarrier struct {
+	h *fasthttp.RequestHeader
+}
+
+func (c headerCarrier) Get(key string) string {
+	return string(c.h.Peek(key))
+}
+
+func (c headerCarrier) Set(key, value string) {
+	c.h.Set(key, value)
+}
+
+func (c headerCarrier) Keys() []string {
+	var ks []string
+	c.h.VisitAll(func(k, _ []byte) {
+		ks = append(ks, string(k))
+	})
+	return ks
+}
+
+// Middleware starts server span of every request continuing trace of the
+// traceparent header and sets its context as the request context. The span
+// is named by matched route pattern once the request is handled.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := otel.GetTextMapPropagator().Extract(context.Background(),
+			headerCarrier{h: &c.Request().Header})
+
+		ctx, span := Start(ctx, "HTTP "+c.Method(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPMethodKey.String(c.Method()),
+				semconv.HTTPTargetKey.String(c.OriginalURL()),
+				semconv.HTTPUserAgentKey.String(c.Get(fiber.HeaderUserAgent)),
+				semconv.NetPeerIPKey.String(c.IP()),
+			))
+		defer span.End()
+
+		reqctx.Set(c, ctx)
+
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = apierr.Of(c, err).Status
+		}
+
+		route := c.Route().Path
+
+		span.SetName(c.Method() + " " + route)
+		span.SetAttributes(
+			semconv.HTTPRouteKey.String(route),
+			semconv.HTTPStatusCodeKey.Int(status),
+		)
+
+		// Client errors are failures of the client rather than the server
+		if status >= fiber.StatusInternalServerError {
+			if err != nil {
+				span.RecordError(err)
+			}
+			span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(status))
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,61 @@
+package tracing
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"eapteka/apierr"
+)
+
+func TestMiddlewareStatus(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(sr)))
+
+	app := fiber.New(fiber.Config{ErrorHandler: apierr.Handler})
+	app.Use(Middleware())
+
+	app.Get("/not_found", func(c *fiber.Ctx) error {
+		return apierr.NotFound("product not found")
+	})
+	app.Get("/internal", func(c *fiber.Ctx) error {
+		return errors.New("boom")
+	})
+
+	tests := []struct {
+		route  string
+		status int64
+		code   codes.Code
+	}{
+		{"/not_found", 404, codes.Unset},
+		{"/internal", 500, codes.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.route, func(t *testing.T) {
+			_, err := app.Test(httptest.NewRequest("GET", tt.route, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			spans := sr.Ended()
+			s := spans[len(spans)-1]
+
+			for _, a := range s.Attributes() {
+				if a.Key == "http.status_code" && a.Value.AsInt64() != tt.status {
+					t.Errorf("status = %d, want %d", a.Value.AsInt64(), tt.status)
+				}
+			}
+			if s.Status().Code != tt.code {
+				t.Errorf("span status = %v, want %v", s.Status().Code, tt.code)
+			}
+		})
+	}
+}
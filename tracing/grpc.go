@@ -0,0 +1,109 @@
+package tracing
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// metadataCarrier adapts gRPC metadata to propagation.TextMapCarrier.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	vs := metadata.MD(c).Get(key)
+	if len(vs) == 0 {
+		return ""
+	}
+	return vs[0]
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	ks := make([]string, 0, len(c))
+	for k := range c {
+		ks = append(ks, k)
+	}
+	return ks
+}
+
+// startRPC starts server span of the call continuing trace of the
+// traceparent metadata. The span is named by full method without leading
+// slash, e.g. "eapteka.v1.CatalogService/Search".
+func startRPC(ctx context.Context, fullMethod string) (context.Context,
+	trace.Span) {
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+
+	name := strings.TrimPrefix(fullMethod, "/")
+	service, method := path.Split(name)
+
+	return Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.RPCSystemKey.String("grpc"),
+			semconv.RPCServiceKey.String(strings.TrimSuffix(service, "/")),
+			semconv.RPCMethodKey.String(method),
+		))
+}
+
+// endRPC records status of the call to the span.
+func endRPC(span trace.Span, err error) {
+	s := status.Convert(err)
+
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(s.Code())))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, s.Message())
+	}
+
+	span.End()
+}
+
+// UnaryServerInterceptor starts server span of every gRPC call.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (
+		resp interface{}, err error) {
+
+		ctx, span := startRPC(ctx, info.FullMethod)
+		defer func() { endRPC(span, err) }()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor starts server span of every gRPC stream, which
+// lasts until the stream ends.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream,
+		info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+
+		ctx, span := startRPC(ss.Context(), info.FullMethod)
+		defer func() { endRPC(span, err) }()
+
+		return handler(srv, serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// serverStream replaces context of the stream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s serverStream) Context() context.Context {
+	return s.ctx
+}
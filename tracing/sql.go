@@ -0,0 +1,151 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const queryNamePrefix = "-- name:"
+
+// OpenDB opens Postgres database which records span of every statement
+// executed with context of another span, so polling of background jobs
+// doesn't produce a trace per statement. Statements are named by
+// "-- name: ..." comment on their first line, or by the operation and the
+// first table otherwise, e.g. "select product".
+func OpenDB(dsn string) (*sql.DB, error) {
+	c, err := pq.NewConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(connector{c}), nil
+}
+
+// QueryName returns name of the statement used as span name.
+func QueryName(query string) string {
+	q := strings.TrimSpace(query)
+
+	if strings.HasPrefix(q, queryNamePrefix) {
+		name := q[len(queryNamePrefix):]
+		if i := strings.IndexByte(name, '\n'); i >= 0 {
+			name = name[:i]
+		}
+		return strings.TrimSpace(name)
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' ||
+			r == '.')
+	})
+	if len(words) == 0 {
+		return "sql"
+	}
+
+	op := words[0]
+
+	var after string
+	switch op {
+	case "select", "delete", "with":
+		after = "from"
+	case "insert":
+		after = "into"
+	case "update":
+		if len(words) > 1 {
+			return op + " " + words[1]
+		}
+	}
+
+	if after != "" {
+		for i := 1; i < len(words)-1; i++ {
+			if words[i] == after {
+				return op + " " + words[i+1]
+			}
+		}
+	}
+
+	return op
+}
+
+func startQuery(ctx context.Context, query string) (context.Context,
+	trace.Span) {
+
+	return Start(ctx, QueryName(query),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBStatementKey.String(query),
+		))
+}
+
+func endQuery(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, driver.ErrSkip) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+type connector struct {
+	driver.Connector
+}
+
+func (c connector) Connect(ctx context.Context) (driver.Conn, error) {
+	cn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return conn{cn}, nil
+}
+
+// conn traces statements of the pq connection, which implements every
+// context aware interface used below.
+type conn struct {
+	driver.Conn
+}
+
+func (c conn) QueryContext(ctx context.Context, query string,
+	args []driver.NamedValue) (driver.Rows, error) {
+
+	q := c.Conn.(driver.QueryerContext)
+
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return q.QueryContext(ctx, query, args)
+	}
+
+	ctx, span := startQuery(ctx, query)
+	rows, err := q.QueryContext(ctx, query, args)
+	endQuery(span, err)
+	return rows, err
+}
+
+func (c conn) ExecContext(ctx context.Context, query string,
+	args []driver.NamedValue) (driver.Result, error) {
+
+	q := c.Conn.(driver.ExecerContext)
+
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return q.ExecContext(ctx, query, args)
+	}
+
+	ctx, span := startQuery(ctx, query)
+	res, err := q.ExecContext(ctx, query, args)
+	endQuery(span, err)
+	return res, err
+}
+
+func (c conn) BeginTx(ctx context.Context, opts driver.TxOptions) (
+	driver.Tx, error) {
+
+	return c.Conn.(driver.ConnBeginTx).BeginTx(ctx, opts)
+}
+
+func (c conn) Ping(ctx context.Context) error {
+	return c.Conn.(driver.Pinger).Ping(ctx)
+}
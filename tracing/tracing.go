@@ -0,0 +1,109 @@
+// Package tracing sets up OpenTelemetry tracing of the service: spans of
+// HTTP requests, SQL statements and background jobs exported via OTLP or to
+// stdout.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exporters of spans.
+const (
+	ExporterNone   = ""
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+const instrumentation = "eapteka"
+
+type Config struct {
+	// ServiceName is the service.name resource attribute.
+	ServiceName string
+
+	// Exporter is one of ExporterNone, ExporterStdout or ExporterOTLP.
+	// Spans are not recorded with ExporterNone.
+	Exporter string
+
+	// Endpoint is host:port of OTLP HTTP receiver, OTEL_EXPORTER_OTLP_*
+	// environment variables are used if empty.
+	Endpoint string
+
+	// Insecure disables TLS of OTLP exporter.
+	Insecure bool
+
+	// SampleRatio is the fraction of traces sampled, unless the parent span
+	// is sampled.
+	SampleRatio float64
+}
+
+var ConfigDefault = Config{
+	ServiceName: "eapteka",
+	SampleRatio: 1,
+}
+
+// Setup sets global tracer provider and W3C trace context propagator. The
+// returned function flushes pending spans and must be called on exit.
+func Setup(cfg Config) (shutdown func(context.Context) error, err error) {
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = ConfigDefault.ServiceName
+	}
+	if cfg.SampleRatio == 0 {
+		cfg.SampleRatio = ConfigDefault.SampleRatio
+	}
+
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exp sdktrace.SpanExporter
+
+	switch cfg.Exporter {
+	case ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exp, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		var opts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exp, err = otlptracehttp.New(context.Background(), opts...)
+	default:
+		return nil, fmt.Errorf("unknown exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create exporter: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithSampler(sdktrace.ParentBased(
+			sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceNameKey.String(cfg.ServiceName))),
+	)
+
+	otel.SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}
+
+// Start starts span of the service, e.g. of the background job iteration.
+func Start(ctx context.Context, name string,
+	opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+
+	return otel.Tracer(instrumentation).Start(ctx, name, opts...)
+}